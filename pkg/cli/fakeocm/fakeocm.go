@@ -0,0 +1,158 @@
+package fakeocm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/ocm-agent/pkg/ocm/fake"
+)
+
+const (
+	// Port the fake OCM server listens on by default
+	defaultPort = 8888
+	// How long the fake OCM server waits for the headers of a request
+	readHeaderTimeout = 3 * time.Second
+)
+
+var (
+	fakeOCMLong = templates.LongDesc(`
+	Start a fake OCM server for local development and tests
+
+	The fake serves the subset of the OCM APIs the agent uses with an in-memory state: the service logs, the limited
+	support reasons, the cluster search and get, the upgrade policies and their state. It accepts any access token, so
+	the agent is started against it in classic mode with --ocm-url pointing to the fake, the cluster ID of one of its
+	clusters and any token.
+
+	Faults (HTTP statuses such as 429 or 503 and latency) are read from a JSON file with --faults and can be changed
+	while the fake runs. The recorded calls and the state are inspected under /_fake:
+
+	  GET /_fake/calls, GET /_fake/state, GET|POST|PUT|DELETE /_fake/faults, POST /_fake/clusters, POST /_fake/reset
+	`)
+
+	fakeOCMExample = templates.Examples(`
+	# Start a fake OCM server with a cluster, and an agent sending its notifications to it
+	ocm-agent fake-ocm --cluster internal-id=bd845de4-5c16-4067-a868-15b02d55ccef
+	ocm-agent serve --ocm-url http://localhost:8888 --access-token fake --cluster-id bd845de4-5c16-4067-a868-15b02d55ccef --services service_logs
+
+	# Rate limit the first three service logs posted
+	echo '[{"operation": "AddServiceLog", "status": 429, "times": 3}]' > faults.json
+	ocm-agent fake-ocm --cluster internal-id=bd845de4-5c16-4067-a868-15b02d55ccef --faults faults.json
+
+	# Show the service logs received by the fake
+	curl -s http://localhost:8888/_fake/state | jq .serviceLogs
+	`)
+)
+
+// fakeOCMOptions define the configuration options of the fake OCM server.
+type fakeOCMOptions struct {
+	port       int
+	clusters   []string
+	faultsFile string
+	logger     *logrus.Logger
+}
+
+func newFakeOCMOptions() *fakeOCMOptions {
+	return &fakeOCMOptions{
+		port:   defaultPort,
+		logger: logrus.StandardLogger(),
+	}
+}
+
+// NewFakeOCMCmd initializes the fake-ocm command and its flags
+func NewFakeOCMCmd() *cobra.Command {
+	o := newFakeOCMOptions()
+
+	cmd := &cobra.Command{
+		Use:     "fake-ocm",
+		Short:   "Starts a fake OCM server for local development and tests",
+		Long:    fakeOCMLong,
+		Example: fakeOCMExample,
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			server, err := o.newServer()
+			kcmdutil.CheckErr(err)
+			kcmdutil.CheckErr(o.run(server))
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.IntVar(&o.port, "port", o.port, "Port the fake OCM server listens on")
+	flags.StringSliceVar(&o.clusters, "cluster", nil, "Cluster of the fake as internal-id=external-id, can be repeated")
+	flags.StringVar(&o.faultsFile, "faults", "", "JSON file holding the list of faults applied to the requests")
+
+	return cmd
+}
+
+// newServer creates the fake OCM server with the clusters and faults of the options.
+func (o *fakeOCMOptions) newServer() (*fake.Server, error) {
+	clusters, err := parseClusters(o.clusters)
+	if err != nil {
+		return nil, err
+	}
+	server := fake.NewServer()
+	for _, cluster := range clusters {
+		server.AddCluster(cluster[0], cluster[1])
+	}
+
+	if o.faultsFile != "" {
+		faults, err := readFaults(o.faultsFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := server.SetFaults(faults); err != nil {
+			return nil, fmt.Errorf("invalid faults in %s: %w", o.faultsFile, err)
+		}
+	}
+	return server, nil
+}
+
+func (o *fakeOCMOptions) run(server *fake.Server) error {
+	o.logger.WithField("Port", o.port).Info("Fake OCM server listening")
+	httpServer := &http.Server{
+		Addr:              ":" + strconv.Itoa(o.port),
+		ReadHeaderTimeout: readHeaderTimeout,
+		Handler:           server,
+	}
+	return httpServer.ListenAndServe()
+}
+
+// parseClusters parses the internal-id=external-id values of the --cluster flag.
+func parseClusters(values []string) ([][2]string, error) {
+	var clusters [][2]string
+	seen := map[string]bool{}
+	for _, value := range values {
+		internalID, externalID, ok := strings.Cut(value, "=")
+		if !ok || internalID == "" || externalID == "" {
+			return nil, fmt.Errorf("invalid cluster '%s', expected internal-id=external-id", value)
+		}
+		if seen[internalID] {
+			return nil, fmt.Errorf("cluster '%s' is set more than once", internalID)
+		}
+		seen[internalID] = true
+		clusters = append(clusters, [2]string{internalID, externalID})
+	}
+	return clusters, nil
+}
+
+// readFaults reads the JSON list of faults of the file.
+func readFaults(path string) ([]fake.Fault, error) {
+	data, err := os.ReadFile(path) //#nosec G304 -- The faults file is chosen by the user
+	if err != nil {
+		return nil, fmt.Errorf("can't read faults: %w", err)
+	}
+	var faults []fake.Fault
+	if err := json.Unmarshal(data, &faults); err != nil {
+		return nil, fmt.Errorf("can't parse faults in %s: %w", path, err)
+	}
+	return faults, nil
+}
@@ -0,0 +1,70 @@
+package fakeocm
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openshift/ocm-agent/pkg/ocm/fake"
+)
+
+func TestParseClusters(t *testing.T) {
+	clusters, err := parseClusters([]string{"internal-a=external-a", "internal-b=external-b"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(clusters) != 2 || clusters[1] != [2]string{"internal-b", "external-b"} {
+		t.Errorf("Unexpected clusters %v", clusters)
+	}
+
+	for _, values := range [][]string{{"internal-a"}, {"=external-a"}, {"internal-a="}, {"internal-a=x", "internal-a=y"}} {
+		if _, err := parseClusters(values); err == nil {
+			t.Errorf("Expected an error for %v", values)
+		}
+	}
+}
+
+func TestNewServer(t *testing.T) {
+	faultsFile := filepath.Join(t.TempDir(), "faults.json")
+	if err := os.WriteFile(faultsFile, []byte(`[{"operation": "AddServiceLog", "status": 429, "times": 3}, {"latency": "1s"}]`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	o := newFakeOCMOptions()
+	o.clusters = []string{"internal-id=external-id"}
+	o.faultsFile = faultsFile
+	server, err := o.newServer()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	faults := server.Faults()
+	if len(faults) != 2 || faults[0].Status != http.StatusTooManyRequests || faults[1].Latency.Seconds() != 1 {
+		t.Errorf("Unexpected faults %+v", faults)
+	}
+	if clusters := server.State().Clusters; len(clusters) != 1 || clusters[0].Cluster["external_id"] != "external-id" {
+		t.Errorf("Unexpected clusters %+v", clusters)
+	}
+}
+
+func TestNewServerInvalidFaults(t *testing.T) {
+	for name, content := range map[string]string{
+		"not JSON":       `faults`,
+		"invalid status": `[{"status": 1000}]`,
+		"nothing to do":  `[{"operation": "` + fake.OperationGetCluster + `"}]`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			faultsFile := filepath.Join(t.TempDir(), "faults.json")
+			if err := os.WriteFile(faultsFile, []byte(content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			o := newFakeOCMOptions()
+			o.faultsFile = faultsFile
+			if _, err := o.newServer(); err == nil || !strings.Contains(err.Error(), "faults") {
+				t.Errorf("Expected a faults error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	oav1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/ocm-agent/pkg/handlers"
+)
+
+// newScheme returns the scheme of the notification custom resources and of the Kubernetes List.
+func newScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(oav1alpha1.AddToScheme(scheme))
+	return scheme
+}
+
+// readNotifications reads the ManagedNotifications, ManagedFleetNotifications and ManagedFleetNotificationRecords
+// of the YAML or JSON files and of the .yaml, .yml and .json files of the directories, e.g. as written by
+// 'oc get managednotifications -o yaml'. Their status is kept, so that a replay can start from the state of a cluster.
+func readNotifications(scheme *runtime.Scheme, paths []string) ([]client.Object, error) {
+	decoder := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+
+	var objects []client.Object
+	for _, path := range paths {
+		files, err := notificationFiles(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			fileObjects, err := readNotificationFile(decoder, file)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, fileObjects...)
+		}
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("no notification custom resource found in %v", paths)
+	}
+	return objects, nil
+}
+
+// notificationFiles returns the path when it's a file, or the manifests of the directory sorted by name.
+func notificationFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read notifications: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read notifications: %w", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if ext := filepath.Ext(entry.Name()); !entry.IsDir() && (ext == ".yaml" || ext == ".yml" || ext == ".json") {
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+func readNotificationFile(decoder runtime.Decoder, path string) ([]client.Object, error) {
+	file, err := os.Open(path) //#nosec G304 -- The notifications are chosen by the user
+	if err != nil {
+		return nil, fmt.Errorf("can't read notifications: %w", err)
+	}
+	defer file.Close()
+
+	var objects []client.Object
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(file))
+	for {
+		document, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("can't read notifications of %s: %w", path, err)
+		}
+		if len(bytes.TrimSpace(document)) == 0 {
+			continue
+		}
+
+		documentObjects, err := decodeNotifications(decoder, document)
+		if err != nil {
+			return nil, fmt.Errorf("invalid notifications in %s: %w", path, err)
+		}
+		objects = append(objects, documentObjects...)
+	}
+	return objects, nil
+}
+
+// decodeNotifications decodes a notification custom resource, or the ones of a list.
+func decodeNotifications(decoder runtime.Decoder, data []byte) ([]client.Object, error) {
+	object, gvk, err := decoder.Decode(data, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []client.Object
+	// The items of the lists written by 'oc get' are raw manifests
+	if list, ok := object.(*corev1.List); ok {
+		for _, item := range list.Items {
+			itemObjects, err := decodeNotifications(decoder, item.Raw)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, itemObjects...)
+		}
+		return objects, nil
+	}
+
+	items := []runtime.Object{object}
+	if meta.IsListType(object) {
+		if items, err = meta.ExtractList(object); err != nil {
+			return nil, err
+		}
+	}
+	for _, item := range items {
+		switch item.(type) {
+		case *oav1alpha1.ManagedNotification, *oav1alpha1.ManagedFleetNotification, *oav1alpha1.ManagedFleetNotificationRecord:
+		default:
+			return nil, fmt.Errorf("unsupported kind %s, expected ManagedNotification, ManagedFleetNotification or ManagedFleetNotificationRecord", gvk.Kind)
+		}
+		notification := item.(client.Object)
+		// The objects are created in the in-memory client, which sets their resourceVersion
+		notification.SetResourceVersion("")
+		if notification.GetNamespace() == "" {
+			notification.SetNamespace(handlers.OCMAgentNamespaceName)
+		}
+		objects = append(objects, notification)
+	}
+	return objects, nil
+}
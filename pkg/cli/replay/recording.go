@@ -0,0 +1,182 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/ocm-agent/pkg/handlers"
+)
+
+const (
+	// Receivers the recordings are delivered to
+	receiverClassic = "classic"
+	receiverFleet   = "fleet"
+)
+
+// Recording is an Alertmanager webhook payload and the time it was delivered at.
+type Recording struct {
+	// Time is when the payload was delivered, which the receivers take as the current time when replayed in process
+	Time time.Time `json:"time"`
+	// Payload is the body of the webhook request
+	Payload json.RawMessage `json:"payload"`
+
+	// source is the file the recording was read from and its position in the file
+	source string
+	// message is the decoded payload
+	message handlers.AMReceiverMessage
+}
+
+// receiver returns the receiver the payload is for, the fleet one when its alerts carry a management cluster ID.
+func (r Recording) receiver() string {
+	for _, alert := range r.message.Alerts {
+		if alert.Labels[handlers.AMLabelAlertMCID] != "" {
+			return receiverFleet
+		}
+	}
+	return receiverClassic
+}
+
+// alerts describes the alerts of the payload as their status and notification template.
+func (r Recording) alerts() []string {
+	alerts := []string{}
+	for _, alert := range r.message.Alerts {
+		description := alert.Status + " " + alert.Labels[handlers.AMLabelTemplateName]
+		if hostedClusterID := alert.Labels[handlers.AMLabelAlertHCID]; hostedClusterID != "" {
+			description += " (" + hostedClusterID + ")"
+		}
+		alerts = append(alerts, description)
+	}
+	return alerts
+}
+
+// readRecordings reads the recordings of the files and of the .json and .jsonl files of the directories, and
+// returns them in the order they were delivered.
+func readRecordings(paths []string) ([]Recording, error) {
+	var recordings []Recording
+	for _, path := range paths {
+		files, err := recordingFiles(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			fileRecordings, err := readRecordingFile(file)
+			if err != nil {
+				return nil, err
+			}
+			recordings = append(recordings, fileRecordings...)
+		}
+	}
+	if len(recordings) == 0 {
+		return nil, fmt.Errorf("no recording found in %s", strings.Join(paths, ", "))
+	}
+
+	// Recordings delivered at the same time keep the order of the files
+	sort.SliceStable(recordings, func(i, j int) bool { return recordings[i].Time.Before(recordings[j].Time) })
+	return recordings, nil
+}
+
+// recordingFiles returns the path when it's a file, or the .json and .jsonl files of the directory sorted by name.
+func recordingFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read recordings: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read recordings: %w", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if ext := filepath.Ext(entry.Name()); !entry.IsDir() && (ext == ".json" || ext == ".jsonl") {
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+// readRecordingFile reads the recordings of a file, which holds JSON values one after the other (e.g. JSON lines),
+// each of them being a recording, a bare Alertmanager payload, or an array of those.
+func readRecordingFile(path string) ([]Recording, error) {
+	data, err := os.ReadFile(path) //#nosec G304 -- The recordings are chosen by the user
+	if err != nil {
+		return nil, fmt.Errorf("can't read recordings: %w", err)
+	}
+
+	var recordings []Recording
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("can't parse recordings of %s: %w", path, err)
+		}
+
+		values := []json.RawMessage{value}
+		if bytes.HasPrefix(bytes.TrimSpace(value), []byte("[")) {
+			if err := json.Unmarshal(value, &values); err != nil {
+				return nil, fmt.Errorf("can't parse recordings of %s: %w", path, err)
+			}
+		}
+		for _, value := range values {
+			recording, err := parseRecording(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid recording %d of %s: %w", len(recordings)+1, path, err)
+			}
+			recording.source = fmt.Sprintf("%s#%d", filepath.Base(path), len(recordings)+1)
+			recordings = append(recordings, recording)
+		}
+	}
+	return recordings, nil
+}
+
+// parseRecording parses a recording, or a bare Alertmanager payload recorded at the time its alerts last changed
+// state: the latest start of its firing alerts and end of its resolved ones.
+func parseRecording(data json.RawMessage) (Recording, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return Recording{}, err
+	}
+
+	var recording Recording
+	if _, ok := fields["payload"]; ok {
+		if err := json.Unmarshal(data, &recording); err != nil {
+			return Recording{}, err
+		}
+	} else {
+		recording.Payload = data
+	}
+	if err := json.Unmarshal(recording.Payload, &recording.message); err != nil {
+		return Recording{}, fmt.Errorf("invalid payload: %w", err)
+	}
+	if len(recording.message.Alerts) == 0 {
+		return Recording{}, fmt.Errorf("payload has no alert")
+	}
+
+	if recording.Time.IsZero() {
+		for _, alert := range recording.message.Alerts {
+			changed := alert.StartsAt
+			if alert.Status == "resolved" {
+				changed = alert.EndsAt
+			}
+			if changed.After(recording.Time) {
+				recording.Time = changed
+			}
+		}
+		if recording.Time.IsZero() {
+			return Recording{}, fmt.Errorf("recording has no time and its alerts neither start nor end time")
+		}
+	}
+	return recording, nil
+}
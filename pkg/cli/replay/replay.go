@@ -0,0 +1,216 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/ocm-agent/pkg/consts"
+	"github.com/openshift/ocm-agent/pkg/logging"
+)
+
+const (
+	// Cluster the classic receiver sends its service logs for by default when replaying in process
+	defaultClusterID = "00000000-0000-0000-0000-000000000000"
+)
+
+var (
+	replayLong = templates.LongDesc(`
+	Replay recorded Alertmanager webhook payloads and report the resulting OCM actions
+
+	The recordings are read from JSON files, or from the .json and .jsonl files of directories. A file holds one or
+	more JSON values, each of them being a recording {"time": "2024-01-02T15:04:05Z", "payload": {...}}, a bare
+	Alertmanager payload, or an array of those. A bare payload, or a recording without time, is taken as delivered when
+	its alerts last changed state. The recordings are replayed in the order of their time, and the payloads whose alerts
+	carry a management cluster ID are delivered to the fleet receiver.
+
+	By default the recordings are replayed in process: the notification custom resources are read from the files of
+	--notifications, the receivers take the time of each recording as the current time, and their calls go to a fake
+	OCM. The resend windows and the resolved notifications are so replayed without waiting for them.
+
+	With --url the recordings are posted to a running agent, which evaluates them with its real clock. The OCM actions
+	are only reported when the agent sends them to a fake OCM, whose URL is set with --fake-ocm-url.
+	`)
+
+	replayExample = templates.Examples(`
+	# Replay recordings against the notifications of the test manifests
+	ocm-agent replay --notifications test/manifests recordings/
+
+	# Replay a firing payload and its resolution, two days apart, as JSON
+	ocm-agent replay --notifications test/manifests/sre-managed-notifications.yaml firing.json resolved.json -o json
+
+	# Post the recordings to a local agent sending its notifications to a fake OCM
+	ocm-agent replay --url http://localhost:8081 --fake-ocm-url http://localhost:8888 recordings/
+	`)
+)
+
+// replayOptions define the configuration options of the replay command.
+type replayOptions struct {
+	notifications    []string
+	clusterID        string
+	url              string
+	webhookPath      string
+	fleetWebhookPath string
+	fakeOCMURL       string
+	output           string
+	logLevel         string
+	logger           *logrus.Logger
+}
+
+func newReplayOptions() *replayOptions {
+	return &replayOptions{
+		clusterID:        defaultClusterID,
+		webhookPath:      consts.WebhookReceiverPath,
+		fleetWebhookPath: consts.WebhookReceiverPath,
+		output:           "text",
+		logLevel:         logrus.WarnLevel.String(),
+		logger:           logrus.StandardLogger(),
+	}
+}
+
+// NewReplayCmd initializes the replay command and its flags
+func NewReplayCmd() *cobra.Command {
+	o := newReplayOptions()
+
+	cmd := &cobra.Command{
+		Use:     "replay RECORDING...",
+		Short:   "Replays recorded Alertmanager webhook payloads",
+		Long:    replayLong,
+		Example: replayExample,
+		Args:    cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.complete())
+			kcmdutil.CheckErr(o.run(cmd.OutOrStdout(), args))
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringSliceVar(&o.notifications, "notifications", nil, "Files or directories of the notification custom resources replayed in process")
+	flags.StringVar(&o.clusterID, "cluster-id", o.clusterID, "External ID of the cluster the classic receiver sends its service logs for in process")
+	flags.StringVar(&o.url, "url", "", "URL of a running agent the recordings are posted to instead of being replayed in process")
+	flags.StringVar(&o.webhookPath, "webhook-path", o.webhookPath, "Path of the classic receiver of the running agent")
+	flags.StringVar(&o.fleetWebhookPath, "fleet-webhook-path", o.fleetWebhookPath, "Path of the fleet receiver of the running agent")
+	flags.StringVar(&o.fakeOCMURL, "fake-ocm-url", "", "URL of the fake OCM the running agent sends its notifications to")
+	flags.StringVarP(&o.output, "output", "o", o.output, "Output format, text or json (string)")
+	flags.StringVar(&o.logLevel, "log-level", o.logLevel, "Log level of the receivers replaying in process")
+
+	return cmd
+}
+
+// complete validates the options and configures the logging of the receivers.
+func (o *replayOptions) complete() error {
+	if o.output != "text" && o.output != "json" {
+		return fmt.Errorf("unknown output format '%s', expected text or json", o.output)
+	}
+	if o.url == "" {
+		if len(o.notifications) == 0 {
+			return fmt.Errorf("--notifications is required to replay in process, or --url to replay against an agent")
+		}
+		if o.fakeOCMURL != "" {
+			return fmt.Errorf("--fake-ocm-url is only used with --url")
+		}
+	} else if len(o.notifications) > 0 {
+		return fmt.Errorf("--notifications is only used in process, the agent reads its own notifications")
+	}
+	return logging.Configure(o.logger, logging.FormatText, o.logLevel)
+}
+
+func (o *replayOptions) run(out io.Writer, paths []string) error {
+	recordings, err := readRecordings(paths)
+	if err != nil {
+		return err
+	}
+
+	t, err := o.newTarget()
+	if err != nil {
+		return err
+	}
+	defer t.close()
+
+	results, err := replay(t, recordings)
+	if err != nil {
+		return err
+	}
+	return printResults(out, o.output, results)
+}
+
+// newTarget returns the running agent of the options, or receivers replaying in process.
+func (o *replayOptions) newTarget() (target, error) {
+	if o.url != "" {
+		return newAgentTarget(o.url, o.webhookPath, o.fleetWebhookPath, o.fakeOCMURL)
+	}
+	scheme := newScheme()
+	objects, err := readNotifications(scheme, o.notifications)
+	if err != nil {
+		return nil, err
+	}
+	return newInProcessTarget(scheme, objects, o.clusterID)
+}
+
+// result is the outcome of the delivery of a recording.
+type result struct {
+	Time     time.Time `json:"time"`
+	Source   string    `json:"source"`
+	Receiver string    `json:"receiver"`
+	Alerts   []string  `json:"alerts"`
+	// Status is the HTTP status the receiver answered
+	Status  int      `json:"status"`
+	Actions []action `json:"actions"`
+}
+
+// replay delivers the recordings one after the other to the target.
+func replay(t target, recordings []Recording) ([]result, error) {
+	results := []result{}
+	for _, recording := range recordings {
+		status, actions, err := t.deliver(recording)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result{
+			Time:     recording.Time,
+			Source:   recording.source,
+			Receiver: recording.receiver(),
+			Alerts:   recording.alerts(),
+			Status:   status,
+			Actions:  actions,
+		})
+	}
+	return results, nil
+}
+
+func printResults(out io.Writer, output string, results []result) error {
+	if output == "json" {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(append(data, '\n'))
+		return err
+	}
+
+	// The actions of a delivery after the first are on their own rows, with their other columns empty
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tSOURCE\tRECEIVER\tSTATUS\tALERTS\tACTION")
+	for _, r := range results {
+		actions := []string{"none"}
+		if len(r.Actions) > 0 {
+			actions = nil
+		}
+		for _, a := range r.Actions {
+			actions = append(actions, a.String())
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", r.Time.UTC().Format(time.RFC3339), r.Source, r.Receiver, r.Status, strings.Join(r.Alerts, ", "), actions[0])
+		for _, a := range actions[1:] {
+			fmt.Fprintf(w, "\t\t\t\t\t%s\n", a)
+		}
+	}
+	return w.Flush()
+}
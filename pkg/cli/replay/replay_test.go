@@ -0,0 +1,307 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openshift/ocm-agent/pkg/ocm/fake"
+)
+
+const (
+	testManifests         = "../../../test/manifests"
+	testHostedClusterID   = "hosted-cluster-id"
+	testManagementCluster = "management-cluster-id"
+)
+
+var testStart = time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+// testPayload returns an Alertmanager payload with an alert of the template, a fleet one when the management cluster
+// ID isn't empty.
+func testPayload(status, template, managementClusterID string, startsAt, endsAt time.Time) string {
+	labels := map[string]string{
+		"alertname":                     "TestAlert",
+		"send_managed_notification":     "true",
+		"managed_notification_template": template,
+	}
+	if managementClusterID != "" {
+		labels["_mc_id"] = managementClusterID
+		labels["_id"] = testHostedClusterID
+	}
+	payload := map[string]interface{}{
+		"receiver": "ocmagent",
+		"status":   status,
+		"alerts": []map[string]interface{}{{
+			"status":   status,
+			"labels":   labels,
+			"startsAt": startsAt,
+			"endsAt":   endsAt,
+		}},
+	}
+	data, _ := json.Marshal(payload)
+	return string(data)
+}
+
+// testRecording returns a recording of the payload at the time.
+func testRecording(at time.Time, payload string) string {
+	return fmt.Sprintf(`{"time": %q, "payload": %s}`, at.Format(time.RFC3339), payload)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadRecordings(t *testing.T) {
+	dir := t.TempDir()
+	firing := testPayload("firing", "LoggingVolumeFillingUp", "", testStart, time.Time{})
+	resolved := testPayload("resolved", "LoggingVolumeFillingUp", "", testStart, testStart.Add(2*time.Hour))
+	// A bare payload is recorded when its alert started, a resolved one when it ended
+	writeFile(t, filepath.Join(dir, "a-bare.json"), firing+"\n"+resolved)
+	writeFile(t, filepath.Join(dir, "b-lines.jsonl"), testRecording(testStart.Add(time.Hour), firing)+"\n"+testRecording(testStart.Add(3*time.Hour), firing)+"\n")
+	writeFile(t, filepath.Join(dir, "c-array.json"), "["+testRecording(testStart.Add(time.Hour), resolved)+"]")
+	writeFile(t, filepath.Join(dir, "ignored.txt"), "not a recording")
+
+	recordings, err := readRecordings([]string{dir})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var sources []string
+	for _, recording := range recordings {
+		sources = append(sources, recording.source+"@"+recording.Time.Sub(testStart).String())
+	}
+	expected := []string{"a-bare.json#1@0s", "b-lines.jsonl#1@1h0m0s", "c-array.json#1@1h0m0s", "a-bare.json#2@2h0m0s", "b-lines.jsonl#2@3h0m0s"}
+	if strings.Join(sources, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected the recordings %v, got %v", expected, sources)
+	}
+	if recordings[0].receiver() != receiverClassic || recordings[0].alerts()[0] != "firing LoggingVolumeFillingUp" {
+		t.Errorf("Unexpected receiver %s or alerts %v", recordings[0].receiver(), recordings[0].alerts())
+	}
+
+	fleet := testPayload("firing", "oidc-deleted-notification", testManagementCluster, testStart, time.Time{})
+	writeFile(t, filepath.Join(dir, "fleet.json"), fleet)
+	recordings, err = readRecordings([]string{filepath.Join(dir, "fleet.json")})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if recordings[0].receiver() != receiverFleet || recordings[0].alerts()[0] != "firing oidc-deleted-notification (hosted-cluster-id)" {
+		t.Errorf("Unexpected receiver %s or alerts %v", recordings[0].receiver(), recordings[0].alerts())
+	}
+
+	for name, content := range map[string]string{
+		"invalid.json":   "{",
+		"no-alert.json":  `{"status": "firing", "alerts": []}`,
+		"no-time.json":   testPayload("firing", "LoggingVolumeFillingUp", "", time.Time{}, time.Time{}),
+		"bad-value.json": `"firing"`,
+	} {
+		writeFile(t, filepath.Join(dir, name), content)
+		if _, err := readRecordings([]string{filepath.Join(dir, name)}); err == nil {
+			t.Errorf("Expected an error for %s", name)
+		}
+	}
+	if _, err := readRecordings([]string{filepath.Join(dir, "missing.json")}); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestReadNotifications(t *testing.T) {
+	objects, err := readNotifications(newScheme(), []string{testManifests})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var names []string
+	for _, object := range objects {
+		names = append(names, object.GetNamespace()+"/"+object.GetName())
+	}
+	expected := []string{
+		"openshift-ocm-agent-operator/oidc-deleted-notification",
+		"openshift-ocm-agent-operator/audit-webhook-error-putting-minimized-cloudwatch-log",
+		"openshift-ocm-agent-operator/sre-managed-notifications",
+	}
+	if strings.Join(names, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected the notifications %v, got %v", expected, names)
+	}
+
+	// The items of a list default to the namespace of the agent
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "list.yaml"), `
+apiVersion: v1
+kind: List
+items:
+- apiVersion: ocmagent.managed.openshift.io/v1alpha1
+  kind: ManagedNotification
+  metadata:
+    name: listed
+    resourceVersion: "42"
+`)
+	objects, err = readNotifications(newScheme(), []string{filepath.Join(dir, "list.yaml")})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(objects) != 1 || objects[0].GetNamespace() != "openshift-ocm-agent-operator" || objects[0].GetResourceVersion() != "" {
+		t.Errorf("Unexpected notifications %v", objects)
+	}
+
+	writeFile(t, filepath.Join(dir, "config-map.yaml"), "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: other\n")
+	if _, err := readNotifications(newScheme(), []string{filepath.Join(dir, "config-map.yaml")}); err == nil {
+		t.Error("Expected an error for a ConfigMap")
+	}
+}
+
+// replayInProcess replays the recordings against the notifications of the test manifests.
+func replayInProcess(t *testing.T, recordings ...string) []result {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "recordings.jsonl")
+	writeFile(t, file, strings.Join(recordings, "\n"))
+
+	o := newReplayOptions()
+	o.notifications = []string{testManifests}
+	if err := o.complete(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var out bytes.Buffer
+	o.output = "json"
+	if err := o.run(&out, []string{file}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var results []result
+	if err := json.Unmarshal(out.Bytes(), &results); err != nil {
+		t.Fatalf("Can't parse the results %s: %v", out.String(), err)
+	}
+	return results
+}
+
+// actionKinds returns the kinds of the actions of each result.
+func actionKinds(results []result) []string {
+	var kinds []string
+	for _, r := range results {
+		var resultKinds []string
+		for _, a := range r.Actions {
+			resultKinds = append(resultKinds, a.Kind)
+		}
+		kinds = append(kinds, strings.Join(resultKinds, "+"))
+	}
+	return kinds
+}
+
+func TestReplayInProcessClassic(t *testing.T) {
+	firing := testPayload("firing", "LoggingVolumeFillingUp", "", testStart, time.Time{})
+	resolved := testPayload("resolved", "LoggingVolumeFillingUp", "", testStart, testStart.Add(2*time.Hour))
+
+	results := replayInProcess(t,
+		testRecording(testStart, firing),
+		// Within the resend window of 24 hours
+		testRecording(testStart.Add(time.Hour), firing),
+		testRecording(testStart.Add(2*time.Hour), resolved),
+		// Firing again after the resend window
+		testRecording(testStart.Add(26*time.Hour), firing),
+	)
+
+	expected := []string{"service_log", "", "service_log", "service_log"}
+	if kinds := actionKinds(results); strings.Join(kinds, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected the actions %q, got %q", expected, kinds)
+	}
+	for _, r := range results {
+		if r.Status != http.StatusOK || r.Receiver != receiverClassic {
+			t.Errorf("Unexpected status %d or receiver %s of %s", r.Status, r.Receiver, r.Source)
+		}
+	}
+	if a := results[0].Actions[0]; a.ClusterID != defaultClusterID || a.Summary != "Issue Notification: ElasticSearch reaching disk capacity" {
+		t.Errorf("Unexpected service log %+v", a)
+	}
+}
+
+func TestReplayInProcessFleet(t *testing.T) {
+	firing := testPayload("firing", "oidc-deleted-notification", testManagementCluster, testStart, time.Time{})
+	resolved := testPayload("resolved", "oidc-deleted-notification", testManagementCluster, testStart, testStart.Add(2*time.Hour))
+	serviceLog := testPayload("firing", "audit-webhook-error-putting-minimized-cloudwatch-log", testManagementCluster, testStart, time.Time{})
+
+	results := replayInProcess(t,
+		testRecording(testStart, firing),
+		// The cluster is already in limited support
+		testRecording(testStart.Add(time.Hour), firing),
+		testRecording(testStart.Add(2*time.Hour), resolved),
+		testRecording(testStart.Add(3*time.Hour), serviceLog),
+		// Within the resend window of 24 hours
+		testRecording(testStart.Add(4*time.Hour), serviceLog),
+		testRecording(testStart.Add(28*time.Hour), serviceLog),
+	)
+
+	expected := []string{"limited_support_added", "", "limited_support_removed", "service_log", "", "service_log"}
+	if kinds := actionKinds(results); strings.Join(kinds, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected the actions %q, got %q", expected, kinds)
+	}
+	if a := results[0].Actions[0]; a.ClusterID != testHostedClusterID || results[0].Receiver != receiverFleet {
+		t.Errorf("Unexpected limited support %+v for receiver %s", a, results[0].Receiver)
+	}
+}
+
+func TestReplayAgent(t *testing.T) {
+	ocmServer := fake.NewServer()
+	ocmListener := httptest.NewServer(ocmServer)
+	defer ocmListener.Close()
+
+	// The agent posts a service log to OCM for each payload it receives on the classic path
+	var paths []string
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path == "/classic" {
+			body := `{"cluster_uuid": "agent-cluster", "summary": "Replayed"}`
+			response, err := http.Post(ocmListener.URL+"/api/service_logs/v1/cluster_logs", "application/json", strings.NewReader(body))
+			if err == nil {
+				_ = response.Body.Close()
+			}
+		}
+	}))
+	defer agent.Close()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "classic.json"), testPayload("firing", "LoggingVolumeFillingUp", "", testStart, time.Time{}))
+	writeFile(t, filepath.Join(dir, "fleet.json"), testPayload("firing", "oidc-deleted-notification", testManagementCluster, testStart.Add(time.Hour), time.Time{}))
+
+	o := newReplayOptions()
+	o.url = agent.URL + "/"
+	o.webhookPath = "/classic"
+	o.fleetWebhookPath = "/fleet"
+	o.fakeOCMURL = ocmListener.URL
+	if err := o.complete(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var out bytes.Buffer
+	if err := o.run(&out, []string{dir}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if strings.Join(paths, " ") != "/classic /fleet" {
+		t.Errorf("Unexpected paths %v", paths)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(lines[1], `service_log agent-cluster "Replayed"`) || !strings.HasSuffix(lines[2], "none") {
+		t.Errorf("Unexpected output:\n%s", out.String())
+	}
+}
+
+func TestComplete(t *testing.T) {
+	for name, set := range map[string]func(o *replayOptions){
+		"no notifications":            func(o *replayOptions) {},
+		"notifications against agent": func(o *replayOptions) { o.url = "http://localhost:8081"; o.notifications = []string{"n.yaml"} },
+		"fake OCM in process":         func(o *replayOptions) { o.notifications = []string{"n.yaml"}; o.fakeOCMURL = "http://localhost:8888" },
+		"unknown output":              func(o *replayOptions) { o.notifications = []string{"n.yaml"}; o.output = "yaml" },
+		"unknown log level":           func(o *replayOptions) { o.notifications = []string{"n.yaml"}; o.logLevel = "loud" },
+	} {
+		o := newReplayOptions()
+		set(o)
+		if err := o.complete(); err == nil {
+			t.Errorf("Expected an error with %s", name)
+		}
+	}
+}
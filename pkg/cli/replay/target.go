@@ -0,0 +1,267 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	oav1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	"github.com/spf13/viper"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openshift/ocm-agent/pkg/config"
+	"github.com/openshift/ocm-agent/pkg/consts"
+	"github.com/openshift/ocm-agent/pkg/handlers"
+	"github.com/openshift/ocm-agent/pkg/ocm"
+	"github.com/openshift/ocm-agent/pkg/ocm/fake"
+)
+
+const (
+	// Kinds of the OCM actions resulting from a delivery
+	actionServiceLog            = "service_log"
+	actionLimitedSupportAdded   = "limited_support_added"
+	actionLimitedSupportRemoved = "limited_support_removed"
+)
+
+// action is a service log or limited support call made to OCM for a delivery.
+type action struct {
+	Kind string `json:"kind"`
+	// ClusterID is the external ID of the cluster for service logs, and its internal ID for limited support
+	ClusterID string `json:"clusterID"`
+	Summary   string `json:"summary,omitempty"`
+	// Status is the HTTP status OCM answered, the call failed when it's 400 or above
+	Status int `json:"status"`
+}
+
+// String describes the action as its kind, cluster and summary, and its status when it failed.
+func (a action) String() string {
+	description := a.Kind + " " + a.ClusterID
+	if a.Summary != "" {
+		description += fmt.Sprintf(" %q", a.Summary)
+	}
+	if a.Status >= http.StatusBadRequest {
+		description += fmt.Sprintf(" (failed with %d)", a.Status)
+	}
+	return description
+}
+
+// target delivers the recordings to the webhook receivers.
+type target interface {
+	// deliver delivers the recording and returns the HTTP status of the receiver and the OCM actions it resulted in
+	deliver(recording Recording) (int, []action, error)
+	close()
+}
+
+// simulatedClock is the clock of the receivers replaying the recordings in process, which is set to the time of each
+// recording before it's delivered.
+type simulatedClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *simulatedClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *simulatedClock) set(now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = now
+}
+
+// inProcessTarget delivers the recordings to receivers running in process with a simulated clock, which read the
+// notification custom resources from an in-memory client and send their notifications to a fake OCM.
+type inProcessTarget struct {
+	clock      simulatedClock
+	ocmServer  *fake.Server
+	listener   *httptest.Server
+	connection *sdk.Connection
+	receivers  map[string]http.Handler
+	// clusters are the hosted clusters known to the fake OCM, added for the limited support calls
+	clusters map[string]bool
+}
+
+func newInProcessTarget(scheme *runtime.Scheme, objects []client.Object, clusterID string) (*inProcessTarget, error) {
+	kubeClient := crfake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithStatusSubresource(&oav1alpha1.ManagedNotification{}, &oav1alpha1.ManagedFleetNotificationRecord{}).
+		Build()
+
+	// The notifications are read from the namespaces of the custom resources, in the order they were read
+	var namespaces []string
+	seen := map[string]bool{}
+	for _, object := range objects {
+		if !seen[object.GetNamespace()] {
+			seen[object.GetNamespace()] = true
+			namespaces = append(namespaces, object.GetNamespace())
+		}
+	}
+
+	t := &inProcessTarget{
+		ocmServer: fake.NewServer(),
+		clusters:  map[string]bool{},
+	}
+	t.listener = httptest.NewServer(t.ocmServer)
+	// The fake accepts any access token, base64 encoded like the ones of the pull secret, and doesn't fail unless told
+	// to so the calls aren't retried
+	connection, err := ocm.NewConnection().RetryPolicy(ocm.RetryPolicy{Interval: ocm.DefaultRetryInterval}).Build(t.listener.URL, clusterID, "cmVwbGF5")
+	if err != nil {
+		t.listener.Close()
+		return nil, fmt.Errorf("can't connect to the fake OCM: %w", err)
+	}
+	t.connection = connection
+	ocmClient := ocm.NewOcmClient(connection)
+
+	// The classic receiver sends its service logs for the cluster of the agent
+	viper.Set(config.ExternalClusterID, clusterID)
+	t.receivers = map[string]http.Handler{
+		receiverClassic: handlers.NewWebhookReceiverHandler(kubeClient, ocmClient, nil).WithNamespaces(namespaces).WithClock(t.clock.Now),
+		receiverFleet:   handlers.NewWebhookRHOBSReceiverHandler(kubeClient, ocmClient, nil).WithNamespaces(namespaces).WithClock(t.clock.Now),
+	}
+	return t, nil
+}
+
+func (t *inProcessTarget) deliver(recording Recording) (int, []action, error) {
+	t.clock.set(recording.Time)
+
+	receiver := recording.receiver()
+	if receiver == receiverFleet {
+		// Limited support is set on the hosted clusters by external ID, which must be known to OCM
+		for _, alert := range recording.message.Alerts {
+			if hostedClusterID := alert.Labels[handlers.AMLabelAlertHCID]; hostedClusterID != "" && !t.clusters[hostedClusterID] {
+				t.ocmServer.AddCluster(hostedClusterID, hostedClusterID)
+				t.clusters[hostedClusterID] = true
+			}
+		}
+	}
+
+	calls := len(t.ocmServer.Calls())
+	request := httptest.NewRequest(http.MethodPost, consts.WebhookReceiverPath, bytes.NewReader(recording.Payload))
+	response := httptest.NewRecorder()
+	t.receivers[receiver].ServeHTTP(response, request)
+	return response.Code, actionsOf(t.ocmServer.Calls()[calls:]), nil
+}
+
+func (t *inProcessTarget) close() {
+	t.connection.Close()
+	t.listener.Close()
+}
+
+// agentTarget posts the recordings to the receivers of a running agent, with their real clock. When the agent sends
+// its notifications to a fake OCM, the actions are read from the calls it recorded.
+type agentTarget struct {
+	client     *http.Client
+	urls       map[string]string
+	fakeOCMURL string
+}
+
+func newAgentTarget(agentURL, webhookPath, fleetWebhookPath, fakeOCMURL string) (*agentTarget, error) {
+	if _, err := url.ParseRequestURI(agentURL); err != nil {
+		return nil, fmt.Errorf("invalid agent URL: %w", err)
+	}
+	agentURL = strings.TrimSuffix(agentURL, "/")
+	return &agentTarget{
+		client: &http.Client{Timeout: time.Minute},
+		urls: map[string]string{
+			receiverClassic: agentURL + webhookPath,
+			receiverFleet:   agentURL + fleetWebhookPath,
+		},
+		fakeOCMURL: strings.TrimSuffix(fakeOCMURL, "/"),
+	}, nil
+}
+
+func (t *agentTarget) deliver(recording Recording) (int, []action, error) {
+	before, err := t.calls()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	response, err := t.client.Post(t.urls[recording.receiver()], "application/json", bytes.NewReader(recording.Payload))
+	if err != nil {
+		return 0, nil, fmt.Errorf("can't deliver %s: %w", recording.source, err)
+	}
+	_, _ = io.Copy(io.Discard, response.Body)
+	_ = response.Body.Close()
+
+	after, err := t.calls()
+	if err != nil {
+		return 0, nil, err
+	}
+	// The calls were removed meanwhile when there are less of them
+	if len(after) >= len(before) {
+		after = after[len(before):]
+	}
+	return response.StatusCode, actionsOf(after), nil
+}
+
+// calls returns the calls recorded by the fake OCM, or none when the agent doesn't send its notifications to one.
+func (t *agentTarget) calls() ([]fake.Call, error) {
+	if t.fakeOCMURL == "" {
+		return nil, nil
+	}
+	response, err := t.client.Get(t.fakeOCMURL + fake.InspectionPathPrefix + "/calls")
+	if err != nil {
+		return nil, fmt.Errorf("can't get the calls of the fake OCM: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("can't get the calls of the fake OCM: %s", response.Status)
+	}
+	var calls []fake.Call
+	if err := json.NewDecoder(response.Body).Decode(&calls); err != nil {
+		return nil, fmt.Errorf("can't parse the calls of the fake OCM: %w", err)
+	}
+	return calls, nil
+}
+
+func (t *agentTarget) close() {}
+
+// actionsOf returns the service log and limited support actions of the calls received by the fake OCM.
+func actionsOf(calls []fake.Call) []action {
+	actions := []action{}
+	for _, call := range calls {
+		var body struct {
+			ClusterUUID string `json:"cluster_uuid"`
+			ClusterID   string `json:"cluster_id"`
+			Summary     string `json:"summary"`
+		}
+		_ = json.Unmarshal(call.Body, &body)
+
+		switch call.Operation {
+		case fake.OperationAddServiceLog:
+			clusterID := body.ClusterUUID
+			if clusterID == "" {
+				clusterID = body.ClusterID
+			}
+			actions = append(actions, action{Kind: actionServiceLog, ClusterID: clusterID, Summary: body.Summary, Status: call.Status})
+		case fake.OperationAddLimitedSupportReason:
+			actions = append(actions, action{Kind: actionLimitedSupportAdded, ClusterID: pathClusterID(call.Path), Summary: body.Summary, Status: call.Status})
+		case fake.OperationDeleteLimitedSupportReason:
+			actions = append(actions, action{Kind: actionLimitedSupportRemoved, ClusterID: pathClusterID(call.Path), Status: call.Status})
+		}
+	}
+	return actions
+}
+
+// pathClusterID returns the cluster ID of a /api/clusters_mgmt/v1/clusters/{id}/... path.
+func pathClusterID(path string) string {
+	_, rest, found := strings.Cut(path, "/clusters/")
+	if !found {
+		return ""
+	}
+	clusterID, _, _ := strings.Cut(rest, "/")
+	return clusterID
+}
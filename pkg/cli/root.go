@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/openshift/ocm-agent/pkg/cli/fakeocm"
+	"github.com/openshift/ocm-agent/pkg/cli/replay"
 	"github.com/openshift/ocm-agent/pkg/cli/serve"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -25,6 +27,9 @@ func NewCmdRoot() *cobra.Command {
 
 	// Add subcommands
 	rootCmd.AddCommand(serve.NewServeCmd())
+	rootCmd.AddCommand(serve.NewConfigCmd())
+	rootCmd.AddCommand(fakeocm.NewFakeOCMCmd())
+	rootCmd.AddCommand(replay.NewReplayCmd())
 
 	return rootCmd
 }
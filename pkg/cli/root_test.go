@@ -41,12 +41,16 @@ func TestRootCommandSubcommands(t *testing.T) {
 	rootCmd := cli.NewCmdRoot()
 
 	commands := rootCmd.Commands()
-	if len(commands) != 1 {
-		t.Errorf("Expected exactly 1 subcommand, got %d", len(commands))
+	if len(commands) != 4 {
+		t.Errorf("Expected exactly 4 subcommands, got %d", len(commands))
 	}
 
-	if len(commands) > 0 && commands[0].Use != "serve" {
-		t.Errorf("Expected first subcommand to be 'serve', got %s", commands[0].Use)
+	// Subcommands are sorted by name
+	expected := []string{"config", "fake-ocm", "replay RECORDING...", "serve"}
+	for i, command := range commands {
+		if i < len(expected) && command.Use != expected[i] {
+			t.Errorf("Expected subcommand %d to be '%s', got %s", i, expected[i], command.Use)
+		}
 	}
 }
 
@@ -83,6 +87,9 @@ func TestRootCommandHelp(t *testing.T) {
 		"Command line tool for OCM Agent",
 		"Usage:",
 		"Available Commands:",
+		"config",
+		"fake-ocm",
+		"replay",
 		"serve",
 	}
 
@@ -111,12 +118,10 @@ func TestRootCommandInvalidSubcommand(t *testing.T) {
 func TestRootCommandStructure(t *testing.T) {
 	rootCmd := cli.NewCmdRoot()
 
-	commands := rootCmd.Commands()
-	if len(commands) == 0 {
-		t.Fatal("Expected at least one subcommand")
+	serveCmd, _, err := rootCmd.Find([]string{"serve"})
+	if err != nil {
+		t.Fatalf("Expected serve subcommand: %v", err)
 	}
-
-	serveCmd := commands[0]
 	if serveCmd.Use != "serve" {
 		t.Errorf("Expected serve command, got %s", serveCmd.Use)
 	}
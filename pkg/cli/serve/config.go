@@ -6,8 +6,53 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/ocm-agent/pkg/config"
 )
 
+// ApplyConfig sets the flags of cmd which were not passed on the command line from the environment and from the
+// configuration file given with --config or the OCM_AGENT_CONFIG environment variable. Flags passed on the command
+// line take precedence over the environment, which takes precedence over the configuration file.
+func ApplyConfig(cmd *cobra.Command) error {
+	flags := cmd.Flags()
+	passed := map[string]bool{}
+	flags.Visit(func(flag *pflag.Flag) {
+		passed[flag.Name] = true
+	})
+
+	path, err := flags.GetString(config.ConfigFile)
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		path = os.Getenv(config.ConfigFileEnv)
+	}
+	if path != "" {
+		file, err := config.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, setting := range file.Settings() {
+			if passed[setting.Flag] {
+				continue
+			}
+			// The value isn't part of the error, as it may be a secret
+			if err := flags.Set(setting.Flag, setting.Value); err != nil {
+				return fmt.Errorf("invalid value of %s in configuration file '%s': %w", setting.Key, path, err)
+			}
+		}
+	}
+
+	if secretName, ok := os.LookupEnv(config.FleetSecretNameEnv); ok && !passed[config.FleetSecretName] {
+		if err := flags.Set(config.FleetSecretName, secretName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // ReadFlagsFromFile checks for '@' prefix, if found try to read value from file.
 func ReadFlagsFromFile(cmd *cobra.Command, names ...string) error {
 	for _, name := range names {
@@ -743,3 +743,89 @@ func TestReadFlagsFromFileOnlyNewlines(t *testing.T) {
 		t.Errorf("Expected empty string, got %s", value)
 	}
 }
+
+// writeConfigFile writes a configuration file with the given sections to a temporary directory
+func writeConfigFile(t *testing.T, sections string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "apiVersion: " + config.FileAPIVersion + "\nkind: " + config.FileKind + "\n" + sections
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestApplyConfigPrecedence tests that flags take precedence over the environment, which takes precedence over the file
+func TestApplyConfigPrecedence(t *testing.T) {
+	path := writeConfigFile(t, `
+ocm:
+  url: https://api.example.com
+  services: [service_logs, clusters_mgmt]
+mode:
+  test: true
+credentials:
+  fleetSecretName: from-file
+logging:
+  level: warning
+`)
+	t.Setenv(config.FleetSecretNameEnv, "from-env")
+
+	cmd := serve.NewServeCmd()
+	if err := cmd.Flags().Set(config.ConfigFile, path); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Flags().Set(config.LogLevel, "error"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := serve.ApplyConfig(cmd); err != nil {
+		t.Fatalf("ApplyConfig failed: %v", err)
+	}
+
+	expected := map[string]string{
+		config.OcmURL:          "https://api.example.com",
+		config.TestMode:        "true",
+		config.FleetSecretName: "from-env",
+		config.LogLevel:        "error",
+	}
+	for name, value := range expected {
+		if actual := cmd.Flags().Lookup(name).Value.String(); actual != value {
+			t.Errorf("Expected flag %s to be %s, got %s", name, value, actual)
+		}
+	}
+	services, err := cmd.Flags().GetStringSlice(config.Services)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 2 || services[0] != config.ServiceLogService || services[1] != config.ClustersService {
+		t.Errorf("Expected the services of the file, got %v", services)
+	}
+	if !cmd.Flags().Lookup(config.OcmURL).Changed {
+		t.Error("Expected the flags set by the file to count as passed for the required flags")
+	}
+}
+
+// TestApplyConfigFromEnvironment tests that the configuration file path is read from the environment
+func TestApplyConfigFromEnvironment(t *testing.T) {
+	t.Setenv(config.ConfigFileEnv, writeConfigFile(t, "ocm:\n  url: https://api.example.com\n"))
+
+	cmd := serve.NewServeCmd()
+	if err := serve.ApplyConfig(cmd); err != nil {
+		t.Fatalf("ApplyConfig failed: %v", err)
+	}
+	if value, _ := cmd.Flags().GetString(config.OcmURL); value != "https://api.example.com" {
+		t.Errorf("Expected the OCM URL of the file, got %s", value)
+	}
+}
+
+// TestApplyConfigInvalidFile tests that invalid configuration files are rejected
+func TestApplyConfigInvalidFile(t *testing.T) {
+	cmd := serve.NewServeCmd()
+	if err := cmd.Flags().Set(config.ConfigFile, writeConfigFile(t, "ocm:\n  endpoint: https://api.example.com\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	err := serve.ApplyConfig(cmd)
+	if err == nil || !strings.Contains(err.Error(), "endpoint") {
+		t.Errorf("Expected an error about the unknown field, got %v", err)
+	}
+}
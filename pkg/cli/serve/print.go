@@ -0,0 +1,100 @@
+package serve
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/ocm-agent/pkg/config"
+)
+
+var (
+	configPrintLong = templates.LongDesc(`
+	Print the effective configuration of the OCM Agent server
+
+	The configuration is read from the flags, the environment and the configuration file as by the serve command,
+	and printed as a configuration file. Secrets set directly are redacted, values read from a file are shown as their
+	'@' file path.
+	`)
+
+	configPrintExample = templates.Examples(`
+	# Print the configuration the OCM agent server would run with
+	ocm-agent config print --config /etc/ocm-agent/config.yaml
+
+	# Print the configuration as JSON, with a flag overriding the configuration file
+	ocm-agent config print --config /etc/ocm-agent/config.yaml --log-level debug -o json
+	`)
+)
+
+// NewConfigCmd initializes the config command and its subcommands
+func NewConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Shows the configuration of the OCM Agent server",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(newConfigPrintCmd())
+
+	return cmd
+}
+
+// newConfigPrintCmd initializes the config print command, which accepts the flags of the serve command
+func newConfigPrintCmd() *cobra.Command {
+	o := NewServeOptions()
+	var output string
+
+	cmd := &cobra.Command{
+		Use:     "print",
+		Short:   "Prints the effective configuration of the OCM Agent server",
+		Long:    configPrintLong,
+		Example: configPrintExample,
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(printConfig(cmd, output))
+		},
+	}
+
+	// The flags are not bound to viper, which holds the flags of the serve command
+	o.addFlags(cmd)
+	cmd.Flags().StringVarP(&output, "output", "o", "yaml", "Output format, yaml or json (string)")
+
+	return cmd
+}
+
+// printConfig prints the effective configuration of cmd with its secrets redacted
+func printConfig(cmd *cobra.Command, output string) error {
+	if err := ApplyConfig(cmd); err != nil {
+		return err
+	}
+
+	file, err := config.FileFromFlags(cmd.Flags())
+	if err != nil {
+		return err
+	}
+	file.Redact()
+
+	var data []byte
+	switch output {
+	case "yaml":
+		data, err = yaml.Marshal(file)
+	case "json":
+		data, err = json.MarshalIndent(file, "", "  ")
+		data = append(data, '\n')
+	default:
+		return fmt.Errorf("unknown output format '%s', expected yaml or json", output)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = cmd.OutOrStdout().Write(data)
+	return err
+}
@@ -0,0 +1,50 @@
+package serve_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/openshift/ocm-agent/pkg/cli/serve"
+	"github.com/openshift/ocm-agent/pkg/config"
+)
+
+// TestConfigPrint tests that the effective configuration is printed with its secrets redacted
+func TestConfigPrint(t *testing.T) {
+	path := writeConfigFile(t, `
+ocm:
+  url: https://api.example.com
+credentials:
+  accessToken: secret-token
+  clusterID: abcd-1234
+`)
+
+	cmd := serve.NewConfigCmd()
+	output := &bytes.Buffer{}
+	cmd.SetOut(output)
+	cmd.SetArgs([]string{"print", "--config", path, "--cache-ttl", "1m"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("config print failed: %v", err)
+	}
+
+	printed := output.String()
+	for _, expected := range []string{
+		"apiVersion: " + config.FileAPIVersion,
+		"url: https://api.example.com",
+		"clusterID: abcd-1234",
+		"accessToken: " + config.RedactedValue,
+		"cacheTTL: 1m0s",
+	} {
+		if !strings.Contains(printed, expected) {
+			t.Errorf("Expected the printed configuration to contain %q, got:\n%s", expected, printed)
+		}
+	}
+	if strings.Contains(printed, "secret-token") {
+		t.Errorf("Expected the access token to be redacted, got:\n%s", printed)
+	}
+
+	// The printed configuration is a valid configuration file
+	if _, err := config.ParseFile(output.Bytes()); err != nil {
+		t.Errorf("Expected the printed configuration to be valid, got %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package serve
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openshift/ocm-agent/pkg/config"
+	"github.com/openshift/ocm-agent/pkg/k8s"
+)
+
+var _ = Describe("Cluster connection reloader", func() {
+	const clusterID = "bd845de4-5c16-4067-a868-15b02d55ccef"
+
+	var (
+		kubeClient     client.Client
+		clusterVersion *unstructured.Unstructured
+		pullSecret     *corev1.Secret
+		o              *serveOptions
+	)
+
+	BeforeEach(func() {
+		clusterVersion = &unstructured.Unstructured{}
+		clusterVersion.SetGroupVersionKind(k8s.ClusterVersionGVK)
+		clusterVersion.SetName(k8s.ClusterVersionName)
+		Expect(unstructured.SetNestedField(clusterVersion.Object, clusterID, "spec", "clusterID")).To(Succeed())
+		pullSecret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: k8s.PullSecretNamespace, Name: k8s.PullSecretName},
+			Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {"cloud.openshift.com": {"auth": "dG9rZW4="}}}`)},
+		}
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		kubeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterVersion, pullSecret).Build()
+
+		viper.Set(config.OcmURL, "http://localhost")
+		DeferCleanup(viper.Reset)
+		o = NewServeOptions()
+	})
+
+	setClusterID := func(id string) {
+		Expect(unstructured.SetNestedField(clusterVersion.Object, id, "spec", "clusterID")).To(Succeed())
+		Expect(kubeClient.Update(context.Background(), clusterVersion)).To(Succeed())
+	}
+
+	It("refuses the reload and keeps the connection when the cluster ID changes", func() {
+		reloader, err := o.newClusterConnectionReloader(kubeClient)
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(func() { _ = reloader.Connection().Close() })
+		connection := reloader.Connection()
+		Expect(o.externalClusterID).To(Equal(clusterID))
+
+		setClusterID("2a3c9a4e-4f5c-4d8e-9b1a-0c6f1f8e2d7b")
+		reloaded, err := reloader.Reload()
+		Expect(err).To(MatchError(ContainSubstring("restart the agent")))
+		Expect(reloaded).To(BeFalse())
+		Expect(reloader.Connection()).To(BeIdenticalTo(connection))
+		Expect(o.externalClusterID).To(Equal(clusterID))
+		Expect(viper.GetString(config.ExternalClusterID)).To(Equal(clusterID))
+	})
+
+	It("follows a rotated pull secret of the same cluster", func() {
+		reloader, err := o.newClusterConnectionReloader(kubeClient)
+		Expect(err).ToNot(HaveOccurred())
+		DeferCleanup(func() { _ = reloader.Connection().Close() })
+		connection := reloader.Connection()
+
+		pullSecret.Data[corev1.DockerConfigJsonKey] = []byte(`{"auths": {"cloud.openshift.com": {"auth": "cm90YXRlZA=="}}}`)
+		Expect(kubeClient.Update(context.Background(), pullSecret)).To(Succeed())
+		reloaded, err := reloader.Reload()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reloaded).To(BeTrue())
+		Expect(reloader.Connection()).ToNot(BeIdenticalTo(connection))
+	})
+})
@@ -1,8 +1,11 @@
 package serve
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -15,31 +18,63 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/templates"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/openshift/ocm-agent/pkg/config"
 	"github.com/openshift/ocm-agent/pkg/handlers"
 	"github.com/openshift/ocm-agent/pkg/k8s"
 	"github.com/openshift/ocm-agent/pkg/logging"
 	"github.com/openshift/ocm-agent/pkg/metrics"
+	"github.com/openshift/ocm-agent/pkg/tracing"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	sdk "github.com/openshift-online/ocm-sdk-go"
 )
 
+// How long the listeners wait for the headers of a request by default
+const defaultReadHeaderTimeout = 3 * time.Second
+
 // serveOptions define the configuration options required by OCM agent to serve.
 type serveOptions struct {
+	configFile        string
 	accessToken       string
+	accessTokenFile   string
+	fromCluster       bool
 	services          []string
 	ocmURL            string
 	externalClusterID string
 	ocmClientID       string
 	ocmClientSecret   string
+	fleetSecretName   string
+	fleetEnvironments []string
+	servicePort       int
+	metricsPort       int
+	readHeaderTimeout time.Duration
+	retry             ocm.RetryPolicy
+	transport         ocm.TransportOptions
 	debug             bool
+	logFormat         string
+	logLevel          string
 	fleetMode         bool
 	testMode          bool
-	logger            logrus.Logger
+	cacheTTL          time.Duration
+	cacheMaxStale     time.Duration
+	proxyAuthz        bool
+	proxyAuthzTTL     time.Duration
+	auditLog          string
+	auditEvents       bool
+	tracing           tracing.Options
+	clusterMetrics    metrics.ClusterMetricsOptions
+	namespaces        []string
+	webhookPath       string
+	fleetWebhookPath  string
+	metricsPath       string
+	logger            *logrus.Logger
 }
 
 var (
@@ -53,6 +88,10 @@ var (
 	it requires a client ID and a client secret to be able to authenticate with OCM.
 
 	For testing purposes, fleet mode can leverage access token as well.
+
+	Every flag can also be set in a configuration file passed with --config or the OCM_AGENT_CONFIG environment variable.
+	Flags passed on the command line take precedence over environment variables, which take precedence over the
+	configuration file.
 	`)
 
 	serviceExample = templates.Examples(`
@@ -63,6 +102,9 @@ var (
 	# Start the OCM agent server in traditional OSD/ROSA mode by accepting token from a file (value starting with '@' is considered a file)
 	ocm-agent serve -t @tokenfile --services "$SERVICE" --ocm-url @urlfile --cluster-id @clusteridfile
 
+	# Start the OCM agent server in traditional OSD/ROSA mode caching proxied OCM responses for one minute
+	ocm-agent serve -t @tokenfile --services "$SERVICE" --ocm-url @urlfile --cluster-id @clusteridfile --cache-ttl 1m
+
 	# Start the OCM agent server in traditional OSD/ROSA in debug mode
 	ocm-agent serve -t @tokenfile --services "$SERVICE" --ocm-url @urlfile --cluster-id @clusteridfile --debug
 
@@ -74,19 +116,34 @@ var (
 
 	# Start OCM agent server in fleet mode on staging clusters (in development/testing mode)
 	ocm-agent serve --services $SERVICE --ocm-url $URL --fleet-mode --ocm-client-id $CLIENT_ID --ocm-client-secret $CLIENT_SECRET
-	`)
 
-	sdkclient *sdk.Connection
+	# Start the OCM agent server with the settings of a configuration file, overriding its log level
+	ocm-agent serve --config /etc/ocm-agent/config.yaml --log-level debug
+
+	# Start a second, isolated OCM agent server reading its notifications from its own namespace
+	ocm-agent serve --config /etc/ocm-agent/config.yaml --namespaces ocm-agent-staging --service-port 9081 --metrics-port 9383
+	`)
 )
 
 func NewServeOptions() *serveOptions {
-	return &serveOptions{}
+	return &serveOptions{
+		logFormat:         logging.FormatText,
+		logLevel:          logrus.InfoLevel.String(),
+		servicePort:       consts.OCMAgentServicePort,
+		metricsPort:       consts.OCMAgentMetricsPort,
+		readHeaderTimeout: defaultReadHeaderTimeout,
+		retry:             ocm.DefaultRetryPolicy(),
+		namespaces:        []string{handlers.OCMAgentNamespaceName},
+		webhookPath:       consts.WebhookReceiverPath,
+		metricsPath:       consts.MetricsPath,
+		// Handlers and pkg/ocm log through the standard logger, configure it rather than a separate one
+		logger: logrus.StandardLogger(),
+	}
 }
 
 // NewServeCmd initializes serve command and it's flags
 func NewServeCmd() *cobra.Command {
 	o := NewServeOptions()
-	o.logger = *logging.NewLogger()
 
 	var cmd = &cobra.Command{
 		Use:     "serve",
@@ -95,13 +152,19 @@ func NewServeCmd() *cobra.Command {
 		Example: serviceExample,
 		Args:    cobra.OnlyValidArgs,
 		PreRun: func(cmd *cobra.Command, args []string) {
+			// Set the flags not passed on the command line from the environment and the configuration file first,
+			// so that the flags they set count as passed for the validations below
+			kcmdutil.CheckErr(ApplyConfig(cmd))
+
 			clientID, _ := cmd.Flags().GetString(config.OCMClientID)
 			clientSecret, _ := cmd.Flags().GetString(config.OCMClientSecret)
 			mode, _ := cmd.Flags().GetBool(config.FleetMode)
 			test, _ := cmd.Flags().GetBool(config.TestMode)
+			fromCluster, _ := cmd.Flags().GetBool(config.PullSecretFromCluster)
 
-			// Mark AccessToken and ClusterID as required only in Classic mode or in Fleet mode only if test mode is enabled
-			if clientID == "" && clientSecret == "" {
+			// Mark AccessToken and ClusterID as required only in Classic mode or in Fleet mode only if test mode is enabled,
+			// unless they are read from the cluster
+			if clientID == "" && clientSecret == "" && !fromCluster {
 				if !mode || (mode && test) {
 					_ = cmd.MarkFlagRequired(config.AccessToken)
 					_ = cmd.MarkFlagRequired(config.ExternalClusterID)
@@ -119,15 +182,7 @@ func NewServeCmd() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVarP(&o.ocmURL, config.OcmURL, "", "", "OCM URL (string)")
-	cmd.Flags().StringVarP(&o.accessToken, config.AccessToken, "t", "", "Access token for OCM (string)")
-	cmd.Flags().StringVarP(&o.externalClusterID, config.ExternalClusterID, "c", "", "Cluster ID (string)")
-	cmd.Flags().StringVarP(&o.ocmClientID, config.OCMClientID, "", "", "OCM Client ID for testing fleet mode (string)")
-	cmd.Flags().StringVarP(&o.ocmClientSecret, config.OCMClientSecret, "", "", "OCM Client Secret for testing fleet mode (string)")
-	cmd.Flags().StringSliceVarP(&o.services, config.Services, "", []string{}, "OCM service name (string)")
-	cmd.Flags().BoolVar(&o.fleetMode, config.FleetMode, false, "Fleet Mode (bool)")
-	cmd.Flags().BoolVar(&o.testMode, config.TestMode, false, "Test Mode (bool)")
-	cmd.PersistentFlags().BoolVarP(&o.debug, config.Debug, "d", false, "Debug mode enable")
+	o.addFlags(cmd)
 	kcmdutil.CheckErr(viper.BindPFlags(cmd.Flags()))
 
 	// ocm-url and services flags are always required
@@ -142,13 +197,68 @@ func NewServeCmd() *cobra.Command {
 	cmd.MarkFlagsMutuallyExclusive(config.AccessToken, config.OCMClientSecret)
 	cmd.MarkFlagsMutuallyExclusive(config.ExternalClusterID, config.OCMClientID)
 	cmd.MarkFlagsMutuallyExclusive(config.ExternalClusterID, config.OCMClientSecret)
+	// The pull secret and cluster ID are either read from the cluster or passed as flags
+	cmd.MarkFlagsMutuallyExclusive(config.PullSecretFromCluster, config.AccessToken)
+	cmd.MarkFlagsMutuallyExclusive(config.PullSecretFromCluster, config.ExternalClusterID)
+	cmd.MarkFlagsMutuallyExclusive(config.PullSecretFromCluster, config.FleetMode)
 
 	return cmd
 }
 
+// addFlags defines the flags of the server configuration on cmd.
+func (o *serveOptions) addFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	flags.StringVar(&o.configFile, config.ConfigFile, "", "Configuration file in YAML or JSON the flags not passed on the command line are read from, defaults to the OCM_AGENT_CONFIG environment variable (string)")
+	flags.StringVarP(&o.ocmURL, config.OcmURL, "", "", "OCM URL (string)")
+	flags.StringVarP(&o.accessToken, config.AccessToken, "t", "", "Access token for OCM (string)")
+	flags.StringVarP(&o.externalClusterID, config.ExternalClusterID, "c", "", "Cluster ID (string)")
+	flags.StringVarP(&o.ocmClientID, config.OCMClientID, "", "", "OCM Client ID for testing fleet mode (string)")
+	flags.StringVarP(&o.ocmClientSecret, config.OCMClientSecret, "", "", "OCM Client Secret for testing fleet mode (string)")
+	flags.StringVar(&o.fleetSecretName, config.FleetSecretName, "", "Name of the secret mounted under /secrets holding the OCM client credentials in fleet mode, defaults to the OCM_AGENT_SECRET_NAME environment variable (string)")
+	flags.StringSliceVar(&o.fleetEnvironments, config.FleetEnvironments, nil, "Named OCM environments the fleet notifications can be sent to besides the default one, as name=secret pairs of the secrets mounted under /secrets holding their OCM client credentials (string)")
+	flags.StringSliceVarP(&o.services, config.Services, "", []string{}, "OCM service name (string)")
+	flags.BoolVar(&o.fleetMode, config.FleetMode, false, "Fleet Mode (bool)")
+	flags.BoolVar(&o.testMode, config.TestMode, false, "Test Mode (bool)")
+	flags.BoolVar(&o.fromCluster, config.PullSecretFromCluster, false, "Read the access token from the openshift-config/pull-secret Secret and the cluster ID from the ClusterVersion, following their updates, in classic mode (bool)")
+	flags.IntVar(&o.servicePort, config.ServicePort, consts.OCMAgentServicePort, "Port the web service listens on (int)")
+	flags.IntVar(&o.metricsPort, config.MetricsPort, consts.OCMAgentMetricsPort, "Port the metrics and admin endpoints listen on (int)")
+	flags.StringVar(&o.webhookPath, config.WebhookReceiverPath, consts.WebhookReceiverPath, "Path of the Alertmanager webhook receiver on the service port (string)")
+	flags.StringVar(&o.fleetWebhookPath, config.FleetWebhookReceiverPath, "", "Path of a fleet mode Alertmanager webhook receiver served besides the classic one in classic mode, with the OCM client credentials of --fleet-secret-name, empty to disable (string)")
+	flags.StringVar(&o.metricsPath, config.MetricsPath, consts.MetricsPath, "Path of the metrics on the metrics port (string)")
+	flags.StringSliceVar(&o.namespaces, config.Namespaces, []string{handlers.OCMAgentNamespaceName}, "Namespaces the notification custom resources are read from, those of the first namespaces taking precedence (string)")
+	flags.DurationVar(&o.readHeaderTimeout, config.ReadHeaderTimeout, defaultReadHeaderTimeout, "How long the listeners wait for the headers of a request (duration)")
+	flags.IntVar(&o.retry.Limit, config.OCMRetryLimit, ocm.DefaultRetryLimit, "How many times a failed OCM call is retried, 0 disables retries (int)")
+	flags.DurationVar(&o.retry.Interval, config.OCMRetryInterval, ocm.DefaultRetryInterval, "Time before the first retry of a failed OCM call, doubled on every retry (duration)")
+	flags.Float64Var(&o.retry.Jitter, config.OCMRetryJitter, ocm.DefaultRetryJitter, "Random variation of the interval between retries of OCM calls, between 0 and 1 (float)")
+	flags.StringVar(&o.transport.ProxyURL, config.OCMProxy, "", "URL of the proxy the requests to OCM are sent through, defaults to the HTTPS_PROXY environment variable (string)")
+	flags.StringVar(&o.transport.NoProxy, config.OCMNoProxy, "", "Comma-separated hosts, domains and CIDRs reached without the proxy, defaults to the NO_PROXY environment variable (string)")
+	flags.StringVar(&o.transport.CABundleFile, config.OCMCABundle, "", "PEM file of the CAs trusted for OCM in addition to the system ones, such as the trusted CA bundle of the cluster (string)")
+	flags.DurationVar(&o.cacheTTL, config.CacheTTL, 30*time.Second, "How long to cache read-only OCM proxy responses, 0 disables caching (duration)")
+	flags.DurationVar(&o.cacheMaxStale, config.CacheMaxStale, 10*time.Minute, "How long cached OCM proxy responses may be served while OCM is unavailable (duration)")
+	flags.BoolVar(&o.proxyAuthz, config.ProxyAuthorization, false, "Authorize the reads of the proxy routes too, their changes are always authorized with a SubjectAccessReview (bool)")
+	flags.DurationVar(&o.proxyAuthzTTL, config.ProxyAuthorizationCacheTTL, 30*time.Second, "How long to cache authorization decisions for the proxy routes (duration)")
+	flags.StringVar(&o.auditLog, config.AuditLog, "-", "File to append the JSON audit records of mutating OCM calls to, '-' for stdout and empty to disable (string)")
+	flags.BoolVar(&o.auditEvents, config.AuditEvents, false, "Record Events on the related notification resources for mutating OCM calls (bool)")
+	flags.StringVar(&o.tracing.Exporter, config.TracingExporter, tracing.ExporterNone, "Exporter of the OpenTelemetry spans, one of none, otlp-grpc, otlp-http or stdout (string)")
+	flags.StringVar(&o.tracing.Endpoint, config.TracingEndpoint, "", "host:port of the OTLP collector, defaults to the OTEL_EXPORTER_OTLP_ENDPOINT environment variable (string)")
+	flags.BoolVar(&o.tracing.Insecure, config.TracingInsecure, false, "Send the spans to the OTLP collector without TLS (bool)")
+	flags.Float64Var(&o.tracing.SampleRatio, config.TracingSampleRatio, 1, "Ratio of the traces started by the agent which are sampled, between 0 and 1 (float)")
+	flags.BoolVar(&o.clusterMetrics.Enabled, config.FleetClusterMetrics, false, "Label the fleet mode metrics by hosted cluster, for a bounded number of hosted clusters (bool)")
+	flags.IntVar(&o.clusterMetrics.MaxClusters, config.FleetClusterMetricsMax, metrics.DefaultMaxClusterSeries, "Number of hosted clusters the per-cluster metrics are kept for, those with the most failures first (int)")
+	flags.DurationVar(&o.clusterMetrics.TTL, config.FleetClusterMetricsTTL, metrics.DefaultClusterSeriesTTL, "How long the per-cluster metrics of a hosted cluster are kept without update (duration)")
+	cmd.PersistentFlags().BoolVarP(&o.debug, config.Debug, "d", false, "Debug mode enable")
+	flags.StringVar(&o.logFormat, config.LogFormat, logging.FormatText, "Format of the log entries, text or json (string)")
+	flags.StringVar(&o.logLevel, config.LogLevel, logrus.InfoLevel.String(), "Level of the log entries, one of trace, debug, info, warning, error (string)")
+}
+
 // Complete initialisation for the server
 func (o *serveOptions) Complete(cmd *cobra.Command, args []string) error {
 
+	// The access token is read again from its file when it changes
+	if strings.HasPrefix(o.accessToken, "@") {
+		o.accessTokenFile = strings.TrimPrefix(o.accessToken, "@")
+	}
+
 	// ReadFlagsFromFile would read the values of flags from files (if any)
 	err := ReadFlagsFromFile(cmd, config.AccessToken, config.OcmURL, config.Services, config.ExternalClusterID)
 	// Cobra keeps the filename argument as the first element of the services slice
@@ -160,19 +270,55 @@ func (o *serveOptions) Complete(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	for name, port := range map[string]int{config.ServicePort: o.servicePort, config.MetricsPort: o.metricsPort} {
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("--%s must be between 1 and 65535", name)
+		}
+	}
+	if err := validatePaths(o.webhookPath, o.fleetWebhookPath, o.metricsPath); err != nil {
+		return err
+	}
+	if o.fleetWebhookPath != "" && (o.fleetMode || o.fleetSecretName == "") {
+		return fmt.Errorf("--%s requires classic mode and --%s", config.FleetWebhookReceiverPath, config.FleetSecretName)
+	}
+	if len(o.namespaces) == 0 {
+		return fmt.Errorf("--%s can't be empty", config.Namespaces)
+	}
+	for _, namespace := range o.namespaces {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return fmt.Errorf("--%s: invalid namespace '%s': %s", config.Namespaces, namespace, strings.Join(errs, ", "))
+		}
+	}
+	if o.retry.Limit < 0 || o.retry.Jitter < 0 || o.retry.Jitter > 1 {
+		return fmt.Errorf("--%s can't be negative and --%s must be between 0 and 1", config.OCMRetryLimit, config.OCMRetryJitter)
+	}
+
+	if len(o.fleetEnvironments) > 0 && !o.fleetMode && o.fleetWebhookPath == "" {
+		return fmt.Errorf("--%s requires --%s or --%s", config.FleetEnvironments, config.FleetMode, config.FleetWebhookReceiverPath)
+	}
+	if _, err := parseEnvironments(o.fleetEnvironments); err != nil {
+		return fmt.Errorf("--%s: %w", config.FleetEnvironments, err)
+	}
+
+	if err := o.transport.Validate(); err != nil {
+		return fmt.Errorf("--%s: %w", config.OCMProxy, err)
+	}
+
+	if o.clusterMetrics.Enabled && (o.clusterMetrics.MaxClusters < 1 || o.clusterMetrics.TTL <= 0) {
+		return fmt.Errorf("--%s must be positive and --%s longer than 0 when --%s is set",
+			config.FleetClusterMetricsMax, config.FleetClusterMetricsTTL, config.FleetClusterMetrics)
+	}
+
 	// Check if debug mode is enabled and set the logging level accordingly
+	logLevel := o.logLevel
 	if o.debug {
-		o.logger.Level = logging.DebugLogLevel
+		logLevel = logging.DebugLogLevel.String()
 	}
 
-	return nil
+	return logging.Configure(o.logger, o.logFormat, logLevel)
 }
 
 func (o *serveOptions) Run() error {
-	var ocmAgentClientID string
-	var ocmAgentClientSecret string
-	var ocmAgentURL string
-
 	o.logger.Info("Starting ocm-agent server")
 	o.logger.WithField("URL", o.ocmURL).Debug("OCM URL configured")
 	o.logger.WithField("Service", o.services).Debug("OCM Service configured")
@@ -189,18 +335,71 @@ func (o *serveOptions) Run() error {
 		o.logger.WithField("TestMode", o.testMode).Info("Test mode not configured")
 	}
 
+	if o.fleetWebhookPath != "" {
+		o.logger.WithFields(logrus.Fields{"Path": o.fleetWebhookPath, "Secret": o.fleetSecretName}).Info("Fleet mode webhook receiver configured besides the classic one")
+	}
+
+	o.logger.WithField("Namespaces", o.namespaces).Info("Reading the notification custom resources")
+
+	if o.transport.ProxyURL != "" || o.transport.CABundleFile != "" {
+		o.logger.WithFields(logrus.Fields{"Proxy": o.transport.RedactedProxyURL(), "NoProxy": o.transport.NoProxy, "CABundle": o.transport.CABundleFile}).Info("OCM egress configured")
+	}
+
+	// Export the spans of the webhook receiver, Kubernetes and OCM calls, if configured
+	tracerProvider, err := tracing.NewTracerProvider(context.Background(), o.tracing)
+	if err != nil {
+		o.logger.WithError(err).Fatal("Can't initialise tracing")
+		return err
+	}
+	if tracerProvider != nil {
+		o.logger.WithFields(logrus.Fields{"Exporter": o.tracing.Exporter, "Endpoint": o.tracing.Endpoint}).Info("Tracing configured")
+		defer func() { _ = tracerProvider.Shutdown(context.Background()) }()
+		logrus.AddHook(tracing.NewLogHook())
+	}
+
+	// Initialize k8s client
+	client, err := k8s.NewClient()
+	if err != nil {
+		o.logger.WithError(err).Fatal("Can't initialise k8s client, ensure KUBECONFIG is set")
+		return err
+	}
+	if tracerProvider != nil {
+		client = tracing.NewKubeClient(client)
+	}
+
+	if o.clusterMetrics.Enabled {
+		o.logger.WithFields(logrus.Fields{"MaxClusters": o.clusterMetrics.MaxClusters, "TTL": o.clusterMetrics.TTL}).Info("Per-cluster fleet metrics configured")
+	}
+	metrics.ConfigureClusterMetrics(o.clusterMetrics)
+
+	// Expose the state recorded in the notification custom resources, which survives restarts
+	if err := prometheus.Register(metrics.NewStatusCollector(client, o.namespaces, o.fleetMode, metrics.DefaultStatusCacheTTL)); err != nil {
+		o.logger.WithError(err).Fatal("Can't register the notification status metrics")
+		return err
+	}
+	if o.fleetWebhookPath != "" {
+		if err := prometheus.Register(metrics.NewStatusCollector(client, o.namespaces, true, metrics.DefaultStatusCacheTTL)); err != nil {
+			o.logger.WithError(err).Fatal("Can't register the fleet notification status metrics")
+			return err
+		}
+	}
+
 	// create new router for metrics
 	rMetrics := mux.NewRouter()
-	rMetrics.Path(consts.MetricsPath).Handler(promhttp.Handler())
+	rMetrics.Path(o.metricsPath).Handler(promhttp.Handler())
+	// The log levels can always only be changed by authorized callers, as debug logs may contain sensitive data
+	logLevelsHandler := handlers.NewLogLevelsHandler()
+	rMetrics.Path(consts.LogLevelsPath).Handler(logging.Middleware(
+		handlers.NewProxyAuthorizer(client, o.proxyAuthzTTL).Authorize(handlers.LogLevelsResource, "", logLevelsHandler.ServeHTTP)))
 
 	// Listen on the metrics port with a separated goroutine
-	o.logger.WithField("Port", consts.OCMAgentMetricsPort).Info("Start listening on metrics port")
+	o.logger.WithField("Port", o.metricsPort).Info("Start listening on metrics port")
 	go func() {
 		// Adding ReadHeaderTimeout to fix below gosec error
 		// G114: Use of net/http serve function that has no support for setting timeouts
 		server := &http.Server{
-			Addr:              ":" + strconv.Itoa(consts.OCMAgentMetricsPort),
-			ReadHeaderTimeout: 3 * time.Second,
+			Addr:              ":" + strconv.Itoa(o.metricsPort),
+			ReadHeaderTimeout: o.readHeaderTimeout,
 			Handler:           rMetrics,
 		}
 		err := server.ListenAndServe()
@@ -210,84 +409,66 @@ func (o *serveOptions) Run() error {
 		}
 	}()
 
-	// Initialize k8s client
-	client, err := k8s.NewClient()
+	// Initialize the recorder for Events on the notification custom resources
+	recorder, err := k8s.NewEventRecorder()
 	if err != nil {
-		o.logger.WithError(err).Fatal("Can't initialise k8s client, ensure KUBECONFIG is set")
+		o.logger.WithError(err).Fatal("Can't initialise k8s event recorder")
 		return err
 	}
 
 	// Depending on whether the FleetMode is enabled or not, we need to initiate the OCM SDK connection accordingly
-	// If fleet mode is not enabled, we will fetch the cluster ID and access token to initiate connection with OCM
-	if !o.fleetMode || (o.fleetMode && o.testMode) {
-		sdkclient, err = ocm.NewConnection().Build(viper.GetString(config.OcmURL),
-			viper.GetString(config.ExternalClusterID),
-			viper.GetString(config.AccessToken))
-		if err != nil {
-			o.logger.WithError(err).Fatal("Can't initialise OCM sdk.Connection client")
-			return err
-		}
-		o.logger.Info("Connection with OCM initialised successfully")
-		// Continuously check OCM connection
-		go func() {
-			for {
-				o.logger.Info("OCM connection check starting")
-				response, _ := sdkclient.AccountsMgmt().V1().CurrentAccount().Get().Send()
-				if response.Status() == http.StatusUnauthorized {
-					o.logger.Info("OCM connection check failure")
-					metrics.SetPullSecretInvalidMetricFailure()
-					time.Sleep(1 * time.Minute)
-				} else {
-					o.logger.Info("OCM connection check success")
-					metrics.SetPullSecretInvalidMetricSuccess()
-					time.Sleep(5 * time.Minute)
-				}
-			}
-		}()
-	} else {
-		// If fleet mode is enabled, the connection to OCM needs to initiate using client ID and client secret
-		// On the managed cluster, the client ID and secret will be fetched from the secret volume however for
-		// local testing, the client ID and secret can be passed directly as flags for ocm-agent CLI.
-		ocmAgentClientID = viper.GetString(config.OCMClientID)
-		ocmAgentClientSecret = viper.GetString(config.OCMClientSecret)
-		ocmAgentURL = viper.GetString(config.OcmURL)
-
-		if ocmAgentClientID == "" && ocmAgentClientSecret == "" {
-			clientID, err := os.ReadFile(consts.OCMAgentAccessFleetSecretPathBase + os.Getenv("OCM_AGENT_SECRET_NAME") + "/" + consts.OCMAgentAccessFleetSecretClientKey)
-			ocmAgentClientID = string(clientID)
-			if err != nil {
-				o.logger.WithError(err).Fatal("Can't find value for secret key ", consts.OCMAgentAccessFleetSecretClientKey)
-				return err
-			}
+	// The connection is rebuilt when the files holding its credentials change, so that they can be rotated
+	connections, err := o.newConnectionReloader(client)
+	if err != nil {
+		o.logger.WithError(err).Fatal("Can't initialise OCM sdk.Connection client")
+		return err
+	}
+	o.logger.Info("Connection with OCM initialised successfully")
+	go connections.Watch(context.Background())
 
-			clientSecret, err := os.ReadFile(consts.OCMAgentAccessFleetSecretPathBase + os.Getenv("OCM_AGENT_SECRET_NAME") + "/" + consts.OCMAgentAccessFleetSecretClientSecretKey)
-			ocmAgentClientSecret = string(clientSecret)
-			if err != nil {
-				o.logger.WithError(err).Fatal("Can't find value for secret key ", consts.OCMAgentAccessFleetSecretClientSecretKey)
-				return err
-			}
+	// Continuously check the tokens of the OCM connection and whether OCM is reachable
+	go ocm.NewConnectionMonitor(connections.Connection, !o.fleetMode).WithEnvironment(metrics.OCMEnvironmentDefault).Run(context.Background())
 
-			url, err := os.ReadFile(consts.OCMAgentAccessFleetSecretPathBase + os.Getenv("OCM_AGENT_SECRET_NAME") + "/" + consts.OCMAgentAccessFleetSecretURLKey)
-			ocmAgentURL = string(url)
-			if err != nil {
-				o.logger.WithError(err).Fatal("Can't find value for secret key ", consts.OCMAgentAccessFleetSecretURLKey)
-				return err
-			}
-		}
+	// Initialize OCMClient
+	// Record the latency, status and retries of every call sent to OCM; responses served from the proxy cache are not calls
+	baseOCMClient := ocm.NewInstrumentedOcmClient(ocm.NewReloadingOcmClient(connections), metrics.OCMEnvironmentDefault)
 
-		sdkclient, err = sdk.NewConnectionBuilder().URL(ocmAgentURL).Client(ocmAgentClientID, ocmAgentClientSecret).Insecure(false).Build()
+	// Record every mutating OCM call to the audit log
+	auditSink, err := o.newAuditSink(recorder)
+	if err != nil {
+		o.logger.WithError(err).Fatal("Can't initialise the audit log")
+		return err
+	}
+	audited := func(c ocm.OCMClient) ocm.OCMClient {
+		if auditSink == nil {
+			return c
+		}
+		return ocm.NewAuditingOcmClient(c, auditSink)
+	}
+	// Trace every OCM call, outside of the cache and audit log so that cache hits and audits are part of the span
+	traced := func(c ocm.OCMClient) ocm.OCMClient {
+		if tracerProvider == nil {
+			return c
+		}
+		return ocm.NewTracingOcmClient(c)
+	}
+	ocmclient := traced(audited(baseOCMClient))
+	// The fleet mode receivers send the notifications of the named OCM environments with their own clients
+	newFleetWebhookReceiver := func(ocmClient ocm.OCMClient) (http.Handler, error) {
+		environments, err := o.newEnvironmentClients()
 		if err != nil {
-			o.logger.WithError(err).Fatal("Can't initialise OCM sdk.connection client in fleet mode")
-			return err
+			return nil, err
+		}
+		for name, environment := range environments {
+			environments[name] = traced(audited(environment))
 		}
-		o.logger.Info("Connection with OCM initialised successfully in fleet mode")
+		return handlers.NewWebhookRHOBSReceiverHandler(client, ocmClient, recorder).WithNamespaces(o.namespaces).WithEnvironments(environments), nil
 	}
 
-	// Initialize OCMClient
-	ocmclient := ocm.NewOcmClient(sdkclient)
-
 	// create a new router
 	r := mux.NewRouter()
+	// Carry a request-scoped logger with the correlation ID of every request
+	r.Use(logging.Middleware)
 
 	livezHandler := handlers.NewLivezHandler()
 	readyzHandler := handlers.NewReadyzHandler()
@@ -298,46 +479,101 @@ func (o *serveOptions) Run() error {
 		// The webhook receiver is independent of the enabled services in the configmap
 		// as it's not a direct reverse proxy and doesn't directly reflect a single service
 		o.logger.Info("Initialising alertmanager webhook handler in fleet mode")
-		webhookReceiverHandler := handlers.NewWebhookRHOBSReceiverHandler(client, ocmclient)
-		r.Path(consts.WebhookReceiverPath).Handler(webhookReceiverHandler)
+		webhookReceiverHandler, err := newFleetWebhookReceiver(ocmclient)
+		if err != nil {
+			o.logger.WithError(err).Fatal("Can't initialise the connections with the OCM environments")
+			return err
+		}
+		r.Path(o.webhookPath).Handler(tracing.NewHandler(webhookReceiverHandler, o.webhookPath))
 		r.Use(metrics.PrometheusMiddleware)
 	} else {
-		internalID, err := ocm.GetInternalIDByExternalID(o.externalClusterID, sdkclient)
+		internalID, err := ocm.GetInternalIDByExternalID(o.externalClusterID, connections.Connection())
 		if err != nil {
 			o.logger.WithError(err).Fatal("OCM Agent failed to fetch internal cluster ID")
 			os.Exit(1)
 		}
 
+		// Cache the read-only proxy calls as in-cluster operators poll them frequently
+		proxyOCMClient := baseOCMClient
+		if o.cacheTTL > 0 {
+			o.logger.WithField("TTL", o.cacheTTL).Info("Caching OCM proxy responses")
+			proxyOCMClient = ocm.NewCachingOcmClient(baseOCMClient, o.cacheTTL, o.cacheMaxStale)
+		}
+		proxyOCMClient = traced(audited(proxyOCMClient))
+
+		// Only allow callers authorized for the virtual resources of the proxy routes to change them. The reads are
+		// authorized as well once enabled, which requires all the callers to send their ServiceAccount token
+		authorizer := handlers.NewProxyAuthorizer(client, o.proxyAuthzTTL)
+		if o.proxyAuthz {
+			o.logger.Info("Authorizing proxy requests with TokenReview and SubjectAccessReview")
+		} else {
+			o.logger.Warn("Proxy reads are not authorized, enable --proxy-authorization once the callers are granted access")
+			authorizer = authorizer.WithUnauthenticatedReads()
+		}
+		// The proxy routes are traced like the webhook ones, including the requests which aren't authorized
+		proxyRoute := func(path string, next http.HandlerFunc) {
+			r.Path(path).Handler(tracing.NewHandler(next, path))
+		}
+
+		// The request metrics middleware is added once, whichever receivers are served
+		requestMetrics := false
+		if o.fleetWebhookPath != "" {
+			// The fleet notifications about the hosted clusters are sent with a connection of their own, authenticated
+			// with client credentials rather than the pull secret of the management cluster
+			o.logger.Info("Initialising alertmanager webhook handler in fleet mode besides the classic one")
+			fleetConnections, err := o.newFleetSecretConnectionReloader(o.fleetSecretName)
+			if err != nil {
+				o.logger.WithError(err).Fatal("Can't initialise the fleet mode OCM sdk.Connection client")
+				return err
+			}
+			go fleetConnections.WithEnvironment(metrics.OCMEnvironmentFleet).Watch(context.Background())
+			go ocm.NewConnectionMonitor(fleetConnections.Connection, false).WithEnvironment(metrics.OCMEnvironmentFleet).Run(context.Background())
+			fleetOCMClient := traced(audited(ocm.NewInstrumentedOcmClient(ocm.NewReloadingOcmClient(fleetConnections), metrics.OCMEnvironmentFleet)))
+			fleetWebhookReceiverHandler, err := newFleetWebhookReceiver(fleetOCMClient)
+			if err != nil {
+				o.logger.WithError(err).Fatal("Can't initialise the connections with the OCM environments")
+				return err
+			}
+			r.Path(o.fleetWebhookPath).Handler(tracing.NewHandler(fleetWebhookReceiverHandler, o.fleetWebhookPath))
+			requestMetrics = true
+		}
+
 		for _, service := range o.services {
 			switch service {
 			case config.ServiceLogService:
 				// TODO: we might want to split this out of the service switch,
 				// see comment for fleet mode.
 				o.logger.Info("Initialising alertmanager webhook handler in NON-fleet mode")
-				webhookReceiverHandler := handlers.NewWebhookReceiverHandler(client, ocmclient)
-				r.Path(consts.WebhookReceiverPath).Handler(webhookReceiverHandler)
-				r.Use(metrics.PrometheusMiddleware)
+				webhookReceiverHandler := handlers.NewWebhookReceiverHandler(client, ocmclient, recorder).WithNamespaces(o.namespaces)
+				r.Path(o.webhookPath).Handler(tracing.NewHandler(webhookReceiverHandler, o.webhookPath))
+				requestMetrics = true
+				o.logger.Info("Initialising ServiceLog handlers")
+				serviceLogsHandler := handlers.NewServiceLogsHandler(ocmclient, o.externalClusterID)
+				proxyRoute("/service_logs", authorizer.AuthorizeCollection(handlers.ServiceLogsResource, serviceLogsHandler.ServeServiceLogList))
 			case config.ClustersService:
 				o.logger.Info("Initialising UpgradePolicy handlers")
-				upgradePolicyHandler := handlers.NewUpgradePoliciesHandler(ocmclient, internalID)
+				upgradePolicyHandler := handlers.NewUpgradePoliciesHandler(proxyOCMClient, internalID)
 				// See https://github.com/gorilla/mux#examples
-				r.HandleFunc("/upgrade_policies", upgradePolicyHandler.ServeUpgradePolicyList)
-				r.HandleFunc("/upgrade_policies/{upgrade_policy_id}", upgradePolicyHandler.ServeUpgradePolicyGet)
-				r.HandleFunc("/upgrade_policies/{upgrade_policy_id}/state", upgradePolicyHandler.ServeUpgradePolicyState)
+				proxyRoute("/upgrade_policies", authorizer.AuthorizeCollection(handlers.UpgradePoliciesResource, upgradePolicyHandler.ServeUpgradePolicyList))
+				proxyRoute("/upgrade_policies/{upgrade_policy_id}", authorizer.Authorize(handlers.UpgradePoliciesResource, "", upgradePolicyHandler.ServeUpgradePolicyGet))
+				proxyRoute("/upgrade_policies/{upgrade_policy_id}/state", authorizer.Authorize(handlers.UpgradePoliciesResource, handlers.UpgradePolicyStateSubresource, upgradePolicyHandler.ServeUpgradePolicyState))
 				o.logger.Info("Initialising Cluster handlers")
-				clusterHandler := handlers.NewClusterHandler(ocmclient, internalID)
-				r.HandleFunc("/", clusterHandler.ServeClusterGet)
+				clusterHandler := handlers.NewClusterHandler(proxyOCMClient, internalID)
+				proxyRoute("/", authorizer.Authorize(handlers.ClustersResource, "", clusterHandler.ServeClusterGet))
 			}
 		}
+		if requestMetrics {
+			r.Use(metrics.PrometheusMiddleware)
+		}
 	}
 
 	// serve
-	o.logger.WithField("Port", consts.OCMAgentServicePort).Info("Start listening on service port")
+	o.logger.WithField("Port", o.servicePort).Info("Start listening on service port")
 	// Adding ReadHeaderTimeout to fix below gosec error
 	// G114: Use of net/http serve function that has no support for setting timeouts
 	server := &http.Server{
-		Addr:              ":" + strconv.Itoa(consts.OCMAgentServicePort),
-		ReadHeaderTimeout: 3 * time.Second,
+		Addr:              ":" + strconv.Itoa(o.servicePort),
+		ReadHeaderTimeout: o.readHeaderTimeout,
 		Handler:           r,
 	}
 	err = server.ListenAndServe()
@@ -350,9 +586,221 @@ func (o *serveOptions) Run() error {
 	return nil
 }
 
+// newAuditSink returns the sink for the audit records of mutating OCM calls, or nil if auditing is disabled.
+func (o *serveOptions) newAuditSink(recorder record.EventRecorder) (ocm.AuditSink, error) {
+	var sinks ocm.AuditSinks
+
+	switch o.auditLog {
+	case "":
+	case "-":
+		sinks = append(sinks, ocm.NewJSONAuditSink(os.Stdout))
+	default:
+		auditFile, err := os.OpenFile(o.auditLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600) //#nosec G304 -- The audit log path is configured by the operator
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, ocm.NewJSONAuditSink(auditFile))
+	}
+
+	if o.auditEvents {
+		sinks = append(sinks, ocm.NewEventAuditSink(recorder))
+	}
+
+	if len(sinks) == 0 {
+		o.logger.Info("Audit log of mutating OCM calls disabled")
+		return nil, nil
+	}
+	o.logger.WithFields(logrus.Fields{"AuditLog": o.auditLog, "AuditEvents": o.auditEvents}).Info("Audit log of mutating OCM calls configured")
+	return sinks, nil
+}
+
 func deleteFirstElementIfFileName(slice []string) []string {
 	if len(slice) > 0 && strings.HasPrefix(slice[0], "@") {
 		slice = slice[1:]
 	}
 	return slice
 }
+
+// newConnectionReloader builds the connection to OCM, which is reloaded when the access token file, the pull secret
+// and ClusterVersion of the cluster, or the secret holding the OCM client credentials in fleet mode, change.
+func (o *serveOptions) newConnectionReloader(kubeClient client.Client) (*ocm.ConnectionReloader, error) {
+	if o.fromCluster {
+		return o.newClusterConnectionReloader(kubeClient)
+	}
+
+	// If fleet mode is not enabled, we will fetch the cluster ID and access token to initiate connection with OCM
+	if !o.fleetMode || (o.fleetMode && o.testMode) {
+		build := func() (*sdk.Connection, error) {
+			accessToken := viper.GetString(config.AccessToken)
+			if o.accessTokenFile != "" {
+				data, err := os.ReadFile(o.accessTokenFile)
+				if err != nil {
+					return nil, err
+				}
+				accessToken = strings.TrimSpace(string(data))
+			}
+			return ocm.NewConnection().TransportWrapper(ocm.MetricsTransportWrapper).RetryPolicy(o.retry).Transport(o.transport).Build(viper.GetString(config.OcmURL),
+				viper.GetString(config.ExternalClusterID),
+				accessToken)
+		}
+		if o.accessTokenFile == "" {
+			return ocm.NewConnectionReloader(build, o.transportFiles()...)
+		}
+		return ocm.NewConnectionReloader(build, append(o.transportFiles(), o.accessTokenFile)...)
+	}
+
+	// If fleet mode is enabled, the connection to OCM needs to initiate using client ID and client secret
+	// On the managed cluster, the client ID and secret will be fetched from the secret volume however for
+	// local testing, the client ID and secret can be passed directly as flags for ocm-agent CLI.
+	ocmAgentClientID := viper.GetString(config.OCMClientID)
+	ocmAgentClientSecret := viper.GetString(config.OCMClientSecret)
+	if ocmAgentClientID != "" || ocmAgentClientSecret != "" {
+		return ocm.NewConnectionReloader(func() (*sdk.Connection, error) {
+			return o.buildFleetConnection(ocmAgentClientID, ocmAgentClientSecret, viper.GetString(config.OcmURL))
+		}, o.transportFiles()...)
+	}
+
+	return o.newFleetSecretConnectionReloader(o.fleetSecretName)
+}
+
+// buildFleetConnection builds a connection to OCM authenticating with client credentials
+func (o *serveOptions) buildFleetConnection(clientID, clientSecret, url string) (*sdk.Connection, error) {
+	return o.transport.Apply(o.retry.Apply(sdk.NewConnectionBuilder().URL(url).Client(clientID, clientSecret).Insecure(false).
+		TransportWrapper(ocm.MetricsTransportWrapper))).Build()
+}
+
+// newFleetSecretConnectionReloader builds the connection to OCM with the client credentials and URL of the secret
+// mounted under consts.OCMAgentAccessFleetSecretPathBase, which is reloaded when they change.
+func (o *serveOptions) newFleetSecretConnectionReloader(secretName string) (*ocm.ConnectionReloader, error) {
+	secretPath := consts.OCMAgentAccessFleetSecretPathBase + secretName + "/"
+	clientIDFile := secretPath + consts.OCMAgentAccessFleetSecretClientKey
+	clientSecretFile := secretPath + consts.OCMAgentAccessFleetSecretClientSecretKey
+	urlFile := secretPath + consts.OCMAgentAccessFleetSecretURLKey
+	return ocm.NewConnectionReloader(func() (*sdk.Connection, error) {
+		var values []string
+		for _, file := range []string{clientIDFile, clientSecretFile, urlFile} {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("can't find value for secret key %s: %w", file, err)
+			}
+			values = append(values, string(data))
+		}
+		return o.buildFleetConnection(values[0], values[1], values[2])
+	}, append(o.transportFiles(), clientIDFile, clientSecretFile, urlFile)...)
+}
+
+// newEnvironmentClients builds the connections to the named OCM environments of fleet mode, and returns their
+// instrumented clients by name. Each connection is monitored and reloaded on its own.
+func (o *serveOptions) newEnvironmentClients() (map[string]ocm.OCMClient, error) {
+	environments, err := parseEnvironments(o.fleetEnvironments)
+	if err != nil {
+		return nil, err
+	}
+	clients := map[string]ocm.OCMClient{}
+	for name, secretName := range environments {
+		reloader, err := o.newFleetSecretConnectionReloader(secretName)
+		if err != nil {
+			return nil, fmt.Errorf("OCM environment '%s': %w", name, err)
+		}
+		o.logger.WithFields(logrus.Fields{"Environment": name, "Secret": secretName}).Info("Connection with OCM environment initialised successfully")
+		go reloader.WithEnvironment(name).Watch(context.Background())
+		go ocm.NewConnectionMonitor(reloader.Connection, false).WithEnvironment(name).Run(context.Background())
+		clients[name] = ocm.NewInstrumentedOcmClient(ocm.NewReloadingOcmClient(reloader), name)
+	}
+	return clients, nil
+}
+
+// parseEnvironments parses the name=secret pairs of the named OCM environments into the secret names by environment
+func parseEnvironments(values []string) (map[string]string, error) {
+	environments := map[string]string{}
+	for _, value := range values {
+		name, secretName, found := strings.Cut(value, "=")
+		if !found || secretName == "" {
+			return nil, fmt.Errorf("'%s' is not a name=secret pair", value)
+		}
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid environment name '%s': %s", name, strings.Join(errs, ", "))
+		}
+		if name == metrics.OCMEnvironmentFleet {
+			return nil, fmt.Errorf("environment name '%s' is reserved for the fleet mode receiver served besides the classic one", name)
+		}
+		if _, ok := environments[name]; ok {
+			return nil, fmt.Errorf("environment '%s' is defined more than once", name)
+		}
+		environments[name] = secretName
+	}
+	return environments, nil
+}
+
+// validatePaths checks that the configurable paths are absolute and don't shadow the other routes of their port.
+// The fleet webhook receiver path is empty when it isn't served.
+func validatePaths(webhookPath, fleetWebhookPath, metricsPath string) error {
+	serviceRoutes := []string{"/", consts.LivezPath, consts.ReadyzPath, "/service_logs", "/upgrade_policies"}
+	reserved := map[string][]string{
+		config.WebhookReceiverPath:      serviceRoutes,
+		config.FleetWebhookReceiverPath: append(slices.Clone(serviceRoutes), webhookPath),
+		config.MetricsPath:              {consts.LogLevelsPath},
+	}
+	paths := map[string]string{config.WebhookReceiverPath: webhookPath, config.MetricsPath: metricsPath}
+	if fleetWebhookPath != "" {
+		paths[config.FleetWebhookReceiverPath] = fleetWebhookPath
+	}
+	for name, path := range paths {
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("--%s must start with /", name)
+		}
+		if slices.Contains(reserved[name], path) {
+			return fmt.Errorf("--%s can't be %s, which is already routed", name, path)
+		}
+	}
+	return nil
+}
+
+// transportFiles returns the files the OCM connection is rebuilt on changes of, besides its credentials
+func (o *serveOptions) transportFiles() []string {
+	if o.transport.CABundleFile == "" {
+		return nil
+	}
+	return []string{o.transport.CABundleFile}
+}
+
+// newClusterConnectionReloader builds the connection to OCM with the pull secret and cluster ID of the cluster.
+// The cluster ID the notifications are sent for is the one read at startup: as the internal ID and the handlers are
+// resolved for it, the reload is refused and the current connection kept when the cluster ID changes.
+func (o *serveOptions) newClusterConnectionReloader(kubeClient client.Client) (*ocm.ConnectionReloader, error) {
+	credentials, err := k8s.GetClusterCredentials(context.Background(), kubeClient)
+	if err != nil {
+		return nil, err
+	}
+	o.externalClusterID = credentials.ClusterID
+	viper.Set(config.ExternalClusterID, credentials.ClusterID)
+	o.logger.WithField("ClusterID", credentials.ClusterID).Info("Read the pull secret and cluster ID from the cluster")
+
+	return ocm.NewCredentialsConnectionReloader(func() (*sdk.Connection, error) {
+		credentials, err := k8s.GetClusterCredentials(context.Background(), kubeClient)
+		if err != nil {
+			return nil, err
+		}
+		if credentials.ClusterID != o.externalClusterID {
+			return nil, fmt.Errorf("the cluster ID changed from %s to %s, restart the agent to send the notifications for it",
+				o.externalClusterID, credentials.ClusterID)
+		}
+		return ocm.NewConnection().TransportWrapper(ocm.MetricsTransportWrapper).RetryPolicy(o.retry).Transport(o.transport).Build(viper.GetString(config.OcmURL),
+			credentials.ClusterID, credentials.AccessToken)
+	}, func() ([]byte, error) {
+		credentials, err := k8s.GetClusterCredentials(context.Background(), kubeClient)
+		if err != nil {
+			return nil, err
+		}
+		fingerprint := []byte(credentials.ClusterID + ":" + credentials.AccessToken)
+		if o.transport.CABundleFile != "" {
+			// The connection is also rebuilt when the trusted CA bundle changes
+			bundle, err := os.ReadFile(o.transport.CABundleFile)
+			if err != nil {
+				return nil, err
+			}
+			fingerprint = append(fingerprint, bundle...)
+		}
+		return fingerprint, nil
+	})
+}
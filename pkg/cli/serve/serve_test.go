@@ -52,6 +52,13 @@ func TestServeCommandFlags(t *testing.T) {
 		{config.OCMClientSecret, "", "OCM Client Secret for testing fleet mode (string)"},
 		{config.Services, "", "OCM service name (string)"},
 		{config.FleetMode, "", "Fleet Mode (bool)"},
+		{config.FleetEnvironments, "", "Named OCM environments the fleet notifications can be sent to"},
+		{config.PullSecretFromCluster, "", "Read the access token from the openshift-config/pull-secret Secret"},
+		{config.ConfigFile, "", "Configuration file in YAML or JSON"},
+		{config.OCMProxy, "", "URL of the proxy the requests to OCM are sent through"},
+		{config.Namespaces, "", "Namespaces the notification custom resources are read from"},
+		{config.WebhookReceiverPath, "", "Path of the Alertmanager webhook receiver on the service port"},
+		{config.FleetWebhookReceiverPath, "", "Path of a fleet mode Alertmanager webhook receiver"},
 		{config.Debug, "d", "Debug mode enable"},
 	}
 
@@ -72,6 +79,20 @@ func TestServeCommandFlags(t *testing.T) {
 	}
 }
 
+// TestProxyAuthorizationDisabledByDefault tests that the reads of the proxy routes stay open to the existing callers
+// unless enabled
+func TestProxyAuthorizationDisabledByDefault(t *testing.T) {
+	cmd := serve.NewServeCmd()
+
+	flag := cmd.Flags().Lookup(config.ProxyAuthorization)
+	if flag == nil {
+		t.Fatalf("Flag %s should exist", config.ProxyAuthorization)
+	}
+	if flag.DefValue != "false" {
+		t.Errorf("Flag %s should default to false, got %s", config.ProxyAuthorization, flag.DefValue)
+	}
+}
+
 // TestServeCommandStructure tests the command structure and relationships
 func TestServeCommandStructure(t *testing.T) {
 	cmd := serve.NewServeCmd()
@@ -84,6 +84,56 @@ var _ = Describe("Utility functions", func() {
 			Expect(result).To(ConsistOf("service_log"))
 		})
 	})
+
+	Context("validatePaths function", func() {
+		It("should accept the default paths and other absolute paths", func() {
+			Expect(validatePaths("/alertmanager-receiver", "", "/metrics")).To(Succeed())
+			Expect(validatePaths("/staging/alertmanager-receiver", "/staging/fleet-alertmanager-receiver", "/staging/metrics")).To(Succeed())
+		})
+
+		It("should reject relative paths", func() {
+			Expect(validatePaths("alertmanager-receiver", "", "/metrics")).To(MatchError(ContainSubstring("--webhook-receiver-path must start with /")))
+		})
+
+		It("should reject the paths of the other routes", func() {
+			Expect(validatePaths("/readyz", "", "/metrics")).To(MatchError(ContainSubstring("already routed")))
+			Expect(validatePaths("/alertmanager-receiver", "/service_logs", "/metrics")).To(MatchError(ContainSubstring("already routed")))
+			Expect(validatePaths("/alertmanager-receiver", "", "/admin/log-levels")).To(MatchError(ContainSubstring("already routed")))
+		})
+
+		It("should reject a fleet webhook receiver path shared with the classic one", func() {
+			Expect(validatePaths("/alertmanager-receiver", "/alertmanager-receiver", "/metrics")).
+				To(MatchError(ContainSubstring("--fleet-webhook-receiver-path can't be /alertmanager-receiver")))
+			Expect(validatePaths("/alertmanager-receiver", "fleet", "/metrics")).
+				To(MatchError(ContainSubstring("--fleet-webhook-receiver-path must start with /")))
+		})
+	})
+
+	Context("parseEnvironments function", func() {
+		It("should return the secret names by environment", func() {
+			Expect(parseEnvironments([]string{"integration=ocm-agent-int", "stage=ocm-agent-stage"})).To(Equal(map[string]string{
+				"integration": "ocm-agent-int",
+				"stage":       "ocm-agent-stage",
+			}))
+			Expect(parseEnvironments(nil)).To(BeEmpty())
+		})
+
+		It("should reject values which are not name=secret pairs", func() {
+			for _, value := range []string{"stage", "stage=", "=ocm-agent-stage"} {
+				_, err := parseEnvironments([]string{value})
+				Expect(err).To(HaveOccurred(), value)
+			}
+		})
+
+		It("should reject invalid, duplicated and reserved names", func() {
+			_, err := parseEnvironments([]string{"Stage=ocm-agent-stage"})
+			Expect(err).To(MatchError(ContainSubstring("invalid environment name 'Stage'")))
+			_, err = parseEnvironments([]string{"stage=ocm-agent-stage", "stage=ocm-agent-stage-2"})
+			Expect(err).To(MatchError(ContainSubstring("defined more than once")))
+			_, err = parseEnvironments([]string{"fleet=ocm-agent-fleet"})
+			Expect(err).To(MatchError(ContainSubstring("reserved")))
+		})
+	})
 })
 
 // Test the function directly for better performance testing
@@ -9,6 +9,10 @@ const (
 	OcmURL string = "ocm-url"
 	// Debug represents whether debug behaviours will be enabled
 	Debug string = "debug"
+	// LogFormat represents the format of the log entries, text or json
+	LogFormat string = "log-format"
+	// LogLevel represents the level of the log entries
+	LogLevel string = "log-level"
 	// ExternalClusterID represents the ID of the cluster used for OCM notifications
 	ExternalClusterID string = "cluster-id"
 	// FleetMode represents if ocm-agent is going to run in default OSD/ROSA mode or HyperShift mode
@@ -19,6 +23,72 @@ const (
 	OCMClientID string = "ocm-client-id"
 	// OCMClientSecret represents the OCM Client ID that will be used for testing fleet-mode run
 	OCMClientSecret string = "ocm-client-secret" //#nosec G101 -- This is a false positive
+	// PullSecretFromCluster represents whether the access token and cluster ID are read from the cluster instead of flags
+	PullSecretFromCluster string = "pull-secret-from-cluster"
+	// CacheTTL represents how long responses of read-only OCM proxy calls are cached
+	CacheTTL string = "cache-ttl"
+	// CacheMaxStale represents how long cached responses may still be served while OCM is unavailable
+	CacheMaxStale string = "cache-max-stale"
+	// ProxyAuthorization represents whether the reads of the proxy routes are authorized with TokenReview and
+	// SubjectAccessReview, as their changes always are
+	ProxyAuthorization string = "proxy-authorization"
+	// ProxyAuthorizationCacheTTL represents how long authorization decisions for the proxy routes are cached
+	ProxyAuthorizationCacheTTL string = "proxy-authorization-cache-ttl"
+	// AuditLog represents the file the audit records of mutating OCM calls are written to
+	AuditLog string = "audit-log"
+	// AuditEvents represents whether Events are recorded for mutating OCM calls
+	AuditEvents string = "audit-events"
+	// TracingExporter represents the exporter the OpenTelemetry spans are sent with
+	TracingExporter string = "tracing-exporter"
+	// TracingEndpoint represents the host:port of the OTLP collector the spans are sent to
+	TracingEndpoint string = "tracing-endpoint"
+	// TracingInsecure represents whether the spans are sent to the OTLP collector without TLS
+	TracingInsecure string = "tracing-insecure"
+	// TracingSampleRatio represents the ratio of the traces started by the agent which are sampled
+	TracingSampleRatio string = "tracing-sample-ratio"
+	// FleetClusterMetrics represents whether the fleet mode metrics are labeled by hosted cluster
+	FleetClusterMetrics string = "fleet-cluster-metrics"
+	// FleetClusterMetricsMax represents the number of hosted clusters the per-cluster metrics are kept for
+	FleetClusterMetricsMax string = "fleet-cluster-metrics-max"
+	// FleetClusterMetricsTTL represents how long the per-cluster metrics of a hosted cluster are kept without update
+	FleetClusterMetricsTTL string = "fleet-cluster-metrics-ttl"
+	// ConfigFile represents the path of the configuration file the flags not passed on the command line are read from
+	ConfigFile string = "config"
+	// FleetSecretName represents the name of the mounted secret holding the OCM client credentials in fleet mode
+	FleetSecretName string = "fleet-secret-name" //#nosec G101 -- This is a false positive
+	// FleetEnvironments represents the named OCM environments fleet notifications can be sent to, with the secrets of their credentials
+	FleetEnvironments string = "fleet-environments"
+	// ServicePort represents the port the OCM Agent web service listens on
+	ServicePort string = "service-port"
+	// MetricsPort represents the port the OCM Agent metrics listen on
+	MetricsPort string = "metrics-port"
+	// ReadHeaderTimeout represents how long the listeners wait for the headers of a request
+	ReadHeaderTimeout string = "read-header-timeout"
+	// OCMRetryLimit represents how many times a failed OCM call is retried
+	OCMRetryLimit string = "ocm-retry-limit"
+	// OCMRetryInterval represents the time before the first retry of a failed OCM call, doubled on every retry
+	OCMRetryInterval string = "ocm-retry-interval"
+	// OCMRetryJitter represents the random variation applied to the interval between retries of OCM calls
+	OCMRetryJitter string = "ocm-retry-jitter"
+	// OCMProxy represents the proxy the requests to OCM are sent through
+	OCMProxy string = "ocm-proxy"
+	// OCMNoProxy represents the hosts, domains and CIDRs reached without the proxy
+	OCMNoProxy string = "ocm-no-proxy"
+	// OCMCABundle represents the PEM file of the CAs trusted for OCM in addition to the system ones
+	OCMCABundle string = "ocm-ca-bundle"
+	// Namespaces represents the namespaces the notification custom resources are read from
+	Namespaces string = "namespaces"
+	// WebhookReceiverPath represents the path of the Alertmanager webhook receiver on the service port
+	WebhookReceiverPath string = "webhook-receiver-path"
+	// FleetWebhookReceiverPath represents the path of the fleet mode webhook receiver served besides the classic one
+	FleetWebhookReceiverPath string = "fleet-webhook-receiver-path"
+	// MetricsPath represents the path of the metrics on the metrics port
+	MetricsPath string = "metrics-path"
+
+	// ConfigFileEnv is the environment variable the configuration file path is read from when --config is not passed
+	ConfigFileEnv string = "OCM_AGENT_CONFIG"
+	// FleetSecretNameEnv is the environment variable the fleet secret name is read from when --fleet-secret-name is not passed
+	FleetSecretNameEnv string = "OCM_AGENT_SECRET_NAME" //#nosec G101 -- This is a false positive
 
 	ServiceLogService string = "service_logs" //#nosec G101 -- This is a false positive
 
@@ -0,0 +1,369 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// FileAPIVersion is the version of the configuration file schema
+	FileAPIVersion = "ocmagent.managed.openshift.io/v1alpha1"
+	// FileKind is the kind of the configuration file
+	FileKind = "OCMAgentConfig"
+	// RedactedValue replaces the secrets of the printed configuration
+	RedactedValue = "<redacted>"
+)
+
+// File is the configuration file of the serve command, which can set every flag.
+// String values starting with '@' are read from a file, as for the flags.
+type File struct {
+	APIVersion  string            `json:"apiVersion"`
+	Kind        string            `json:"kind"`
+	Listeners   ListenersConfig   `json:"listeners,omitempty"`
+	OCM         OCMConfig         `json:"ocm,omitempty"`
+	Mode        ModeConfig        `json:"mode,omitempty"`
+	Kubernetes  KubernetesConfig  `json:"kubernetes,omitempty"`
+	Credentials CredentialsConfig `json:"credentials,omitempty"`
+	Timeouts    TimeoutsConfig    `json:"timeouts,omitempty"`
+	Retry       RetryConfig       `json:"retry,omitempty"`
+	Proxy       ProxyConfig       `json:"proxy,omitempty"`
+	Features    FeaturesConfig    `json:"features,omitempty"`
+	Tracing     TracingConfig     `json:"tracing,omitempty"`
+	Logging     LoggingConfig     `json:"logging,omitempty"`
+}
+
+// ListenersConfig configures the ports and paths the agent listens on
+type ListenersConfig struct {
+	ServicePort              *int    `json:"servicePort,omitempty"`
+	MetricsPort              *int    `json:"metricsPort,omitempty"`
+	WebhookReceiverPath      *string `json:"webhookReceiverPath,omitempty"`
+	FleetWebhookReceiverPath *string `json:"fleetWebhookReceiverPath,omitempty"`
+	MetricsPath              *string `json:"metricsPath,omitempty"`
+}
+
+// OCMConfig configures the OCM environment and the services the agent talks to
+type OCMConfig struct {
+	URL      *string  `json:"url,omitempty"`
+	Services []string `json:"services,omitempty"`
+}
+
+// ModeConfig configures whether the agent runs in classic or fleet mode
+type ModeConfig struct {
+	Fleet *bool `json:"fleet,omitempty"`
+	Test  *bool `json:"test,omitempty"`
+}
+
+// KubernetesConfig configures where the notification custom resources are read from
+type KubernetesConfig struct {
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// CredentialsConfig configures where the credentials of the OCM connection are read from
+type CredentialsConfig struct {
+	AccessToken       *string  `json:"accessToken,omitempty"`
+	ClusterID         *string  `json:"clusterID,omitempty"`
+	FromCluster       *bool    `json:"fromCluster,omitempty"`
+	ClientID          *string  `json:"clientID,omitempty"`
+	ClientSecret      *string  `json:"clientSecret,omitempty"`
+	FleetSecretName   *string  `json:"fleetSecretName,omitempty"`
+	FleetEnvironments []string `json:"fleetEnvironments,omitempty"`
+}
+
+// TimeoutsConfig configures the timeouts of the listeners and how long responses and decisions are cached
+type TimeoutsConfig struct {
+	ReadHeader            *metav1.Duration `json:"readHeader,omitempty"`
+	CacheTTL              *metav1.Duration `json:"cacheTTL,omitempty"`
+	CacheMaxStale         *metav1.Duration `json:"cacheMaxStale,omitempty"`
+	AuthorizationCacheTTL *metav1.Duration `json:"authorizationCacheTTL,omitempty"`
+}
+
+// RetryConfig configures how failed OCM calls are retried
+type RetryConfig struct {
+	Limit    *int             `json:"limit,omitempty"`
+	Interval *metav1.Duration `json:"interval,omitempty"`
+	Jitter   *float64         `json:"jitter,omitempty"`
+}
+
+// ProxyConfig configures the egress proxy and the additional trusted CAs of the requests to OCM
+type ProxyConfig struct {
+	URL      *string `json:"url,omitempty"`
+	NoProxy  *string `json:"noProxy,omitempty"`
+	CABundle *string `json:"caBundle,omitempty"`
+}
+
+// FeaturesConfig toggles the optional features of the agent
+type FeaturesConfig struct {
+	ProxyAuthorization  *bool                     `json:"proxyAuthorization,omitempty"`
+	AuditLog            *string                   `json:"auditLog,omitempty"`
+	AuditEvents         *bool                     `json:"auditEvents,omitempty"`
+	FleetClusterMetrics FleetClusterMetricsConfig `json:"fleetClusterMetrics,omitempty"`
+}
+
+// FleetClusterMetricsConfig configures the per-hosted-cluster metrics of fleet mode
+type FleetClusterMetricsConfig struct {
+	Enabled     *bool            `json:"enabled,omitempty"`
+	MaxClusters *int             `json:"maxClusters,omitempty"`
+	TTL         *metav1.Duration `json:"ttl,omitempty"`
+}
+
+// TracingConfig configures the export of the OpenTelemetry spans
+type TracingConfig struct {
+	Exporter    *string  `json:"exporter,omitempty"`
+	Endpoint    *string  `json:"endpoint,omitempty"`
+	Insecure    *bool    `json:"insecure,omitempty"`
+	SampleRatio *float64 `json:"sampleRatio,omitempty"`
+}
+
+// LoggingConfig configures the log entries
+type LoggingConfig struct {
+	Format *string `json:"format,omitempty"`
+	Level  *string `json:"level,omitempty"`
+	Debug  *bool   `json:"debug,omitempty"`
+}
+
+// Setting is a flag value set by the configuration file
+type Setting struct {
+	// Key is the path of the value in the file, e.g. ocm.url
+	Key string
+	// Flag is the name of the flag the value is set to
+	Flag  string
+	Value string
+}
+
+// binding ties a value of the configuration file to its flag
+type binding struct {
+	key  string
+	flag string
+	// redact hides the secrets of the value when the configuration is printed
+	redact func(value string) string
+	// field returns a pointer to the field of the value, e.g. **string or *[]string
+	field func(f *File) any
+}
+
+var bindings = []binding{
+	{key: "listeners.servicePort", flag: ServicePort, field: func(f *File) any { return &f.Listeners.ServicePort }},
+	{key: "listeners.metricsPort", flag: MetricsPort, field: func(f *File) any { return &f.Listeners.MetricsPort }},
+	{key: "listeners.webhookReceiverPath", flag: WebhookReceiverPath, field: func(f *File) any { return &f.Listeners.WebhookReceiverPath }},
+	{key: "listeners.fleetWebhookReceiverPath", flag: FleetWebhookReceiverPath, field: func(f *File) any { return &f.Listeners.FleetWebhookReceiverPath }},
+	{key: "listeners.metricsPath", flag: MetricsPath, field: func(f *File) any { return &f.Listeners.MetricsPath }},
+	{key: "ocm.url", flag: OcmURL, field: func(f *File) any { return &f.OCM.URL }},
+	{key: "ocm.services", flag: Services, field: func(f *File) any { return &f.OCM.Services }},
+	{key: "mode.fleet", flag: FleetMode, field: func(f *File) any { return &f.Mode.Fleet }},
+	{key: "mode.test", flag: TestMode, field: func(f *File) any { return &f.Mode.Test }},
+	{key: "kubernetes.namespaces", flag: Namespaces, field: func(f *File) any { return &f.Kubernetes.Namespaces }},
+	{key: "credentials.accessToken", flag: AccessToken, redact: redactSecret, field: func(f *File) any { return &f.Credentials.AccessToken }},
+	{key: "credentials.clusterID", flag: ExternalClusterID, field: func(f *File) any { return &f.Credentials.ClusterID }},
+	{key: "credentials.fromCluster", flag: PullSecretFromCluster, field: func(f *File) any { return &f.Credentials.FromCluster }},
+	{key: "credentials.clientID", flag: OCMClientID, field: func(f *File) any { return &f.Credentials.ClientID }},
+	{key: "credentials.clientSecret", flag: OCMClientSecret, redact: redactSecret, field: func(f *File) any { return &f.Credentials.ClientSecret }},
+	{key: "credentials.fleetSecretName", flag: FleetSecretName, field: func(f *File) any { return &f.Credentials.FleetSecretName }},
+	{key: "credentials.fleetEnvironments", flag: FleetEnvironments, field: func(f *File) any { return &f.Credentials.FleetEnvironments }},
+	{key: "timeouts.readHeader", flag: ReadHeaderTimeout, field: func(f *File) any { return &f.Timeouts.ReadHeader }},
+	{key: "timeouts.cacheTTL", flag: CacheTTL, field: func(f *File) any { return &f.Timeouts.CacheTTL }},
+	{key: "timeouts.cacheMaxStale", flag: CacheMaxStale, field: func(f *File) any { return &f.Timeouts.CacheMaxStale }},
+	{key: "timeouts.authorizationCacheTTL", flag: ProxyAuthorizationCacheTTL, field: func(f *File) any { return &f.Timeouts.AuthorizationCacheTTL }},
+	{key: "retry.limit", flag: OCMRetryLimit, field: func(f *File) any { return &f.Retry.Limit }},
+	{key: "retry.interval", flag: OCMRetryInterval, field: func(f *File) any { return &f.Retry.Interval }},
+	{key: "retry.jitter", flag: OCMRetryJitter, field: func(f *File) any { return &f.Retry.Jitter }},
+	{key: "proxy.url", flag: OCMProxy, redact: redactURL, field: func(f *File) any { return &f.Proxy.URL }},
+	{key: "proxy.noProxy", flag: OCMNoProxy, field: func(f *File) any { return &f.Proxy.NoProxy }},
+	{key: "proxy.caBundle", flag: OCMCABundle, field: func(f *File) any { return &f.Proxy.CABundle }},
+	{key: "features.proxyAuthorization", flag: ProxyAuthorization, field: func(f *File) any { return &f.Features.ProxyAuthorization }},
+	{key: "features.auditLog", flag: AuditLog, field: func(f *File) any { return &f.Features.AuditLog }},
+	{key: "features.auditEvents", flag: AuditEvents, field: func(f *File) any { return &f.Features.AuditEvents }},
+	{key: "features.fleetClusterMetrics.enabled", flag: FleetClusterMetrics, field: func(f *File) any { return &f.Features.FleetClusterMetrics.Enabled }},
+	{key: "features.fleetClusterMetrics.maxClusters", flag: FleetClusterMetricsMax, field: func(f *File) any { return &f.Features.FleetClusterMetrics.MaxClusters }},
+	{key: "features.fleetClusterMetrics.ttl", flag: FleetClusterMetricsTTL, field: func(f *File) any { return &f.Features.FleetClusterMetrics.TTL }},
+	{key: "tracing.exporter", flag: TracingExporter, field: func(f *File) any { return &f.Tracing.Exporter }},
+	{key: "tracing.endpoint", flag: TracingEndpoint, field: func(f *File) any { return &f.Tracing.Endpoint }},
+	{key: "tracing.insecure", flag: TracingInsecure, field: func(f *File) any { return &f.Tracing.Insecure }},
+	{key: "tracing.sampleRatio", flag: TracingSampleRatio, field: func(f *File) any { return &f.Tracing.SampleRatio }},
+	{key: "logging.format", flag: LogFormat, field: func(f *File) any { return &f.Logging.Format }},
+	{key: "logging.level", flag: LogLevel, field: func(f *File) any { return &f.Logging.Level }},
+	{key: "logging.debug", flag: Debug, field: func(f *File) any { return &f.Logging.Debug }},
+}
+
+// ReadFile reads and validates the YAML or JSON configuration file at path.
+// Unknown fields are rejected, so that misspelled settings don't go unnoticed.
+func ReadFile(path string) (*File, error) {
+	data, err := os.ReadFile(path) //#nosec G304 -- The configuration file path is configured by the operator
+	if err != nil {
+		return nil, fmt.Errorf("can't read configuration file '%s': %w", path, err)
+	}
+	f, err := ParseFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration file '%s': %w", path, err)
+	}
+	return f, nil
+}
+
+// ParseFile parses and validates a YAML or JSON configuration file.
+func ParseFile(data []byte) (*File, error) {
+	f := &File{}
+	if err := yaml.UnmarshalStrict(data, f); err != nil {
+		return nil, err
+	}
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Validate checks the version of the file and the values which are valid for their type but not for the agent.
+func (f *File) Validate() error {
+	if f.APIVersion != FileAPIVersion || f.Kind != FileKind {
+		return fmt.Errorf("unsupported apiVersion '%s' and kind '%s', expected '%s' and '%s'", f.APIVersion, f.Kind, FileAPIVersion, FileKind)
+	}
+	for _, service := range f.OCM.Services {
+		if service != ServiceLogService && service != ClustersService && !strings.HasPrefix(service, "@") {
+			return fmt.Errorf("ocm.services: unknown service '%s', expected %s or %s", service, ServiceLogService, ClustersService)
+		}
+	}
+	for key, port := range map[string]*int{"listeners.servicePort": f.Listeners.ServicePort, "listeners.metricsPort": f.Listeners.MetricsPort} {
+		if port != nil && (*port < 1 || *port > 65535) {
+			return fmt.Errorf("%s: port %d out of range", key, *port)
+		}
+	}
+	for key, path := range map[string]*string{"listeners.webhookReceiverPath": f.Listeners.WebhookReceiverPath, "listeners.metricsPath": f.Listeners.MetricsPath} {
+		if path != nil && !strings.HasPrefix(*path, "/") {
+			return fmt.Errorf("%s: path '%s' doesn't start with /", key, *path)
+		}
+	}
+	// The fleet webhook receiver is disabled with an empty path
+	if path := f.Listeners.FleetWebhookReceiverPath; path != nil && *path != "" && !strings.HasPrefix(*path, "/") {
+		return fmt.Errorf("listeners.fleetWebhookReceiverPath: path '%s' doesn't start with /", *path)
+	}
+	for _, namespace := range f.Kubernetes.Namespaces {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return fmt.Errorf("kubernetes.namespaces: invalid namespace '%s': %s", namespace, strings.Join(errs, ", "))
+		}
+	}
+	for _, b := range bindings {
+		if d, ok := b.field(f).(**metav1.Duration); ok && *d != nil && (*d).Duration < 0 {
+			return fmt.Errorf("%s: negative duration %s", b.key, (*d).Duration)
+		}
+	}
+	if f.Retry.Limit != nil && *f.Retry.Limit < 0 {
+		return fmt.Errorf("retry.limit: negative limit %d", *f.Retry.Limit)
+	}
+	for key, ratio := range map[string]*float64{"retry.jitter": f.Retry.Jitter, "tracing.sampleRatio": f.Tracing.SampleRatio} {
+		if ratio != nil && (*ratio < 0 || *ratio > 1) {
+			return fmt.Errorf("%s: %v is not between 0 and 1", key, *ratio)
+		}
+	}
+	return nil
+}
+
+// Settings returns the flag values set by the file, in the order of the file schema.
+func (f *File) Settings() []Setting {
+	var settings []Setting
+	for _, b := range bindings {
+		var value string
+		switch v := b.field(f).(type) {
+		case **string:
+			if *v == nil {
+				continue
+			}
+			value = **v
+		case **bool:
+			if *v == nil {
+				continue
+			}
+			value = strconv.FormatBool(**v)
+		case **int:
+			if *v == nil {
+				continue
+			}
+			value = strconv.Itoa(**v)
+		case **float64:
+			if *v == nil {
+				continue
+			}
+			value = strconv.FormatFloat(**v, 'g', -1, 64)
+		case **metav1.Duration:
+			if *v == nil {
+				continue
+			}
+			value = (*v).Duration.String()
+		case *[]string:
+			if *v == nil {
+				continue
+			}
+			value = strings.Join(*v, ",")
+		}
+		settings = append(settings, Setting{Key: b.key, Flag: b.flag, Value: value})
+	}
+	return settings
+}
+
+// FileFromFlags returns the configuration file equivalent to the values of flags.
+func FileFromFlags(flags *pflag.FlagSet) (*File, error) {
+	f := &File{APIVersion: FileAPIVersion, Kind: FileKind}
+	for _, b := range bindings {
+		flag := flags.Lookup(b.flag)
+		if flag == nil {
+			continue
+		}
+		var err error
+		switch v := b.field(f).(type) {
+		case **string:
+			value := flag.Value.String()
+			*v = &value
+		case **bool:
+			var value bool
+			value, err = strconv.ParseBool(flag.Value.String())
+			*v = &value
+		case **int:
+			var value int
+			value, err = strconv.Atoi(flag.Value.String())
+			*v = &value
+		case **float64:
+			var value float64
+			value, err = strconv.ParseFloat(flag.Value.String(), 64)
+			*v = &value
+		case **metav1.Duration:
+			var value time.Duration
+			value, err = time.ParseDuration(flag.Value.String())
+			*v = &metav1.Duration{Duration: value}
+		case *[]string:
+			*v, err = flags.GetStringSlice(b.flag)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("can't read the value of flag '%s': %w", b.flag, err)
+		}
+	}
+	return f, nil
+}
+
+// Redact replaces the secrets set directly in the file, leaving those read from a file ('@path') visible.
+func (f *File) Redact() {
+	for _, b := range bindings {
+		if v, ok := b.field(f).(**string); ok && b.redact != nil && *v != nil && **v != "" && !strings.HasPrefix(**v, "@") {
+			redacted := b.redact(**v)
+			*v = &redacted
+		}
+	}
+}
+
+// redactSecret hides the whole value
+func redactSecret(string) string {
+	return RedactedValue
+}
+
+// redactURL hides the password of a URL, such as the credentials of a proxy
+func redactURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil {
+		return RedactedValue
+	}
+	return u.Redacted()
+}
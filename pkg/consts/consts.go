@@ -1,18 +1,20 @@
 package consts
 
 const (
-	// Listening port for the OCM Agent web service
+	// Default listening port for the OCM Agent web service
 	OCMAgentServicePort = 8081
-	// Listening port for the OCM Agent metrics
+	// Default listening port for the OCM Agent metrics
 	OCMAgentMetricsPort = 8383
 
-	// Metrics path for OCM Agent service
+	// Default metrics path for OCM Agent service
 	MetricsPath = "/metrics"
 	// Ready probe path for OCM Agent web service
 	ReadyzPath = "/readyz"
 	// Live probe path for OCM Agent web service
 	LivezPath = "/livez"
-	// Alertmanger webhook receiver path
+	// Admin path on the metrics port to read and change the log levels at runtime
+	LogLevelsPath = "/admin/log-levels"
+	// Default Alertmanger webhook receiver path
 	WebhookReceiverPath = "/alertmanager-receiver"
 
 	// OCMAgentAccessFleetSecretPathBase is the base path where to find the secret
@@ -30,6 +32,10 @@ const (
 	// The URI parameter that represents the upgrade policy ID in OCM
 	UpgradePolicyIdParam = "upgrade_policy_id"
 
+	// API group of the virtual resources the proxy routes are authorized against,
+	// e.g. upgradepolicies.ocmagent.managed.openshift.io
+	ProxyAuthorizationGroup = "ocmagent.managed.openshift.io"
+
 	// The first page of a paginated 'list' request to OCM
 	OCMListRequestStartPage = 1
 
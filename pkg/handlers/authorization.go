@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/ocm-agent/pkg/consts"
+)
+
+const (
+	// Virtual resources the proxy routes are authorized against with a SubjectAccessReview
+	ClustersResource        = "clusters"
+	UpgradePoliciesResource = "upgradepolicies"
+	ServiceLogsResource     = "servicelogs"
+	// Virtual resource the admin log levels route is authorized against
+	LogLevelsResource = "loglevels"
+
+	// Subresource of the upgrade policies for the upgrade policy state
+	UpgradePolicyStateSubresource = "state"
+)
+
+type callerContextKey struct{}
+
+// CallerFromContext returns the username of the authenticated caller of a proxy request,
+// or an empty string if the request wasn't authenticated.
+func CallerFromContext(ctx context.Context) string {
+	caller, _ := ctx.Value(callerContextKey{}).(string)
+	return caller
+}
+
+// requestVerbs maps the HTTP methods of the proxy routes to the Kubernetes verbs used for authorization
+var requestVerbs = map[string]string{
+	http.MethodGet:    "get",
+	http.MethodPost:   "create",
+	http.MethodPatch:  "patch",
+	http.MethodDelete: "delete",
+}
+
+// collectionVerbs maps the HTTP methods of the proxy routes of collections, such as /upgrade_policies, to the
+// Kubernetes verbs used for authorization, listing them rather than getting one
+var collectionVerbs = map[string]string{
+	http.MethodGet:  "list",
+	http.MethodPost: "create",
+}
+
+// readVerbs are the verbs which don't change the resources
+var readVerbs = map[string]bool{
+	"get":  true,
+	"list": true,
+}
+
+type authorizationDecision struct {
+	caller  string
+	allowed bool
+	expires time.Time
+}
+
+// ProxyAuthorizer authenticates the callers of the proxy routes with a TokenReview of their ServiceAccount
+// bearer token and authorizes them with a SubjectAccessReview against a virtual resource in the
+// ocmagent.managed.openshift.io group. Decisions are cached for a short time as in-cluster operators
+// call the proxy routes frequently.
+type ProxyAuthorizer struct {
+	c        client.Client
+	cacheTTL time.Duration
+	now      func() time.Time
+	// unauthenticatedReads lets the get and list requests through without authorizing them
+	unauthenticatedReads bool
+
+	mutex     sync.Mutex
+	decisions map[string]authorizationDecision
+}
+
+// NewProxyAuthorizer returns a ProxyAuthorizer using the given client for the token and access reviews.
+func NewProxyAuthorizer(c client.Client, cacheTTL time.Duration) *ProxyAuthorizer {
+	packageLogger.Debug("Creating new proxy authorizer")
+	return &ProxyAuthorizer{
+		c:         c,
+		cacheTTL:  cacheTTL,
+		now:       time.Now,
+		decisions: make(map[string]authorizationDecision),
+	}
+}
+
+// WithUnauthenticatedReads lets the get and list requests through without authorizing them, while the requests
+// changing the resources are still authorized.
+func (a *ProxyAuthorizer) WithUnauthenticatedReads() *ProxyAuthorizer {
+	a.unauthenticatedReads = true
+	return a
+}
+
+// Authorize wraps a proxy handler so that it is only called for callers allowed to perform the request
+// on the given resource and subresource.
+func (a *ProxyAuthorizer) Authorize(resource, subresource string, next http.HandlerFunc) http.HandlerFunc {
+	return a.authorize(requestVerbs, resource, subresource, next)
+}
+
+// AuthorizeCollection wraps the proxy handler of a collection of resources so that it is only called for callers
+// allowed to list them, or to create one of them.
+func (a *ProxyAuthorizer) AuthorizeCollection(resource string, next http.HandlerFunc) http.HandlerFunc {
+	return a.authorize(collectionVerbs, resource, "", next)
+}
+
+func (a *ProxyAuthorizer) authorize(verbs map[string]string, resource, subresource string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		verb, found := verbs[r.Method]
+		if !found {
+			invalidRequestVerbResponse(r.Method, w)
+			return
+		}
+		if a.unauthenticatedReads && readVerbs[verb] {
+			next(w, r)
+			return
+		}
+
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || token == "" {
+			authorizationErrorResponse(w, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+
+		decision, err := a.review(r.Context(), token, verb, resource, subresource)
+		if err != nil {
+			packageLogger.FromContext(r.Context()).WithError(err).Error("Failed to review proxy request")
+			authorizationErrorResponse(w, http.StatusInternalServerError, "unable to review request")
+			return
+		}
+		if decision.caller == "" {
+			authorizationErrorResponse(w, http.StatusUnauthorized, "invalid bearer token")
+			return
+		}
+
+		logger := packageLogger.FromContext(r.Context()).WithFields(log.Fields{"caller": decision.caller, "verb": verb, "resource": resource, "subresource": subresource})
+		if !decision.allowed {
+			logger.Warn("Denied proxy request")
+			authorizationErrorResponse(w, http.StatusForbidden, fmt.Sprintf("%s is not allowed to %s %s", decision.caller, verb, resourceName(resource, subresource)))
+			return
+		}
+
+		logger.Debug("Authorized proxy request")
+		next(w, r.WithContext(context.WithValue(r.Context(), callerContextKey{}, decision.caller)))
+	}
+}
+
+// review returns the cached decision for the token and request attributes, or reviews them with the API server.
+// A decision without a caller means the token couldn't be authenticated.
+func (a *ProxyAuthorizer) review(ctx context.Context, token, verb, resource, subresource string) (authorizationDecision, error) {
+	sum := sha256.Sum256([]byte(token))
+	key := strings.Join([]string{hex.EncodeToString(sum[:]), verb, resource, subresource}, "/")
+
+	a.mutex.Lock()
+	decision, found := a.decisions[key]
+	a.mutex.Unlock()
+	if found && a.now().Before(decision.expires) {
+		return decision, nil
+	}
+
+	tokenReview := &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}
+	if err := a.c.Create(ctx, tokenReview); err != nil {
+		return authorizationDecision{}, fmt.Errorf("token review failed: %w", err)
+	}
+
+	decision = authorizationDecision{expires: a.now().Add(a.cacheTTL)}
+	if tokenReview.Status.Authenticated {
+		user := tokenReview.Status.User
+		extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+		for k, v := range user.Extra {
+			extra[k] = authorizationv1.ExtraValue(v)
+		}
+
+		accessReview := &authorizationv1.SubjectAccessReview{
+			Spec: authorizationv1.SubjectAccessReviewSpec{
+				User:   user.Username,
+				UID:    user.UID,
+				Groups: user.Groups,
+				Extra:  extra,
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Group:       consts.ProxyAuthorizationGroup,
+					Resource:    resource,
+					Subresource: subresource,
+					Verb:        verb,
+				},
+			},
+		}
+		if err := a.c.Create(ctx, accessReview); err != nil {
+			return authorizationDecision{}, fmt.Errorf("subject access review failed: %w", err)
+		}
+
+		decision.caller = user.Username
+		decision.allowed = accessReview.Status.Allowed
+	}
+
+	a.mutex.Lock()
+	for k, d := range a.decisions {
+		if !a.now().Before(d.expires) {
+			delete(a.decisions, k)
+		}
+	}
+	a.decisions[key] = decision
+	a.mutex.Unlock()
+
+	return decision, nil
+}
+
+func resourceName(resource, subresource string) string {
+	name := resource + "." + consts.ProxyAuthorizationGroup
+	if subresource != "" {
+		name += "/" + subresource
+	}
+	return name
+}
+
+func authorizationErrorResponse(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "text/plain")
+	http.Error(w, message, status)
+}
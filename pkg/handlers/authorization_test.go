@@ -0,0 +1,224 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/ocm-agent/pkg/consts"
+	"github.com/openshift/ocm-agent/pkg/handlers"
+	clientmocks "github.com/openshift/ocm-agent/pkg/util/test/generated/mocks/client"
+)
+
+var _ = Describe("Proxy authorization", func() {
+	const muoServiceAccount = "system:serviceaccount:openshift-managed-upgrade-operator:managed-upgrade-operator"
+
+	var (
+		mockCtrl     *gomock.Controller
+		mockClient   *clientmocks.MockClient
+		authorizer   *handlers.ProxyAuthorizer
+		recorder     *httptest.ResponseRecorder
+		called       bool
+		calledCaller string
+		next         http.HandlerFunc
+		// resourceAttributes are the attributes the SubjectAccessReview is expected for
+		resourceAttributes authorizationv1.ResourceAttributes
+	)
+
+	// expectReviews makes the API server authenticate the token as username (if set) and answer
+	// the SubjectAccessReview with allowed.
+	expectReviews := func(username string, allowed bool) {
+		mockClient.EXPECT().Create(gomock.Any(), gomock.AssignableToTypeOf(&authenticationv1.TokenReview{})).DoAndReturn(
+			func(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+				review := obj.(*authenticationv1.TokenReview)
+				Expect(review.Spec.Token).To(Equal("sa-token"))
+				review.Status.Authenticated = username != ""
+				review.Status.User = authenticationv1.UserInfo{Username: username, Groups: []string{"system:serviceaccounts"}}
+				return nil
+			},
+		)
+		if username == "" {
+			return
+		}
+		mockClient.EXPECT().Create(gomock.Any(), gomock.AssignableToTypeOf(&authorizationv1.SubjectAccessReview{})).DoAndReturn(
+			func(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+				review := obj.(*authorizationv1.SubjectAccessReview)
+				Expect(review.Spec.User).To(Equal(username))
+				Expect(review.Spec.Groups).To(ConsistOf("system:serviceaccounts"))
+				Expect(*review.Spec.ResourceAttributes).To(Equal(resourceAttributes))
+				review.Status.Allowed = allowed
+				return nil
+			},
+		)
+	}
+
+	newRequest := func(token string) *http.Request {
+		req := httptest.NewRequest("PATCH", "/upgrade_policies/upgrade-policy-id/state", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return req
+	}
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = clientmocks.NewMockClient(mockCtrl)
+		authorizer = handlers.NewProxyAuthorizer(mockClient, time.Minute)
+		recorder = httptest.NewRecorder()
+		resourceAttributes = authorizationv1.ResourceAttributes{
+			Group:       consts.ProxyAuthorizationGroup,
+			Resource:    handlers.UpgradePoliciesResource,
+			Subresource: handlers.UpgradePolicyStateSubresource,
+			Verb:        "patch",
+		}
+		called = false
+		calledCaller = ""
+		next = func(w http.ResponseWriter, r *http.Request) {
+			called = true
+			calledCaller = handlers.CallerFromContext(r.Context())
+			w.WriteHeader(http.StatusOK)
+		}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("allows callers authorized for the virtual resource", func() {
+		expectReviews(muoServiceAccount, true)
+
+		authorizer.Authorize(handlers.UpgradePoliciesResource, handlers.UpgradePolicyStateSubresource, next)(recorder, newRequest("sa-token"))
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(called).To(BeTrue())
+		Expect(calledCaller).To(Equal(muoServiceAccount))
+	})
+
+	It("forbids callers that are not authorized for the virtual resource", func() {
+		expectReviews("system:serviceaccount:default:default", false)
+
+		authorizer.Authorize(handlers.UpgradePoliciesResource, handlers.UpgradePolicyStateSubresource, next)(recorder, newRequest("sa-token"))
+
+		Expect(recorder.Code).To(Equal(http.StatusForbidden))
+		Expect(called).To(BeFalse())
+	})
+
+	It("rejects requests without a bearer token", func() {
+		authorizer.Authorize(handlers.UpgradePoliciesResource, handlers.UpgradePolicyStateSubresource, next)(recorder, newRequest(""))
+
+		Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+		Expect(called).To(BeFalse())
+	})
+
+	It("rejects tokens that can't be authenticated", func() {
+		expectReviews("", false)
+
+		authorizer.Authorize(handlers.UpgradePoliciesResource, handlers.UpgradePolicyStateSubresource, next)(recorder, newRequest("sa-token"))
+
+		Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+		Expect(called).To(BeFalse())
+	})
+
+	It("fails closed when the reviews can't be created", func() {
+		mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("api server unavailable"))
+
+		authorizer.Authorize(handlers.UpgradePoliciesResource, handlers.UpgradePolicyStateSubresource, next)(recorder, newRequest("sa-token"))
+
+		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		Expect(called).To(BeFalse())
+	})
+
+	It("authorizes the reads of a collection as a list", func() {
+		resourceAttributes = authorizationv1.ResourceAttributes{
+			Group:    consts.ProxyAuthorizationGroup,
+			Resource: handlers.UpgradePoliciesResource,
+			Verb:     "list",
+		}
+		expectReviews(muoServiceAccount, true)
+		req := httptest.NewRequest("GET", "/upgrade_policies", nil)
+		req.Header.Set("Authorization", "Bearer sa-token")
+
+		authorizer.AuthorizeCollection(handlers.UpgradePoliciesResource, next)(recorder, req)
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(called).To(BeTrue())
+	})
+
+	It("authorizes the creations in a collection", func() {
+		resourceAttributes = authorizationv1.ResourceAttributes{
+			Group:    consts.ProxyAuthorizationGroup,
+			Resource: handlers.UpgradePoliciesResource,
+			Verb:     "create",
+		}
+		expectReviews(muoServiceAccount, false)
+		req := httptest.NewRequest("POST", "/upgrade_policies", nil)
+		req.Header.Set("Authorization", "Bearer sa-token")
+
+		authorizer.AuthorizeCollection(handlers.UpgradePoliciesResource, next)(recorder, req)
+
+		Expect(recorder.Code).To(Equal(http.StatusForbidden))
+		Expect(called).To(BeFalse())
+	})
+
+	Context("with unauthenticated reads", func() {
+		BeforeEach(func() {
+			authorizer = authorizer.WithUnauthenticatedReads()
+		})
+
+		It("lets the reads through without a bearer token", func() {
+			authorizer.Authorize(handlers.UpgradePoliciesResource, handlers.UpgradePolicyStateSubresource, next)(recorder, httptest.NewRequest("GET", "/upgrade_policies/upgrade-policy-id/state", nil))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(called).To(BeTrue())
+
+			recorder = httptest.NewRecorder()
+			authorizer.AuthorizeCollection(handlers.UpgradePoliciesResource, next)(recorder, httptest.NewRequest("GET", "/upgrade_policies", nil))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(calledCaller).To(BeEmpty())
+		})
+
+		DescribeTable("still authorizes the changes",
+			func(method string, collection bool) {
+				req := httptest.NewRequest(method, "/upgrade_policies", nil)
+				if collection {
+					authorizer.AuthorizeCollection(handlers.UpgradePoliciesResource, next)(recorder, req)
+				} else {
+					authorizer.Authorize(handlers.UpgradePoliciesResource, "", next)(recorder, req)
+				}
+
+				Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+				Expect(called).To(BeFalse())
+			},
+			Entry("POST", "POST", true),
+			Entry("PATCH", "PATCH", false),
+			Entry("DELETE", "DELETE", false),
+		)
+
+		It("authorizes the changes of authenticated callers", func() {
+			expectReviews(muoServiceAccount, true)
+
+			authorizer.Authorize(handlers.UpgradePoliciesResource, handlers.UpgradePolicyStateSubresource, next)(recorder, newRequest("sa-token"))
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(calledCaller).To(Equal(muoServiceAccount))
+		})
+	})
+
+	It("caches authorization decisions", func() {
+		expectReviews(muoServiceAccount, true)
+
+		for i := 0; i < 3; i++ {
+			recorder = httptest.NewRecorder()
+			authorizer.Authorize(handlers.UpgradePoliciesResource, handlers.UpgradePolicyStateSubresource, next)(recorder, newRequest("sa-token"))
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+		}
+	})
+})
@@ -1,10 +1,11 @@
 package handlers
 
 import (
+	"io"
+	"net/http"
+
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/openshift/ocm-agent/pkg/ocm"
-	log "github.com/sirupsen/logrus"
-	"net/http"
 )
 
 type ClusterHandler struct {
@@ -15,7 +16,7 @@ type ClusterHandler struct {
 // For /api/clusters_mgmt/v1/clusters/{cluster_id}
 // https://api.openshift.com/#/default/get_api_clusters_mgmt_v1_clusters__cluster_id_
 func NewClusterHandler(o ocm.OCMClient, clusterId string) *ClusterHandler {
-	log.Debug("Creating new cluster object Handler")
+	packageLogger.Debug("Creating new cluster object Handler")
 	return &ClusterHandler{
 		ocm:       o,
 		clusterId: clusterId,
@@ -27,20 +28,12 @@ func NewClusterHandler(o ocm.OCMClient, clusterId string) *ClusterHandler {
 func (g *ClusterHandler) ServeClusterGet(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case "GET":
-		cluster, operationIdHeader, err := g.ocm.GetCluster(g.clusterId)
+		cluster, operationIdHeader, err := ocm.WithContext(g.ocm, r.Context()).GetCluster(g.clusterId)
 
 		w.Header().Set(OCM_OPERATION_ID_HEADER, operationIdHeader)
-		if err != nil {
-			errorMessageResponse(err, w)
-			return
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		err = cmv1.MarshalCluster(cluster, w)
-		if err != nil {
-			errorMessageResponse(err, w)
-			return
-		}
+		writeCacheableResponse(w, r, err, func(out io.Writer) error {
+			return cmv1.MarshalCluster(cluster, out)
+		})
 	default:
 		invalidRequestVerbResponse(r.Method, w)
 	}
@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -346,6 +347,23 @@ var _ = Describe("ClusterHandler", func() {
 			Expect(responseRecorder.Result().StatusCode).To(Equal(http.StatusOK))
 		})
 
+		It("should make the ocm call with the context of the request", func() {
+			makeOCMRequest(
+				"GET",
+				http.StatusOK,
+				fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s", internalId),
+				getCluster,
+			)
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			req := httptest.NewRequest("GET", "/cluster", nil).WithContext(ctx)
+
+			clusterHandler.ServeClusterGet(responseRecorder, req)
+
+			Expect(responseRecorder.Result().StatusCode).To(Equal(http.StatusBadRequest))
+			Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+		})
+
 		It("should set OCM operation ID header for successful requests", func() {
 			makeOCMRequest(
 				"GET",
@@ -360,6 +378,66 @@ var _ = Describe("ClusterHandler", func() {
 			Expect(responseRecorder.Header().Get(handlers.OCM_OPERATION_ID_HEADER)).To(Equal(ocmOperationId))
 		})
 
+		It("should set an ETag and return 304 when If-None-Match matches it", func() {
+			makeOCMRequest(
+				"GET",
+				http.StatusOK,
+				fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s", internalId),
+				getCluster,
+			)
+			req := httptest.NewRequest("GET", "/cluster", nil)
+			clusterHandler.ServeClusterGet(responseRecorder, req)
+			etag := responseRecorder.Header().Get("ETag")
+			Expect(etag).ToNot(BeEmpty())
+
+			responseRecorder = httptest.NewRecorder()
+			req = httptest.NewRequest("GET", "/cluster", nil)
+			req.Header.Set("If-None-Match", etag)
+			clusterHandler.ServeClusterGet(responseRecorder, req)
+
+			Expect(responseRecorder.Result().StatusCode).To(Equal(http.StatusNotModified))
+			Expect(responseRecorder.Body.Len()).To(Equal(0))
+			Expect(responseRecorder.Header().Get("ETag")).To(Equal(etag))
+		})
+
+		It("should serve a stale cached response with a warning when ocm is unavailable", func() {
+			accessToken := MakeTokenString("Bearer", 15*time.Minute)
+			sdkclient, _ := sdk.NewConnectionBuilder().
+				Logger(nil).
+				Tokens(accessToken).
+				URL(apiServer.URL()).
+				Build()
+			cachingHandler := handlers.NewClusterHandler(ocm.NewCachingOcmClient(ocm.NewOcmClient(sdkclient), 0, time.Hour), internalId)
+
+			makeOCMRequest(
+				"GET",
+				http.StatusOK,
+				fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s", internalId),
+				getCluster,
+			)
+			cachingHandler.ServeClusterGet(responseRecorder, httptest.NewRequest("GET", "/cluster", nil))
+			Expect(responseRecorder.Result().StatusCode).To(Equal(http.StatusOK))
+			Expect(responseRecorder.Header().Get("Warning")).To(BeEmpty())
+
+			makeOCMRequest(
+				"GET",
+				http.StatusInternalServerError,
+				fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s", internalId),
+				`{"message": "Internal server error"}`,
+			)
+			responseRecorder = httptest.NewRecorder()
+			cachingHandler.ServeClusterGet(responseRecorder, httptest.NewRequest("GET", "/cluster", nil))
+
+			var cluster cmv1.Cluster
+			_ = json.NewDecoder(responseRecorder.Result().Body).Decode(&cluster)
+			var ocmResp cmv1.Cluster
+			_ = json.Unmarshal([]byte(getCluster), &ocmResp)
+
+			Expect(responseRecorder.Result().StatusCode).To(Equal(http.StatusOK))
+			Expect(responseRecorder.Header().Get("Warning")).To(Equal(handlers.StaleResponseWarning))
+			Expect(reflect.DeepEqual(cluster, ocmResp)).To(BeTrue())
+		})
+
 		It("should set OCM operation ID header for error responses", func() {
 			errorMessage := `{"message": "Test error"}`
 			makeOCMRequest(
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// Reasons of the Events recorded on ManagedNotifications and ManagedFleetNotificationRecords
+	// for the decisions taken on a notification
+	EventReasonNotificationSent                     = "NotificationSent"
+	EventReasonNotificationSuppressedResendWindow   = "NotificationSuppressedResendWindow"
+	EventReasonNotificationSuppressedRateLimit      = "NotificationSuppressedRateLimit"
+	EventReasonNotificationSuppressedLimitedSupport = "NotificationSuppressedLimitedSupport"
+	EventReasonNotificationFailed                   = "NotificationFailed"
+)
+
+// recordEvent records an Event on obj with the recorder, if one is configured.
+func recordEvent(recorder record.EventRecorder, obj runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	if recorder == nil || obj == nil {
+		packageLogger.WithField("reason", reason).Debug("No event recorder configured, not recording event")
+		return
+	}
+	recorder.Eventf(obj, eventType, reason, messageFmt, args...)
+}
+
+// alertState describes whether an alert is firing or resolved in Event messages.
+func alertState(isCurrentlyFiring bool) string {
+	if isCurrentlyFiring {
+		return "firing"
+	}
+	return "resolved"
+}
@@ -1,22 +1,138 @@
 package handlers
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 
-	log "github.com/sirupsen/logrus"
+	oav1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	"github.com/openshift/ocm-agent/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/ocm-agent/pkg/ocm"
+)
+
+const (
+	OCM_OPERATION_ID_HEADER = "X-Operation-Id"
+
+	// Warning header value set when OCM is unreachable and a cached response is served instead
+	// See https://www.rfc-editor.org/rfc/rfc7234#section-5.5.1
+	StaleResponseWarning = `110 ocm-agent "Response is Stale"`
+
+	// Caller recorded in the audit log for OCM calls made for alerts received from Alertmanager
+	AuditCallerWebhookReceiver = "alertmanager-receiver"
 )
 
-const OCM_OPERATION_ID_HEADER = "X-Operation-Id"
+var (
+	// packageLogger logs at the level of the handlers package, which can be changed at runtime
+	packageLogger = logging.ForPackage(logging.PackageHandlers)
+)
 
 func errorMessageResponse(err error, w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "text/plain")
-	log.Error(err)
+	packageLogger.Error(err)
 	http.Error(w, fmt.Sprintf("%v", err), http.StatusBadRequest)
 }
 
+// auditObjectReference references one of the agent's custom resources in audit records and Events.
+func auditObjectReference(kind string, obj client.Object) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		APIVersion:      oav1alpha1.GroupVersion.String(),
+		Kind:            kind,
+		Namespace:       obj.GetNamespace(),
+		Name:            obj.GetName(),
+		UID:             obj.GetUID(),
+		ResourceVersion: obj.GetResourceVersion(),
+	}
+}
+
+// proxyAuditContext returns the audit context of a mutating proxy request. Its caller is the authenticated one, or the
+// remote address and user agent of the request when it wasn't authenticated.
+func proxyAuditContext(r *http.Request) ocm.AuditContext {
+	caller := CallerFromContext(r.Context())
+	if caller == "" {
+		caller = "unauthenticated " + r.RemoteAddr
+		if userAgent := r.UserAgent(); userAgent != "" {
+			caller += fmt.Sprintf(" (%s)", userAgent)
+		}
+	}
+	return ocm.AuditContext{Caller: caller}
+}
+
 func invalidRequestVerbResponse(method string, w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "text/plain")
-	log.Errorf("Invalid request verb: %s", method)
+	packageLogger.Errorf("Invalid request verb: %s", method)
 	http.Error(w, "Bad request body", http.StatusBadRequest)
 }
+
+// listOptionsFromRequest reads the OCM list query parameters (page, size, search and order) from the request
+// so that they can be passed through to OCM.
+func listOptionsFromRequest(r *http.Request) (ocm.ListOptions, error) {
+	query := r.URL.Query()
+	opts := ocm.ListOptions{
+		Search: query.Get(ocm.ListParamSearch),
+		Order:  query.Get(ocm.ListParamOrder),
+	}
+
+	for param, value := range map[string]*int{ocm.ListParamPage: &opts.Page, ocm.ListParamSize: &opts.Size} {
+		if query.Get(param) == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(query.Get(param))
+		if err != nil || parsed < 1 {
+			return ocm.ListOptions{}, fmt.Errorf("invalid '%s' query parameter: must be a positive integer", param)
+		}
+		*value = parsed
+	}
+
+	return opts, nil
+}
+
+// writeCacheableResponse marshals a proxied OCM object, tags it with an ETag and answers with
+// 304 Not Modified if the request's If-None-Match header matches it.
+// If err indicates stale cached data, the response is still written with a Warning header.
+func writeCacheableResponse(w http.ResponseWriter, r *http.Request, err error, marshal func(io.Writer) error) {
+	if err != nil {
+		if !ocm.IsStaleResponse(err) {
+			errorMessageResponse(err, w)
+			return
+		}
+		packageLogger.FromContext(r.Context()).WithError(err).Warn("OCM is unavailable, serving stale response")
+		w.Header().Set("Warning", StaleResponseWarning)
+	}
+
+	var body bytes.Buffer
+	if err := marshal(&body); err != nil {
+		errorMessageResponse(err, w)
+		return
+	}
+
+	sum := sha256.Sum256(body.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+	w.Header().Set("ETag", etag)
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body.Bytes())
+}
+
+// etagMatches reports whether the If-None-Match header value contains the given ETag.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
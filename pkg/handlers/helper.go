@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/prometheus/alertmanager/template"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/openshift/ocm-agent/pkg/ocm"
@@ -20,6 +22,7 @@ const (
 	AMLabelManagedNotification = "send_managed_notification"
 	AMLabelAlertMCID           = "_mc_id"
 	AMLabelAlertHCID           = "_id"
+	AMLabelOCMEnvironment      = "ocm_environment"
 
 	LogFieldNotificationName           = "notification"
 	LogFieldNotificationRecordName     = "notification_record"
@@ -31,6 +34,12 @@ const (
 	LogFieldPostServiceLogOpId         = "post_servicelog_operation_id"
 	LogFieldPostServiceLogFailedReason = "post_servicelog_failed_reason"
 
+	// Attributes of the spans created for each alert
+	TraceAttributeAlertName        = "alert.name"
+	TraceAttributeNotificationName = "alert.notification"
+	TraceAttributeIsFiring         = "alert.is_firing"
+	TraceAttributeHostedClusterID  = "alert.hosted_cluster_id"
+
 	// Header returned in OCM responses
 	HeaderOperationId = "X-Operation-Id"
 )
@@ -45,11 +54,21 @@ type AMReceiverResponse struct {
 // Use prometheus alertmanager template type for post data
 type AMReceiverData template.Data
 
+// AMReceiverMessage is the payload posted by Alertmanager to the webhook receiver
+type AMReceiverMessage struct {
+	AMReceiverData
+	// GroupKey identifies the group of alerts the notification is for
+	GroupKey string `json:"groupKey"`
+}
+
 type AMReceiverAlert template.Alert
 
 type WebhookReceiverHandler struct {
-	c   client.Client
-	ocm ocm.OCMClient
+	c          client.Client
+	ocm        ocm.OCMClient
+	recorder   record.EventRecorder
+	namespaces []string
+	now        func() time.Time
 }
 
 type OCMResponseBody struct {
@@ -63,32 +82,32 @@ func isValidAlert(alert template.Alert, fleetMode bool) bool {
 	// An invalid alert won't have a name
 	alertname, err := alertName(alert)
 	if err != nil {
-		log.WithError(err).Info("alertname missing for alert")
+		packageLogger.WithError(err).Info("alertname missing for alert")
 		return false
 	}
 
 	// An invalid alert won't have a send_managed_notification label
 	if val, ok := alert.Labels[AMLabelManagedNotification]; !ok || val == "false" {
-		log.WithField(LogFieldAlertname, alertname).Error("alert has no send_managed_notification label")
+		packageLogger.WithField(LogFieldAlertname, alertname).Error("alert has no send_managed_notification label")
 		return false
 	}
 
 	// An invalid alert won't have a managed_notification_template label
 	if _, ok := alert.Labels[AMLabelTemplateName]; !ok {
-		log.WithField(LogFieldAlertname, alertname).Error("alert has no managed notification defined")
+		packageLogger.WithField(LogFieldAlertname, alertname).Error("alert has no managed notification defined")
 		return false
 	}
 
 	if fleetMode {
 		// An alert in fleet mode must have a management cluster ID label
 		if _, ok := alert.Labels[AMLabelAlertMCID]; !ok {
-			log.WithField(LogFieldAlertname, alertname).Error("fleet mode alert has no management cluster ID")
+			packageLogger.WithField(LogFieldAlertname, alertname).Error("fleet mode alert has no management cluster ID")
 			return false
 		}
 
 		// An alert in fleet mode must have a hosted cluster ID label
 		if _, ok := alert.Labels[AMLabelAlertHCID]; !ok {
-			log.WithField(LogFieldAlertname, alertname).Error("fleet mode alert has no hosted cluster ID")
+			packageLogger.WithField(LogFieldAlertname, alertname).Error("fleet mode alert has no hosted cluster ID")
 			return false
 		}
 	}
@@ -106,7 +125,7 @@ func alertName(a template.Alert) (*string, error) {
 // responseChecker checks the ocm response returns error or not
 func responseChecker(opId string, statusCode int, asBytes []byte) error {
 	if statusCode == http.StatusCreated {
-		log.WithField(LogFieldPostServiceLogOpId, opId).Info("service log sent succeeded")
+		packageLogger.WithField(LogFieldPostServiceLogOpId, opId).Info("service log sent succeeded")
 		return nil
 	}
 
@@ -116,7 +135,7 @@ func responseChecker(opId string, statusCode int, asBytes []byte) error {
 		return err
 	}
 
-	log.WithFields(log.Fields{LogFieldPostServiceLogOpId: opId, LogFieldPostServiceLogFailedReason: ocmRes.Reason}).Error("service log sent failed")
+	packageLogger.WithFields(log.Fields{LogFieldPostServiceLogOpId: opId, LogFieldPostServiceLogFailedReason: ocmRes.Reason}).Error("service log sent failed")
 
 	switch statusCode {
 	case http.StatusBadRequest:
@@ -131,3 +150,21 @@ func responseChecker(opId string, statusCode int, asBytes []byte) error {
 		return fmt.Errorf("unknown Service Log return code")
 	}
 }
+
+// namespacesOrDefault returns the namespaces the notification custom resources are read from, in order of
+// precedence, which is the namespace of the operator unless configured otherwise
+func namespacesOrDefault(namespaces []string) []string {
+	if len(namespaces) == 0 {
+		return []string{OCMAgentNamespaceName}
+	}
+	return namespaces
+}
+
+// clockOrDefault returns the function the receivers get the current time from, which is time.Now unless
+// configured otherwise
+func clockOrDefault(now func() time.Time) func() time.Time {
+	if now == nil {
+		return time.Now
+	}
+	return now
+}
@@ -3,8 +3,6 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
-
-	log "github.com/sirupsen/logrus"
 )
 
 type LivezHandler struct {
@@ -20,7 +18,7 @@ func NewLivezHandler() *LivezHandler {
 }
 
 func (h *LivezHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	log.Debug("Handling livez request")
+	packageLogger.FromContext(r.Context()).Debug("Handling livez request")
 	// validate request
 	if r != nil && r.Method != http.MethodGet {
 		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
@@ -36,7 +34,7 @@ func (h *LivezHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	err = json.NewEncoder(w).Encode(response)
 	if err != nil {
-		log.Errorf("Failed to write to response: %s\n", err)
+		packageLogger.FromContext(r.Context()).Errorf("Failed to write to response: %s\n", err)
 		http.Error(w, "Failed to write to response", http.StatusInternalServerError)
 		return
 	}
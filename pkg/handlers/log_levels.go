@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/openshift/ocm-agent/pkg/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// How long a log level change lasts when no TTL is given
+	DefaultLogLevelTTL = time.Hour
+	// Longest a log level change may last before it reverts
+	MaxLogLevelTTL = 24 * time.Hour
+
+	// Query parameter of the package whose log level is reset
+	LogLevelPackageParam = "package"
+)
+
+// LogLevelRequest changes the log level of a package, or of all packages if Package is empty.
+type LogLevelRequest struct {
+	Level   string `json:"level"`
+	Package string `json:"package,omitempty"`
+	// TTL is how long the change lasts, such as 15m. Defaults to DefaultLogLevelTTL.
+	TTL string `json:"ttl,omitempty"`
+}
+
+type LogLevelsHandler struct{}
+
+// NewLogLevelsHandler returns the admin handler reading and changing the log levels at runtime.
+func NewLogLevelsHandler() *LogLevelsHandler {
+	packageLogger.Debug("Creating new log levels Handler")
+	return &LogLevelsHandler{}
+}
+
+// ServeHTTP returns the current log levels on GET, changes a log level on PATCH and
+// reverts the level of the package given as query parameter, or of all packages, on DELETE.
+func (h *LogLevelsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := packageLogger.FromContext(r.Context()).WithField("caller", CallerFromContext(r.Context()))
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPatch:
+		var request LogLevelRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			errorMessageResponse(fmt.Errorf("invalid log level request: %w", err), w)
+			return
+		}
+		level, ttl, err := validateLogLevelRequest(request)
+		if err != nil {
+			errorMessageResponse(err, w)
+			return
+		}
+		if err := logging.SetLevel(request.Package, level, ttl); err != nil {
+			errorMessageResponse(err, w)
+			return
+		}
+		logger.WithFields(log.Fields{"package": request.Package, "level": level.String(), "ttl": ttl.String()}).Warn("Log level changed")
+	case http.MethodDelete:
+		pkg := r.URL.Query().Get(LogLevelPackageParam)
+		if err := logging.ResetLevel(pkg); err != nil {
+			errorMessageResponse(err, w)
+			return
+		}
+		logger.WithField("package", pkg).Warn("Log level reset")
+	default:
+		invalidRequestVerbResponse(r.Method, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(logging.CurrentLevels()); err != nil {
+		logger.WithError(err).Error("Failed to write log levels")
+	}
+}
+
+func validateLogLevelRequest(request LogLevelRequest) (log.Level, time.Duration, error) {
+	level, err := log.ParseLevel(request.Level)
+	if err != nil {
+		return level, 0, err
+	}
+
+	ttl := DefaultLogLevelTTL
+	if request.TTL != "" {
+		ttl, err = time.ParseDuration(request.TTL)
+		if err != nil {
+			return level, 0, fmt.Errorf("invalid ttl: %w", err)
+		}
+	}
+	if ttl <= 0 || ttl > MaxLogLevelTTL {
+		return level, 0, fmt.Errorf("ttl must be positive and at most %s", MaxLogLevelTTL)
+	}
+	return level, ttl, nil
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift/ocm-agent/pkg/logging"
+)
+
+var _ = Describe("Log levels handler", func() {
+	var (
+		handler  *LogLevelsHandler
+		recorder *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		handler = NewLogLevelsHandler()
+		recorder = httptest.NewRecorder()
+	})
+
+	AfterEach(func() {
+		Expect(logging.ResetLevel(logging.PackageOCM)).To(Succeed())
+	})
+
+	serve := func(method, target, body string) logging.Levels {
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+		var levels logging.Levels
+		if recorder.Code == http.StatusOK {
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &levels)).To(Succeed())
+		}
+		return levels
+	}
+
+	It("returns the current levels", func() {
+		levels := serve(http.MethodGet, "/", "")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(levels.Packages).To(HaveKey(logging.PackageOCM))
+	})
+
+	It("changes and resets the level of a package", func() {
+		levels := serve(http.MethodPatch, "/", `{"level":"debug","package":"ocm","ttl":"15m"}`)
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(levels.Packages[logging.PackageOCM].Level).To(Equal("debug"))
+		Expect(levels.Packages[logging.PackageOCM].Expires).ToNot(BeNil())
+
+		recorder = httptest.NewRecorder()
+		levels = serve(http.MethodDelete, "/?package=ocm", "")
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(levels.Packages[logging.PackageOCM].Expires).To(BeNil())
+	})
+
+	DescribeTable("rejects invalid requests",
+		func(method, body string) {
+			serve(method, "/", body)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		},
+		Entry("unknown level", http.MethodPatch, `{"level":"loud"}`),
+		Entry("unknown package", http.MethodPatch, `{"level":"debug","package":"unknown"}`),
+		Entry("ttl above the maximum", http.MethodPatch, `{"level":"debug","ttl":"48h"}`),
+		Entry("invalid ttl", http.MethodPatch, `{"level":"debug","ttl":"soon"}`),
+		Entry("malformed body", http.MethodPatch, `{`),
+		Entry("unsupported verb", http.MethodPost, `{"level":"debug"}`),
+	)
+})
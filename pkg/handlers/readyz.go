@@ -3,8 +3,6 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
-
-	log "github.com/sirupsen/logrus"
 )
 
 type ReadyzHandler struct {
@@ -20,7 +18,7 @@ func NewReadyzHandler() *ReadyzHandler {
 }
 
 func (h *ReadyzHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	log.Debug("Handling readyz request")
+	packageLogger.FromContext(r.Context()).Debug("Handling readyz request")
 	// validate request
 	if r != nil && r.Method != http.MethodGet {
 		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
@@ -35,7 +33,7 @@ func (h *ReadyzHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	err = json.NewEncoder(w).Encode(response)
 	if err != nil {
-		log.Errorf("Failed to write to response: %s\n", err)
+		packageLogger.FromContext(r.Context()).Errorf("Failed to write to response: %s\n", err)
 		http.Error(w, "Failed to write to response", http.StatusInternalServerError)
 		return
 	}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+
+	slv1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/openshift/ocm-agent/pkg/ocm"
+)
+
+// ServiceLogsHandler represents a request or requests to the service logs endpoint set in OCM.
+type ServiceLogsHandler struct {
+	ocm         ocm.OCMClient
+	clusterUUID string
+}
+
+// Creates a new ServiceLogsHandler instance for the cluster with the given external ID.
+func NewServiceLogsHandler(o ocm.OCMClient, clusterUUID string) *ServiceLogsHandler {
+	packageLogger.Debug("Creating new service logs Handler")
+	return &ServiceLogsHandler{
+		ocm:         o,
+		clusterUUID: clusterUUID,
+	}
+}
+
+// ServeServiceLogList reads and writes raw HTTP requests and proxies them to the 'list' endpoint for the cluster's service logs
+// Proxies to https://api.openshift.com/#/default/get_api_service_logs_v1_clusters_cluster_logs
+func (g *ServiceLogsHandler) ServeServiceLogList(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		listOptions, err := listOptionsFromRequest(r)
+		if err != nil {
+			errorMessageResponse(err, w)
+			return
+		}
+
+		serviceLogs, operationIdHeader, err := ocm.WithContext(g.ocm, r.Context()).GetServiceLogs(g.clusterUUID, listOptions)
+		w.Header().Set(OCM_OPERATION_ID_HEADER, operationIdHeader)
+
+		writeCacheableResponse(w, r, err, func(out io.Writer) error {
+			return slv1.MarshalLogEntryList(serviceLogs, out)
+		})
+	default:
+		invalidRequestVerbResponse(r.Method, w)
+	}
+}
@@ -1,15 +1,20 @@
 package handlers
 
 import (
+	"fmt"
+	"io"
 	"net/http"
+	"regexp"
 
 	"github.com/gorilla/mux"
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"github.com/openshift/ocm-agent/pkg/consts"
 	"github.com/openshift/ocm-agent/pkg/ocm"
-	log "github.com/sirupsen/logrus"
 )
 
+// upgradePolicyVersionRegex matches the OpenShift release versions an upgrade policy can target, e.g. 4.14.5 or 4.15.0-rc.1
+var upgradePolicyVersionRegex = regexp.MustCompile(`^[0-9]+\.[0-9]+\.[0-9]+(-[0-9A-Za-z.-]+)?$`)
+
 // UpgradePoliciesHandler represents a request or requests to the upgrade policies endpoint set
 // in OCM.
 type UpgradePoliciesHandler struct {
@@ -19,31 +24,52 @@ type UpgradePoliciesHandler struct {
 
 // Creates a new UpgradePoliciesHandler instance.
 func NewUpgradePoliciesHandler(o ocm.OCMClient, clusterId string) *UpgradePoliciesHandler {
-	log.Debug("Creating new upgrade policies Handler")
+	packageLogger.Debug("Creating new upgrade policies Handler")
 	return &UpgradePoliciesHandler{
 		ocm:       o,
 		clusterID: clusterId,
 	}
 }
 
-// ServeUpgradePolicyList reads and writes raw HTTP requests and proxies them to the 'list' endpoints for upgrade policies
+// ServeUpgradePolicyList reads and writes raw HTTP requests and proxies them to the 'list' and 'create' endpoints for upgrade policies
 func (g *UpgradePoliciesHandler) ServeUpgradePolicyList(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case "GET":
-		policies, operationIdHeader, err := g.ocm.GetUpgradePolicies(g.clusterID)
+		listOptions, err := listOptionsFromRequest(r)
+		if err != nil {
+			errorMessageResponse(err, w)
+			return
+		}
+
+		policies, operationIdHeader, err := ocm.WithContext(g.ocm, r.Context()).GetUpgradePolicies(g.clusterID, listOptions)
 		w.Header().Set(ocm.OcmOperationIdHeader, operationIdHeader)
 
+		writeCacheableResponse(w, r, err, func(out io.Writer) error {
+			return cmv1.MarshalUpgradePolicyList(policies, out)
+		})
+	case "POST":
+		policy, err := cmv1.UnmarshalUpgradePolicy(r.Body)
 		if err != nil {
 			errorMessageResponse(err, w)
 			return
 		}
+		if err := validateUpgradePolicy(policy, true); err != nil {
+			errorMessageResponse(err, w)
+			return
+		}
 
-		w.Header().Set("Content-Type", "application/json")
-		err = cmv1.MarshalUpgradePolicyList(policies, w)
+		createdPolicy, operationIdHeader, err := ocm.WithContext(ocm.WithAuditContext(g.ocm, proxyAuditContext(r)), r.Context()).CreateUpgradePolicy(g.clusterID, policy)
+		w.Header().Set(OCM_OPERATION_ID_HEADER, operationIdHeader)
 		if err != nil {
 			errorMessageResponse(err, w)
 			return
 		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		if err := cmv1.MarshalUpgradePolicy(createdPolicy, w); err != nil {
+			packageLogger.FromContext(r.Context()).WithError(err).Error("Failed to write created upgrade policy")
+		}
 	default:
 		invalidRequestVerbResponse(r.Method, w)
 	}
@@ -56,20 +82,45 @@ func (g *UpgradePoliciesHandler) ServeUpgradePolicyGet(w http.ResponseWriter, r
 
 	switch r.Method {
 	case "GET":
-		policy, operationIdHeader, err := g.ocm.GetUpgradePolicy(g.clusterID, upgradePolicyID)
+		policy, operationIdHeader, err := ocm.WithContext(g.ocm, r.Context()).GetUpgradePolicy(g.clusterID, upgradePolicyID)
 		w.Header().Set(OCM_OPERATION_ID_HEADER, operationIdHeader)
 
+		writeCacheableResponse(w, r, err, func(out io.Writer) error {
+			return cmv1.MarshalUpgradePolicy(policy, out)
+		})
+	case "PATCH":
+		policy, err := cmv1.UnmarshalUpgradePolicy(r.Body)
+		if err != nil {
+			errorMessageResponse(err, w)
+			return
+		}
+		if err := validateUpgradePolicy(policy, false); err != nil {
+			errorMessageResponse(err, w)
+			return
+		}
+
+		updatedPolicy, operationIdHeader, err := ocm.WithContext(ocm.WithAuditContext(g.ocm, proxyAuditContext(r)), r.Context()).UpdateUpgradePolicy(g.clusterID, upgradePolicyID, policy)
+		w.Header().Set(OCM_OPERATION_ID_HEADER, operationIdHeader)
 		if err != nil {
 			errorMessageResponse(err, w)
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		err = cmv1.MarshalUpgradePolicy(policy, w)
+		err = cmv1.MarshalUpgradePolicy(updatedPolicy, w)
+		if err != nil {
+			errorMessageResponse(err, w)
+			return
+		}
+	case "DELETE":
+		operationIdHeader, err := ocm.WithContext(ocm.WithAuditContext(g.ocm, proxyAuditContext(r)), r.Context()).DeleteUpgradePolicy(g.clusterID, upgradePolicyID)
+		w.Header().Set(OCM_OPERATION_ID_HEADER, operationIdHeader)
 		if err != nil {
 			errorMessageResponse(err, w)
 			return
 		}
+
+		w.WriteHeader(http.StatusNoContent)
 	default:
 		invalidRequestVerbResponse(r.Method, w)
 	}
@@ -82,20 +133,12 @@ func (g *UpgradePoliciesHandler) ServeUpgradePolicyState(w http.ResponseWriter,
 
 	switch r.Method {
 	case "GET":
-		policyState, operationIdHeader, err := g.ocm.GetUpgradePolicyState(g.clusterID, upgradePolicyID)
+		policyState, operationIdHeader, err := ocm.WithContext(g.ocm, r.Context()).GetUpgradePolicyState(g.clusterID, upgradePolicyID)
 
 		w.Header().Set(OCM_OPERATION_ID_HEADER, operationIdHeader)
-		if err != nil {
-			errorMessageResponse(err, w)
-			return
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		err = cmv1.MarshalUpgradePolicyState(policyState, w)
-		if err != nil {
-			errorMessageResponse(err, w)
-			return
-		}
+		writeCacheableResponse(w, r, err, func(out io.Writer) error {
+			return cmv1.MarshalUpgradePolicyState(policyState, out)
+		})
 	case "PATCH":
 		updatedPolicyState, err := cmv1.UnmarshalUpgradePolicyState(r.Body)
 		if err != nil {
@@ -103,7 +146,7 @@ func (g *UpgradePoliciesHandler) ServeUpgradePolicyState(w http.ResponseWriter,
 			return
 		}
 
-		policy, operationIdHeader, err := g.ocm.UpdateUpgradePolicyState(g.clusterID, upgradePolicyID, updatedPolicyState)
+		policy, operationIdHeader, err := ocm.WithContext(ocm.WithAuditContext(g.ocm, proxyAuditContext(r)), r.Context()).UpdateUpgradePolicyState(g.clusterID, upgradePolicyID, updatedPolicyState)
 		w.Header().Set(OCM_OPERATION_ID_HEADER, operationIdHeader)
 		if err != nil {
 			errorMessageResponse(err, w)
@@ -120,3 +163,42 @@ func (g *UpgradePoliciesHandler) ServeUpgradePolicyState(w http.ResponseWriter,
 		invalidRequestVerbResponse(r.Method, w)
 	}
 }
+
+// validateUpgradePolicy checks an upgrade policy before it is forwarded to OCM.
+// A new policy needs a schedule type and the fields that schedule type depends on: a cron schedule for
+// automatic upgrades, or a version and next run time for manual upgrades. For updates, only the fields
+// that are set are checked.
+func validateUpgradePolicy(policy *cmv1.UpgradePolicy, create bool) error {
+	scheduleType, hasScheduleType := policy.GetScheduleType()
+	if hasScheduleType || create {
+		switch scheduleType {
+		case cmv1.ScheduleTypeAutomatic, cmv1.ScheduleTypeManual:
+		default:
+			return fmt.Errorf("invalid schedule_type '%s': must be one of '%s' or '%s'", scheduleType, cmv1.ScheduleTypeAutomatic, cmv1.ScheduleTypeManual)
+		}
+	}
+
+	if version, ok := policy.GetVersion(); ok && !upgradePolicyVersionRegex.MatchString(version) {
+		return fmt.Errorf("invalid version '%s': must be an OpenShift release version such as 4.14.5", version)
+	}
+
+	if !create {
+		return nil
+	}
+
+	switch scheduleType {
+	case cmv1.ScheduleTypeAutomatic:
+		if policy.Schedule() == "" {
+			return fmt.Errorf("schedule is required for '%s' upgrade policies", cmv1.ScheduleTypeAutomatic)
+		}
+	case cmv1.ScheduleTypeManual:
+		if policy.Version() == "" {
+			return fmt.Errorf("version is required for '%s' upgrade policies", cmv1.ScheduleTypeManual)
+		}
+		if policy.NextRun().IsZero() {
+			return fmt.Errorf("next_run is required for '%s' upgrade policies", cmv1.ScheduleTypeManual)
+		}
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -115,6 +116,33 @@ var _ = Describe("UpgradePolicies", func() {
 		Expect(reflect.DeepEqual(ocmOperationId, responseRecorder.Header().Get(handlers.OCM_OPERATION_ID_HEADER))).To(BeTrue())
 		Expect(reflect.DeepEqual(policies, items)).To(BeTrue())
 	})
+	It("should pass the list query parameters through to ocm", func() {
+		makeOCMRequest(
+			"GET",
+			http.StatusOK,
+			fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s/upgrade_policies", internalId),
+			getUpgradePolicies,
+		)
+		req := httptest.NewRequest("GET", "/upgrade_policies?page=3&size=5&search=schedule_type+%3D+%27manual%27&order=next_run", nil)
+
+		upgradePoliciesHandler.ServeUpgradePolicyList(responseRecorder, req)
+
+		Expect(responseRecorder.Result().StatusCode).To(Equal(http.StatusOK))
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(1))
+		query := apiServer.ReceivedRequests()[0].URL.Query()
+		Expect(query.Get("page")).To(Equal("3"))
+		Expect(query.Get("size")).To(Equal("5"))
+		Expect(query.Get("search")).To(Equal("schedule_type = 'manual'"))
+		Expect(query.Get("order")).To(Equal("next_run"))
+	})
+	It("should reject invalid list query parameters", func() {
+		req := httptest.NewRequest("GET", "/upgrade_policies?page=first", nil)
+
+		upgradePoliciesHandler.ServeUpgradePolicyList(responseRecorder, req)
+
+		Expect(responseRecorder.Result().StatusCode).To(Equal(http.StatusBadRequest))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
 	It("should get an upgrade policy", func() {
 		makeOCMRequest(
 			"GET",
@@ -214,6 +242,171 @@ var _ = Describe("UpgradePolicies", func() {
 		Expect(reflect.DeepEqual(policyState, ocmResp)).To(BeTrue())
 	})
 
+	It("should create an upgrade policy", func() {
+		makeOCMRequest(
+			"POST",
+			http.StatusCreated,
+			fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s/upgrade_policies", internalId),
+			getUpgradePolicy,
+		)
+
+		reqBody := `{
+			"schedule_type": "manual",
+			"upgrade_type": "OSD",
+			"version": "4.14.5",
+			"next_run": "2023-11-01T01:57:42.055Z"
+		}`
+
+		req := httptest.NewRequest("POST", "/upgrade_policies", strings.NewReader(reqBody))
+
+		upgradePoliciesHandler.ServeUpgradePolicyList(responseRecorder, req)
+
+		var policy cmv1.UpgradePolicy
+		// nolint
+		_ = json.NewDecoder(responseRecorder.Result().Body).Decode(&policy)
+		var ocmResp cmv1.UpgradePolicy
+		// nolint
+		_ = json.Unmarshal([]byte(getUpgradePolicy), &ocmResp)
+
+		Expect(responseRecorder.Result().StatusCode).To(Equal(http.StatusCreated))
+		Expect(responseRecorder.Header().Get(handlers.OCM_OPERATION_ID_HEADER)).To(Equal(ocmOperationId))
+		Expect(reflect.DeepEqual(policy, ocmResp)).To(BeTrue())
+	})
+
+	It("should audit the changes of unauthenticated callers with their address and user agent", func() {
+		makeOCMRequest(
+			"DELETE",
+			http.StatusNoContent,
+			fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s/upgrade_policies/%s", internalId, upgradePolicyId),
+			"",
+		)
+		accessToken := MakeTokenString("Bearer", 15*time.Minute)
+		sdkclient, _ := sdk.NewConnectionBuilder().
+			Logger(nil).
+			Tokens(accessToken).
+			URL(apiServer.URL()).
+			Build()
+		sink := &recordingAuditSink{}
+		auditedHandler := handlers.NewUpgradePoliciesHandler(ocm.NewAuditingOcmClient(ocm.NewOcmClient(sdkclient), sink), internalId)
+
+		req := httptest.NewRequest("DELETE", fmt.Sprintf("/upgrade_policies/%s", upgradePolicyId), nil)
+		req.RemoteAddr = "10.128.0.42:53012"
+		req.Header.Set("User-Agent", "managed-upgrade-operator/1.0")
+		req = mux.SetURLVars(
+			req,
+			map[string]string{
+				consts.UpgradePolicyIdParam: upgradePolicyId,
+			},
+		)
+
+		auditedHandler.ServeUpgradePolicyGet(responseRecorder, req)
+
+		Expect(responseRecorder.Result().StatusCode).To(Equal(http.StatusNoContent))
+		Expect(sink.records).To(HaveLen(1))
+		Expect(sink.records[0].Caller).To(Equal("unauthenticated 10.128.0.42:53012 (managed-upgrade-operator/1.0)"))
+	})
+
+	It("should reject invalid upgrade policies before forwarding them to ocm", func() {
+		invalidBodies := []string{
+			`{"schedule_type": "sometimes", "schedule": "0 0 * * *"}`,
+			`{"schedule_type": "manual", "version": "latest", "next_run": "2023-11-01T01:57:42.055Z"}`,
+			`{"schedule_type": "manual", "next_run": "2023-11-01T01:57:42.055Z"}`,
+			`{"schedule_type": "manual", "version": "4.14.5"}`,
+			`{"schedule_type": "automatic"}`,
+			`{"schedule": "0 0 * * *"}`,
+		}
+
+		for _, body := range invalidBodies {
+			responseRecorder = httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/upgrade_policies", strings.NewReader(body))
+
+			upgradePoliciesHandler.ServeUpgradePolicyList(responseRecorder, req)
+			Expect(responseRecorder.Result().StatusCode).To(Equal(http.StatusBadRequest), body)
+		}
+
+		responseRecorder = httptest.NewRecorder()
+		req := httptest.NewRequest("PATCH", fmt.Sprintf("/upgrade_policies/%s", upgradePolicyId), strings.NewReader(`{"version": "4.14"}`))
+		req = mux.SetURLVars(
+			req,
+			map[string]string{
+				consts.UpgradePolicyIdParam: upgradePolicyId,
+			},
+		)
+
+		upgradePoliciesHandler.ServeUpgradePolicyGet(responseRecorder, req)
+		Expect(responseRecorder.Result().StatusCode).To(Equal(http.StatusBadRequest))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("should update an upgrade policy", func() {
+		makeOCMRequest(
+			"PATCH",
+			http.StatusOK,
+			fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s/upgrade_policies/%s", internalId, upgradePolicyId),
+			getUpgradePolicy,
+		)
+
+		req := httptest.NewRequest("PATCH", fmt.Sprintf("/upgrade_policies/%s", upgradePolicyId), strings.NewReader(`{"schedule": "0 2 * * 1"}`))
+		req = mux.SetURLVars(
+			req,
+			map[string]string{
+				consts.UpgradePolicyIdParam: upgradePolicyId,
+			},
+		)
+
+		upgradePoliciesHandler.ServeUpgradePolicyGet(responseRecorder, req)
+
+		Expect(responseRecorder.Result().StatusCode).To(Equal(http.StatusOK))
+		Expect(responseRecorder.Header().Get(handlers.OCM_OPERATION_ID_HEADER)).To(Equal(ocmOperationId))
+		Expect(apiServer.ReceivedRequests()).To(HaveLen(1))
+	})
+
+	It("should make the ocm calls with the context of the request", func() {
+		makeOCMRequest(
+			"PATCH",
+			http.StatusOK,
+			fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s/upgrade_policies/%s", internalId, upgradePolicyId),
+			getUpgradePolicy,
+		)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		req := httptest.NewRequest("PATCH", fmt.Sprintf("/upgrade_policies/%s", upgradePolicyId), strings.NewReader(`{"schedule": "0 2 * * 1"}`)).WithContext(ctx)
+		req = mux.SetURLVars(
+			req,
+			map[string]string{
+				consts.UpgradePolicyIdParam: upgradePolicyId,
+			},
+		)
+
+		upgradePoliciesHandler.ServeUpgradePolicyGet(responseRecorder, req)
+
+		Expect(responseRecorder.Result().StatusCode).To(Equal(http.StatusBadRequest))
+		Expect(apiServer.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("should delete an upgrade policy", func() {
+		makeOCMRequest(
+			"DELETE",
+			http.StatusNoContent,
+			fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s/upgrade_policies/%s", internalId, upgradePolicyId),
+			"",
+		)
+
+		req := httptest.NewRequest("DELETE", fmt.Sprintf("/upgrade_policies/%s", upgradePolicyId), nil)
+		req = mux.SetURLVars(
+			req,
+			map[string]string{
+				consts.UpgradePolicyIdParam: upgradePolicyId,
+			},
+		)
+
+		upgradePoliciesHandler.ServeUpgradePolicyGet(responseRecorder, req)
+
+		Expect(responseRecorder.Result().StatusCode).To(Equal(http.StatusNoContent))
+		Expect(responseRecorder.Header().Get(handlers.OCM_OPERATION_ID_HEADER)).To(Equal(ocmOperationId))
+	})
+
 	It("should return an error if ocm returns an error for all methods", func() {
 		errorMessage := `{"message": "Cannot connect to ocm api"}`
 		makeOCMRequest(
@@ -240,6 +433,12 @@ var _ = Describe("UpgradePolicies", func() {
 			fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s/upgrade_policies/%s/state", internalId, upgradePolicyId),
 			errorMessage,
 		)
+		makeOCMRequest(
+			"DELETE",
+			http.StatusBadRequest,
+			fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s/upgrade_policies/%s", internalId, upgradePolicyId),
+			errorMessage,
+		)
 
 		req := httptest.NewRequest("GET", "/upgrade_policies", nil)
 
@@ -337,6 +536,12 @@ var _ = Describe("UpgradePolicies", func() {
 			fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s/upgrade_policies/%s/state", internalId, upgradePolicyId),
 			errorMessage,
 		)
+		makeOCMRequest(
+			"DELETE",
+			http.StatusPermanentRedirect,
+			fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s/upgrade_policies/%s", internalId, upgradePolicyId),
+			errorMessage,
+		)
 
 		req := httptest.NewRequest("GET", "/upgrade_policies", nil)
 
@@ -408,3 +613,12 @@ var _ = Describe("UpgradePolicies", func() {
 		Expect(responseRecorder.Result().StatusCode).To(Equal(http.StatusBadRequest))
 	})
 })
+
+// recordingAuditSink keeps the audit records of the OCM calls
+type recordingAuditSink struct {
+	records []ocm.AuditRecord
+}
+
+func (s *recordingAuditSink) Record(record ocm.AuditRecord) {
+	s.records = append(s.records, record)
+}
@@ -16,22 +16,42 @@ import (
 
 	oav1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	"github.com/openshift/ocm-agent/pkg/consts"
+	"github.com/openshift/ocm-agent/pkg/logging"
 	"github.com/openshift/ocm-agent/pkg/metrics"
+	"github.com/openshift/ocm-agent/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func NewWebhookReceiverHandler(c client.Client, o ocm.OCMClient) *WebhookReceiverHandler {
+func NewWebhookReceiverHandler(c client.Client, o ocm.OCMClient, recorder record.EventRecorder) *WebhookReceiverHandler {
 	return &WebhookReceiverHandler{
-		c:   c,
-		ocm: o,
+		c:        c,
+		ocm:      o,
+		recorder: recorder,
 	}
 }
 
+// WithNamespaces sets the namespaces the ManagedNotifications are read from. When a notification is defined in
+// several of them, the one of the first namespace is used.
+func (h *WebhookReceiverHandler) WithNamespaces(namespaces []string) *WebhookReceiverHandler {
+	h.namespaces = namespaces
+	return h
+}
+
+// WithClock sets the function the receiver gets the current time from, which the resend windows and the
+// condition transition times are based on. This allows to replay recorded alerts with a simulated clock.
+func (h *WebhookReceiverHandler) WithClock(now func() time.Time) *WebhookReceiverHandler {
+	h.now = now
+	return h
+}
+
 func (h *WebhookReceiverHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// validate request
 	if r != nil && r.Method != http.MethodPost {
@@ -39,74 +59,90 @@ func (h *WebhookReceiverHandler) ServeHTTP(w http.ResponseWriter, r *http.Reques
 		return
 	}
 	var err error
-	var alertData AMReceiverData
+	var alertData AMReceiverMessage
 	err = json.NewDecoder(r.Body).Decode(&alertData)
 	if err != nil {
-		log.Errorf("Failed to process request body: %s\n", err)
+		packageLogger.FromContext(r.Context()).Errorf("Failed to process request body: %s\n", err)
 		http.Error(w, "Bad request body", http.StatusBadRequest)
-		metrics.SetRequestMetricFailure(consts.WebhookReceiverPath)
 		return
 	}
 
 	// process request
-	response := h.processAMReceiver(alertData, r.Context())
+	ctx := logging.WithFields(r.Context(), log.Fields{logging.FieldGroupKey: alertData.GroupKey})
+	start := time.Now()
+	response := h.processAMReceiver(alertData.AMReceiverData, ctx)
+	metrics.ObserveWebhookProcessing(metrics.AlertModeClassic, time.Since(start))
 
 	// write response
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(response.Code)
 	err = json.NewEncoder(w).Encode(response)
 	if err != nil {
-		log.Errorf("Failed to write to response: %s\n", err)
+		packageLogger.FromContext(r.Context()).Errorf("Failed to write to response: %s\n", err)
 		http.Error(w, "Failed to write to response", http.StatusInternalServerError)
-		metrics.SetRequestMetricFailure(consts.WebhookReceiverPath)
 		return
 	}
-
-	metrics.ResetMetric(metrics.MetricRequestFailure)
 }
 
 type notificationRetriever struct {
-	ctx                                       context.Context
-	kubeCli                                   client.Client
-	notificationNameToManagedNotificationName map[string]string
+	ctx                                   context.Context
+	kubeCli                               client.Client
+	notificationNameToManagedNotification map[string]client.ObjectKey
+	now                                   func() time.Time
 }
 
-func newNotificationRetriever(kubeCli client.Client, ctx context.Context) (*notificationRetriever, error) {
-	managedNotificationList := &oav1alpha1.ManagedNotificationList{}
-	listOptions := []client.ListOption{
-		client.InNamespace(OCMAgentNamespaceName),
-	}
+// newNotificationRetriever lists the ManagedNotifications of the namespaces, the notifications of the first
+// namespaces taking precedence over those with the same name in the following ones
+func newNotificationRetriever(kubeCli client.Client, ctx context.Context, namespaces ...string) (*notificationRetriever, error) {
+	result := &notificationRetriever{ctx, kubeCli, make(map[string]client.ObjectKey), time.Now}
 
-	err := kubeCli.List(ctx, managedNotificationList, listOptions...)
-	if err != nil {
-		log.WithError(err).Error("unable to list managed notifications")
-		return nil, err
-	}
+	for _, namespace := range namespacesOrDefault(namespaces) {
+		managedNotificationList := &oav1alpha1.ManagedNotificationList{}
+		listOptions := []client.ListOption{
+			client.InNamespace(namespace),
+		}
 
-	result := &notificationRetriever{ctx, kubeCli, make(map[string]string)}
+		err := kubeCli.List(ctx, managedNotificationList, listOptions...)
+		if err != nil {
+			packageLogger.FromContext(ctx).WithError(err).WithField("namespace", namespace).Error("unable to list managed notifications")
+			return nil, err
+		}
 
-	for _, managedNotification := range managedNotificationList.Items {
-		for _, notification := range managedNotification.Spec.Notifications {
-			result.notificationNameToManagedNotificationName[notification.Name] = managedNotification.Name
+		for _, managedNotification := range managedNotificationList.Items {
+			for _, notification := range managedNotification.Spec.Notifications {
+				if existing, ok := result.notificationNameToManagedNotification[notification.Name]; ok {
+					if existing.Namespace != namespace {
+						packageLogger.FromContext(ctx).WithFields(log.Fields{LogFieldNotificationName: notification.Name, "namespace": namespace}).
+							Debugf("notification is already defined in namespace %s", existing.Namespace)
+					}
+					continue
+				}
+				result.notificationNameToManagedNotification[notification.Name] = client.ObjectKey{Namespace: namespace, Name: managedNotification.Name}
+			}
 		}
 	}
 
 	return result, nil
 }
 
+// withContext returns a copy of the retriever making its calls with ctx.
+func (r *notificationRetriever) withContext(ctx context.Context) *notificationRetriever {
+	withContext := *r
+	withContext.ctx = ctx
+	return &withContext
+}
+
 // retrieveNotificationContext returns the notification from the ManagedNotification bundle if one exists, or error if one does not
 func (r *notificationRetriever) retrieveNotificationContext(notificationName string) (*notificationContext, error) {
-	managedNotificationName, ok := r.notificationNameToManagedNotificationName[notificationName]
+	managedNotificationKey, ok := r.notificationNameToManagedNotification[notificationName]
 	if !ok {
 		return nil, fmt.Errorf("no managed notification found for notification %s", notificationName)
 	}
+	managedNotificationName := managedNotificationKey.Name
 
 	managedNotification := &oav1alpha1.ManagedNotification{}
 
-	err := r.kubeCli.Get(r.ctx, client.ObjectKey{
-		Namespace: OCMAgentNamespaceName,
-		Name:      managedNotificationName,
-	}, managedNotification)
+	err := r.kubeCli.Get(r.ctx, managedNotificationKey, managedNotification)
 	if err != nil {
 		return nil, err
 	}
@@ -146,18 +182,19 @@ func (r *notificationRetriever) retrieveNotificationContext(notificationName str
 }
 
 func (h *WebhookReceiverHandler) processAMReceiver(d AMReceiverData, ctx context.Context) *AMReceiverResponse {
-	log.WithField("AMReceiverData", fmt.Sprintf("%+v", d)).Info("Process alert data")
+	packageLogger.FromContext(ctx).WithField("AMReceiverData", fmt.Sprintf("%+v", d)).Info("Process alert data")
 
-	notificationRetriever, err := newNotificationRetriever(h.c, ctx)
+	notificationRetriever, err := newNotificationRetriever(h.c, ctx, h.namespaces...)
 	if err != nil {
 		return &AMReceiverResponse{Error: err, Status: "unable to retrieve managed notifications", Code: http.StatusInternalServerError}
 	}
+	notificationRetriever.now = clockOrDefault(h.now)
 
 	// Handle each firing alert
 	for _, alert := range d.Alerts.Firing() {
 		err := h.processAlert(alert, notificationRetriever, true)
 		if err != nil {
-			log.WithError(err).Error("a firing alert could not be successfully processed")
+			packageLogger.FromContext(ctx).WithError(err).Error("a firing alert could not be successfully processed")
 		}
 	}
 
@@ -165,7 +202,7 @@ func (h *WebhookReceiverHandler) processAMReceiver(d AMReceiverData, ctx context
 	for _, alert := range d.Alerts.Resolved() {
 		err := h.processAlert(alert, notificationRetriever, false)
 		if err != nil {
-			log.WithError(err).Error("a resolved alert could not be successfully processed")
+			packageLogger.FromContext(ctx).WithError(err).Error("a resolved alert could not be successfully processed")
 		}
 	}
 	return &AMReceiverResponse{Error: nil, Status: "ok", Code: http.StatusOK}
@@ -180,7 +217,7 @@ type notificationContext struct {
 }
 
 func (c *notificationContext) canSendServiceLog(isCurrentlyFiring bool) bool {
-	nowTime := time.Now()
+	nowTime := c.retriever.now()
 	slSentCondition := c.notificationRecord.Conditions.GetCondition(oav1alpha1.ConditionServiceLogSent)
 
 	// If alert is firing
@@ -260,7 +297,7 @@ func (c *notificationContext) setCondition(condType oav1alpha1.NotificationCondi
 }
 
 func (c *notificationContext) updateFiringAndResolvedConditions(isCurrentlyFiring bool) error {
-	nowTime := &v1.Time{Time: time.Now()}
+	nowTime := &v1.Time{Time: c.retriever.now()}
 	var firingReason, resolvedReason string
 
 	if isCurrentlyFiring {
@@ -297,7 +334,7 @@ func (c *notificationContext) updateServiceLogSentCondition(isCurrentlyFiring, h
 		slSentReason = "Service log sent for alert resolved"
 	}
 
-	c.setCondition(oav1alpha1.ConditionServiceLogSent, slSentReason, hasSLBeenSent, true, &v1.Time{Time: time.Now()})
+	c.setCondition(oav1alpha1.ConditionServiceLogSent, slSentReason, hasSLBeenSent, true, &v1.Time{Time: c.retriever.now()})
 
 	c.managedNotification.Status.NotificationRecords.SetNotificationRecord(*c.notificationRecord)
 
@@ -306,15 +343,31 @@ func (c *notificationContext) updateServiceLogSentCondition(isCurrentlyFiring, h
 
 func (c *notificationContext) sendServiceLog(ocmCli ocm.OCMClient, alert template.Alert, isCurrentlyFiring bool) error {
 	// Send the servicelog for the alert
-	log.WithFields(log.Fields{LogFieldNotificationName: c.notification.Name}).Info("will send service log")
+	packageLogger.FromContext(c.retriever.ctx).WithFields(log.Fields{LogFieldNotificationName: c.notification.Name}).Info("will send service log")
 
+	ocmCli = ocm.WithContext(ocm.WithAuditContext(ocmCli, ocm.AuditContext{
+		Caller: AuditCallerWebhookReceiver,
+		Object: auditObjectReference("ManagedNotification", c.managedNotification),
+	}), c.retriever.ctx)
 	slErr := ocm.BuildAndSendServiceLog(
 		ocm.NewServiceLogBuilder(c.notification.Summary, c.notification.ActiveDesc, c.notification.ResolvedDesc, viper.GetString(config.ExternalClusterID), c.notification.Severity, c.notification.LogType, c.notification.References),
 		isCurrentlyFiring, &alert, ocmCli)
 
-	err := c.updateServiceLogSentCondition(isCurrentlyFiring, slErr == nil)
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		err := c.updateServiceLogSentCondition(isCurrentlyFiring, slErr == nil)
+		if errors.IsConflict(err) {
+			// The ManagedNotification was updated meanwhile, e.g. for another of its notifications: the condition is
+			// set again on its latest version, keeping the state the service log was sent for
+			latest, getErr := c.retriever.retrieveNotificationContext(c.notification.Name)
+			if getErr != nil {
+				return getErr
+			}
+			c.managedNotification, c.notificationRecord = latest.managedNotification, latest.notificationRecord
+		}
+		return err
+	})
 	if err != nil {
-		log.WithFields(log.Fields{LogFieldNotificationName: c.notification.Name, LogFieldManagedNotification: c.managedNotification.Name}).WithError(err).Error("unable to update ServiceLogSent condition")
+		packageLogger.FromContext(c.retriever.ctx).WithFields(log.Fields{LogFieldNotificationName: c.notification.Name, LogFieldManagedNotification: c.managedNotification.Name}).WithError(err).Error("unable to update ServiceLogSent condition")
 	}
 
 	return slErr
@@ -322,17 +375,38 @@ func (c *notificationContext) sendServiceLog(ocmCli ocm.OCMClient, alert templat
 
 // processAlert handles the pre-check verification and sending of a notification for a particular alert
 // and returns an error if that process completed successfully or false otherwise
-func (h *WebhookReceiverHandler) processAlert(alert template.Alert, notificationRetriever *notificationRetriever, isCurrentlyFiring bool) error {
+func (h *WebhookReceiverHandler) processAlert(alert template.Alert, notificationRetriever *notificationRetriever, isCurrentlyFiring bool) (err error) {
+	ctx, span := tracing.StartSpan(notificationRetriever.ctx, "process alert", trace.WithAttributes(
+		attribute.String(TraceAttributeAlertName, alert.Labels[AMLabelAlertName]),
+		attribute.String(TraceAttributeNotificationName, alert.Labels[AMLabelTemplateName]),
+		attribute.Bool(TraceAttributeIsFiring, isCurrentlyFiring),
+	))
+	defer func() { tracing.EndSpan(span, err) }()
+	start := time.Now()
+	notificationName := alert.Labels[AMLabelTemplateName]
+	outcome := metrics.AlertOutcomeFailed
+	defer func() {
+		metrics.ObserveAlertProcessing(metrics.AlertModeClassic, notificationName, outcome, time.Since(start))
+	}()
+	ctx = logging.WithFields(ctx, log.Fields{
+		logging.FieldNotification: alert.Labels[AMLabelTemplateName],
+		logging.FieldClusterID:    viper.GetString(config.ExternalClusterID),
+	})
+	notificationRetriever = notificationRetriever.withContext(ctx)
+	logger := packageLogger.FromContext(ctx)
+
 	// Should this alert be handled?
 	if !isValidAlert(alert, false) {
-		log.WithField(LogFieldAlert, fmt.Sprintf("%+v", alert)).Info("alert does not meet valid criteria")
+		logger.WithField(LogFieldAlert, fmt.Sprintf("%+v", alert)).Info("alert does not meet valid criteria")
+		// Don't label the metrics with templates which don't exist
+		notificationName, outcome = "", metrics.AlertOutcomeInvalidAlert
 		return fmt.Errorf("alert does not meet valid criteria")
 	}
 
 	// Can the alert be mapped to an existing notification definition?
-	notificationName := alert.Labels[AMLabelTemplateName]
-	if _, ok := notificationRetriever.notificationNameToManagedNotificationName[notificationName]; !ok {
-		log.WithField(LogFieldAlert, fmt.Sprintf("%+v", alert)).Warning("an alert fired with no associated notification")
+	if _, ok := notificationRetriever.notificationNameToManagedNotification[notificationName]; !ok {
+		logger.WithField(LogFieldAlert, fmt.Sprintf("%+v", alert)).Warning("an alert fired with no associated notification")
+		notificationName, outcome = "", metrics.AlertOutcomeNoTemplate
 		return fmt.Errorf("an alert fired with no associated notification")
 	}
 
@@ -340,10 +414,12 @@ func (h *WebhookReceiverHandler) processAlert(alert template.Alert, notification
 	var canSend bool
 
 	// Critical section: AlertFiring and AlertResolved conditions are read and set/updated in an atomic way
-	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+	err = tracing.Retry(ctx, "retry ManagedNotification status update", func(fn func() error) error {
+		return retry.RetryOnConflict(retry.DefaultRetry, fn)
+	}, func(ctx context.Context) error {
 		var err error
 
-		c, err = notificationRetriever.retrieveNotificationContext(notificationName)
+		c, err = notificationRetriever.withContext(ctx).retrieveNotificationContext(notificationName)
 		if err != nil {
 			return err
 		}
@@ -356,16 +432,22 @@ func (h *WebhookReceiverHandler) processAlert(alert template.Alert, notification
 	if err != nil {
 		return err
 	}
+	// The notification is sent within the span of the alert, the one of the retries has ended
+	c.retriever = notificationRetriever
 
 	if !canSend {
 		if isCurrentlyFiring {
-			log.WithFields(log.Fields{"notification": notificationName,
+			logger.WithFields(log.Fields{"notification": notificationName,
 				LogFieldResendInterval: c.notification.ResendWait,
 			}).Info("not sending a notification as one was already sent recently")
+			recordEvent(h.recorder, c.managedNotification, corev1.EventTypeNormal, EventReasonNotificationSuppressedResendWindow,
+				"Service log for notification %s not sent: one was already sent recently (resend wait %dh)", notificationName, c.notification.ResendWait)
 			// Reset the metric for correct service log response from OCM
 			metrics.ResetResponseMetricFailure(config.ServiceLogService, notificationName, alert.Labels["alertname"])
+			outcome = metrics.AlertOutcomeSuppressedResendWindow
 		} else {
-			log.WithFields(log.Fields{"notification": notificationName}).Info("not sending a resolve notification if it was not firing or resolved body is empty")
+			logger.WithFields(log.Fields{"notification": notificationName}).Info("not sending a resolve notification if it was not firing or resolved body is empty")
+			outcome = metrics.AlertOutcomeNothingToDo
 		}
 		// This is not an error state
 		return nil
@@ -373,7 +455,9 @@ func (h *WebhookReceiverHandler) processAlert(alert template.Alert, notification
 
 	err = c.sendServiceLog(h.ocm, alert, isCurrentlyFiring)
 	if err != nil {
-		log.WithError(err).WithFields(log.Fields{LogFieldNotificationName: notificationName, LogFieldIsFiring: isCurrentlyFiring}).Error("unable to send a service log")
+		logger.WithError(err).WithFields(log.Fields{LogFieldNotificationName: notificationName, LogFieldIsFiring: isCurrentlyFiring}).Error("unable to send a service log")
+		recordEvent(h.recorder, c.managedNotification, corev1.EventTypeWarning, EventReasonNotificationFailed,
+			"Service log for %s notification %s could not be sent: %v", alertState(isCurrentlyFiring), notificationName, err)
 
 		// Set the metric for failed service log response from OCM
 		metrics.SetResponseMetricFailure(config.ServiceLogService, notificationName, alert.Labels["alertname"])
@@ -381,6 +465,9 @@ func (h *WebhookReceiverHandler) processAlert(alert template.Alert, notification
 		return err
 	}
 
+	recordEvent(h.recorder, c.managedNotification, corev1.EventTypeNormal, EventReasonNotificationSent,
+		"Service log sent for %s notification %s", alertState(isCurrentlyFiring), notificationName)
+
 	// Reset the metric for correct service log response from OCM
 	metrics.ResetResponseMetricFailure(config.ServiceLogService, notificationName, alert.Labels["alertname"])
 
@@ -391,7 +478,6 @@ func (h *WebhookReceiverHandler) processAlert(alert template.Alert, notification
 		metrics.CountServiceLogSent(notificationName, "resolved")
 	}
 
-	metrics.SetTotalServiceLogCount(notificationName, c.notificationRecord.ServiceLogSentCount)
-
+	outcome = metrics.AlertOutcomeSent
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,22 +14,65 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/ghttp"
 	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/viper"
 	"go.uber.org/mock/gomock"
 	corev1 "k8s.io/api/core/v1"
 	k8serrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	ocmagentv1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
 
 	"github.com/openshift/ocm-agent/pkg/config"
 	testconst "github.com/openshift/ocm-agent/pkg/consts/test"
+	"github.com/openshift/ocm-agent/pkg/metrics"
 	"github.com/openshift/ocm-agent/pkg/ocm"
 	webhookreceivermock "github.com/openshift/ocm-agent/pkg/ocm/mocks"
 	clientmocks "github.com/openshift/ocm-agent/pkg/util/test/generated/mocks/client"
 )
 
+// alertOutcomes returns how many alerts were processed with the given outcome
+func alertOutcomes(mode, template, outcome string) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	Expect(err).ToNot(HaveOccurred())
+	for _, family := range families {
+		if family.GetName() != "ocm_agent_alert_outcomes_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, pair := range metric.GetLabel() {
+				labels[pair.GetName()] = pair.GetValue()
+			}
+			if labels["mode"] == mode && labels["template"] == template && labels["outcome"] == outcome {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+// requestMetric returns the value of the request metric for the path, and whether it has a series for it
+func requestMetric(name, path string) (float64, bool) {
+	families, err := prometheus.DefaultGatherer.Gather()
+	Expect(err).ToNot(HaveOccurred())
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, pair := range metric.GetLabel() {
+				if pair.GetName() == "path" && pair.GetValue() == path {
+					return metric.GetGauge().GetValue(), true
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
 type RoundTripperFunc func(*http.Request) (*http.Response, error)
 
 func (fn RoundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
@@ -138,6 +182,7 @@ var _ = Describe("Webhook Handlers", func() {
 		testAlertResolved      template.Alert
 		activeServiceLog       *ocm.ServiceLog
 		resolvedServiceLog     *ocm.ServiceLog
+		fakeRecorder           *record.FakeRecorder
 	)
 
 	BeforeEach(func() {
@@ -157,9 +202,11 @@ var _ = Describe("Webhook Handlers", func() {
 		server = ghttp.NewServer()
 		// //mockHTTPChecker = httpcheckermock.NewMockHTTPChecker(mockCtrl)
 		mockOCMClient = webhookreceivermock.NewMockOCMClient(mockCtrl)
+		fakeRecorder = record.NewFakeRecorder(10)
 		webhookReceiverHandler = &WebhookReceiverHandler{
-			c:   mockClient,
-			ocm: mockOCMClient,
+			c:        mockClient,
+			ocm:      mockOCMClient,
+			recorder: fakeRecorder,
 		}
 		testAlert = testconst.NewTestAlert(false, false)
 		testAlertResolved = testconst.NewTestAlert(true, false)
@@ -184,7 +231,7 @@ var _ = Describe("Webhook Handlers", func() {
 
 	Context("NewWebhookReceiverHandler", func() {
 		It("should create a new Webhook Receiver Handler", func() {
-			handler := NewWebhookReceiverHandler(mockClient, mockOCMClient)
+			handler := NewWebhookReceiverHandler(mockClient, mockOCMClient, record.NewFakeRecorder(10))
 			Expect(handler).ToNot(BeNil())
 			Expect(handler).To(BeAssignableToTypeOf(&WebhookReceiverHandler{}))
 		})
@@ -247,6 +294,26 @@ var _ = Describe("Webhook Handlers", func() {
 			Expect(response).Should(Equal(expected))
 		})
 	})
+	Context("AMReceiver handler post when the notifications can't be listed", func() {
+		const path = "/alertmanager-receiver-list-failure"
+		var resp *http.Response
+		var err error
+		BeforeEach(func() {
+			// The request metrics are recorded by the middleware from the status of the response
+			server.AppendHandlers(metrics.PrometheusMiddleware(webhookReceiverHandler).ServeHTTP)
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("list failure"))
+			postDataJson, _ := json.Marshal(AMReceiverData{Status: "foo"})
+			resp, err = http.Post(server.URL()+path, "application/json", bytes.NewBuffer(postDataJson))
+		})
+		It("Records a request failure and no success", func() {
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(resp.StatusCode).Should(Equal(http.StatusInternalServerError))
+			failure, _ := requestMetric("ocm_agent_request_failure", path)
+			Expect(failure).To(Equal(float64(1)))
+			_, succeeded := requestMetric("ocm_agent_request_last_success_timestamp_seconds", path)
+			Expect(succeeded).To(BeFalse())
+		})
+	})
 	Context("AMReceiver handler post bad data", func() {
 		var resp *http.Response
 		var err error
@@ -330,10 +397,38 @@ var _ = Describe("Webhook Handlers", func() {
 		})
 	})
 
+	Context("notificationRetriever in several namespaces", func() {
+		const stagingNamespace = "ocm-agent-staging"
+
+		It("Should prefer the notifications of the first namespace", func() {
+			stagingManagedNotification := testconst.TestManagedNotification.DeepCopy()
+			stagingManagedNotification.Namespace = stagingNamespace
+			stagingManagedNotification.Name = "staging-notifications"
+			gomock.InOrder(
+				mockClient.EXPECT().List(gomock.Any(), gomock.Any(), client.InNamespace(stagingNamespace)).
+					SetArg(1, ocmagentv1alpha1.ManagedNotificationList{Items: []ocmagentv1alpha1.ManagedNotification{*stagingManagedNotification}}).Return(nil),
+				mockClient.EXPECT().List(gomock.Any(), gomock.Any(), client.InNamespace(OCMAgentNamespaceName)).
+					SetArg(1, *testconst.TestManagedNotificationList).Return(nil),
+			)
+
+			notificationRetriever, err := newNotificationRetriever(mockClient, context.TODO(), stagingNamespace, OCMAgentNamespaceName)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(notificationRetriever.notificationNameToManagedNotification).To(HaveKeyWithValue(testconst.TestNotificationName,
+				client.ObjectKey{Namespace: stagingNamespace, Name: "staging-notifications"}))
+		})
+
+		It("Should fail when a namespace can't be listed", func() {
+			mockClient.EXPECT().List(gomock.Any(), gomock.Any(), client.InNamespace(stagingNamespace)).Return(errors.New("forbidden"))
+
+			_, err := newNotificationRetriever(mockClient, context.TODO(), stagingNamespace, OCMAgentNamespaceName)
+			Expect(err).Should(HaveOccurred())
+		})
+	})
+
 	Context("WebhookReceiverHandler.processAlert", func() {
 		var testNotifRetriever *notificationRetriever
 		BeforeEach(func() {
-			testNotifRetriever = &notificationRetriever{context.TODO(), mockClient, map[string]string{testconst.TestNotificationName: testconst.TestManagedNotification.Name}}
+			testNotifRetriever = &notificationRetriever{context.TODO(), mockClient, map[string]client.ObjectKey{testconst.TestNotificationName: {Namespace: OCMAgentNamespaceName, Name: testconst.TestManagedNotification.Name}}, time.Now}
 		})
 		Context("Alert is invalid", func() {
 			It("Reports error if alert does not have alertname label", func() {
@@ -352,9 +447,11 @@ var _ = Describe("Webhook Handlers", func() {
 				Expect(err).Should(HaveOccurred())
 			})
 			It("Reports error if alert send_managed_notification label does not name a valid notification", func() {
+				noTemplate := alertOutcomes(metrics.AlertModeClassic, "", metrics.AlertOutcomeNoTemplate)
 				testAlert.Labels["managed_notification_template"] = "dummy-nonexistent-test"
 				err := webhookReceiverHandler.processAlert(testAlert, testNotifRetriever, true)
 				Expect(err).Should(HaveOccurred())
+				Expect(alertOutcomes(metrics.AlertModeClassic, "", metrics.AlertOutcomeNoTemplate)).To(Equal(noTemplate + 1))
 			})
 		})
 		Context("Alert is valid", func() {
@@ -362,9 +459,11 @@ var _ = Describe("Webhook Handlers", func() {
 			var conditions ocmagentv1alpha1.Conditions
 			var updatedConditions []ocmagentv1alpha1.Conditions
 			var updatedConditionsError error
+			var updateErrors []error
 			BeforeEach(func() {
 				notification = testconst.TestNotification
 				updatedConditions = nil
+				updateErrors = nil
 				mockClient.EXPECT().Get(gomock.Any(), client.ObjectKey{
 					Namespace: OCMAgentNamespaceName,
 					Name:      testconst.TestManagedNotification.Name,
@@ -399,18 +498,27 @@ var _ = Describe("Webhook Handlers", func() {
 						Expect(updatedNotificationRecords[0].Name).To(Equal(testconst.TestNotificationName))
 						updatedConditions = append(updatedConditions, updatedNotificationRecords[0].Conditions.DeepCopy())
 
+						if len(updateErrors) > 0 {
+							err := updateErrors[0]
+							updateErrors = updateErrors[1:]
+							return err
+						}
 						return updatedConditionsError
 					},
 				).MinTimes(1)
 			})
 			It("Should send a service log when receiving a firing alert and the alert never fired before", func() {
+				sent := alertOutcomes(metrics.AlertModeClassic, testconst.TestNotificationName, metrics.AlertOutcomeSent)
 				conditions = getConditions(-1, -1, 0, 0, 0)
 				mockOCMClient.EXPECT().SendServiceLog(activeServiceLog).Return(nil)
 				err := webhookReceiverHandler.processAlert(testAlert, testNotifRetriever, true)
 				Expect(err).ShouldNot(HaveOccurred())
+				Expect(alertOutcomes(metrics.AlertModeClassic, testconst.TestNotificationName, metrics.AlertOutcomeSent)).To(Equal(sent + 1))
 				Expect(len(updatedConditions)).To(Equal(2))
 				assertConditions(updatedConditions[0], 1, -1, 0, 0, 0)
 				assertConditions(updatedConditions[1], 1, 1, 0, 0, 0)
+				Expect(fakeRecorder.Events).To(Receive(Equal(fmt.Sprintf("Normal %s Service log sent for firing notification %s",
+					EventReasonNotificationSent, testconst.TestNotificationName))))
 			})
 			It("Should send a service log when receiving a firing alert and the alert was marked as resolved", func() {
 				conditions = getConditions(0, 1, 90, 90, 90)
@@ -434,11 +542,14 @@ var _ = Describe("Webhook Handlers", func() {
 				assertConditions(updatedConditions[2], 1, 1, 0, 0, 0)
 			})
 			It("Should not resend a service log when receiving a firing alert within the resend time window", func() {
+				suppressed := alertOutcomes(metrics.AlertModeClassic, testconst.TestNotificationName, metrics.AlertOutcomeSuppressedResendWindow)
 				conditions = getConditions(1, 1, 30, 30, 30)
 				err := webhookReceiverHandler.processAlert(testAlert, testNotifRetriever, true)
 				Expect(err).ShouldNot(HaveOccurred())
+				Expect(alertOutcomes(metrics.AlertModeClassic, testconst.TestNotificationName, metrics.AlertOutcomeSuppressedResendWindow)).To(Equal(suppressed + 1))
 				Expect(len(updatedConditions)).To(Equal(1))
 				assertConditions(updatedConditions[0], 1, 1, 30, 0, 30)
+				Expect(fakeRecorder.Events).To(Receive(HavePrefix("Normal " + EventReasonNotificationSuppressedResendWindow)))
 			})
 			It("Should not send a service log when receiving a firing alert within the resend time window even if the alert was marked as resolved", func() {
 				conditions = getConditions(0, 1, 30, 30, 30)
@@ -463,6 +574,16 @@ var _ = Describe("Webhook Handlers", func() {
 				assertConditions(updatedConditions[0], 1, 1, 90, 0, 90)
 				assertConditions(updatedConditions[1], 1, 1, 90, 0, 0)
 			})
+			It("Should evaluate the resend time window with the clock of the receiver", func() {
+				conditions = getConditions(1, 1, 90, 90, 30)
+				later := time.Now().Add(2 * time.Hour)
+				testNotifRetriever.now = func() time.Time { return later }
+				mockOCMClient.EXPECT().SendServiceLog(activeServiceLog).Return(nil)
+				err := webhookReceiverHandler.processAlert(testAlert, testNotifRetriever, true)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(len(updatedConditions)).To(Equal(2))
+				Expect(updatedConditions[1].GetCondition(ocmagentv1alpha1.ConditionServiceLogSent).LastTransitionTime.Time).To(BeTemporally("~", later, time.Second))
+			})
 			It("Should resend a service log only once even if 2 firing alerts are received", func() { // SREP-2079
 				conditions = getConditions(1, 1, 90, 5, 90)
 				mockOCMClient.EXPECT().SendServiceLog(activeServiceLog).Return(nil)
@@ -546,13 +667,27 @@ var _ = Describe("Webhook Handlers", func() {
 				assertConditions(updatedConditions[0], 0, 1, 0, 0, 5)
 			})
 			It("Should report an error if not able to send service log", func() {
+				failed := alertOutcomes(metrics.AlertModeClassic, testconst.TestNotificationName, metrics.AlertOutcomeFailed)
 				conditions = getConditions(0, 1, 90, 90, 90)
 				mockOCMClient.EXPECT().SendServiceLog(activeServiceLog).Return(k8serrs.NewInternalError(fmt.Errorf("a fake error")))
 				err := webhookReceiverHandler.processAlert(testAlert, testNotifRetriever, true)
 				Expect(err).Should(HaveOccurred())
+				Expect(alertOutcomes(metrics.AlertModeClassic, testconst.TestNotificationName, metrics.AlertOutcomeFailed)).To(Equal(failed + 1))
 				Expect(len(updatedConditions)).To(Equal(2))
 				assertConditions(updatedConditions[0], 1, 1, 0, 0, 90)
 				assertConditions(updatedConditions[1], 1, 0, 0, 0, 0)
+				Expect(fakeRecorder.Events).To(Receive(HavePrefix("Warning " + EventReasonNotificationFailed)))
+			})
+			It("Should set the ServiceLogSent condition again on the latest ManagedNotification after a conflict", func() {
+				conditions = getConditions(1, 1, 90, 5, 90)
+				updateErrors = []error{nil, k8serrs.NewConflict(ocmagentv1alpha1.GroupVersion.WithResource("managednotifications").GroupResource(), testconst.TestManagedNotification.Name, fmt.Errorf("a fake conflict"))}
+				mockOCMClient.EXPECT().SendServiceLog(activeServiceLog).Return(nil)
+				err := webhookReceiverHandler.processAlert(testAlert, testNotifRetriever, true)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(len(updatedConditions)).To(Equal(3))
+				assertConditions(updatedConditions[0], 1, 1, 90, 0, 90)
+				assertConditions(updatedConditions[1], 1, 1, 90, 0, 0)
+				assertConditions(updatedConditions[2], 1, 1, 90, 0, 0)
 			})
 			It("Should report an error if not able to update NotificationStatus", func() {
 				updatedConditionsError = k8serrs.NewInternalError(fmt.Errorf("a fake error"))
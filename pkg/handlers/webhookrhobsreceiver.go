@@ -15,21 +15,30 @@ import (
 
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	oav1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 
 	"github.com/openshift/ocm-agent/pkg/config"
-	"github.com/openshift/ocm-agent/pkg/consts"
+	"github.com/openshift/ocm-agent/pkg/logging"
 	"github.com/openshift/ocm-agent/pkg/metrics"
 	"github.com/openshift/ocm-agent/pkg/ocm"
+	"github.com/openshift/ocm-agent/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
+	// OCMAgentNamespaceName is the namespace the notification custom resources are read from by default
 	OCMAgentNamespaceName = "openshift-ocm-agent-operator"
+	// OCMEnvironmentAnnotation is the annotation of a ManagedFleetNotification naming the OCM environment its
+	// notifications are sent to, unless the alert has the ocm_environment label
+	OCMEnvironmentAnnotation = "ocmagent.managed.openshift.io/ocm-environment"
 )
 
 var (
@@ -54,15 +63,54 @@ var (
 type WebhookRHOBSReceiverHandler struct {
 	c   client.Client
 	ocm ocm.OCMClient
+	// environments are the clients of the named OCM environments, ocm being the one of the default environment
+	environments map[string]ocm.OCMClient
+	recorder     record.EventRecorder
+	namespaces   []string
+	now          func() time.Time
 }
 
-func NewWebhookRHOBSReceiverHandler(c client.Client, o ocm.OCMClient) *WebhookRHOBSReceiverHandler {
+func NewWebhookRHOBSReceiverHandler(c client.Client, o ocm.OCMClient, recorder record.EventRecorder) *WebhookRHOBSReceiverHandler {
 	return &WebhookRHOBSReceiverHandler{
-		c:   c,
-		ocm: o,
+		c:        c,
+		ocm:      o,
+		recorder: recorder,
 	}
 }
 
+// WithNamespaces sets the namespaces the ManagedFleetNotifications are read from. When a notification is defined
+// in several of them, the one of the first namespace is used, and its records are kept in the same namespace.
+func (h *WebhookRHOBSReceiverHandler) WithNamespaces(namespaces []string) *WebhookRHOBSReceiverHandler {
+	h.namespaces = namespaces
+	return h
+}
+
+// WithEnvironments sets the clients of the named OCM environments the notifications can be sent to besides the
+// default one. The environment of an alert is named by its ocm_environment label, or else by the
+// OCMEnvironmentAnnotation of its ManagedFleetNotification.
+func (h *WebhookRHOBSReceiverHandler) WithEnvironments(environments map[string]ocm.OCMClient) *WebhookRHOBSReceiverHandler {
+	h.environments = environments
+	return h
+}
+
+// WithClock sets the function the receiver gets the current time from, which the resend windows and the rate
+// limit backoffs are based on. This allows to replay recorded alerts with a simulated clock.
+func (h *WebhookRHOBSReceiverHandler) WithClock(now func() time.Time) *WebhookRHOBSReceiverHandler {
+	h.now = now
+	return h
+}
+
+// ocmClient returns the client of the named OCM environment, the default one for an empty name
+func (h *WebhookRHOBSReceiverHandler) ocmClient(environment string) (ocm.OCMClient, error) {
+	if environment == metrics.OCMEnvironmentDefault {
+		return h.ocm, nil
+	}
+	if o, ok := h.environments[environment]; ok {
+		return o, nil
+	}
+	return nil, fmt.Errorf("unknown OCM environment '%s'", environment)
+}
+
 func (h *WebhookRHOBSReceiverHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// validate request
 	if r != nil && r.Method != http.MethodPost {
@@ -70,48 +118,47 @@ func (h *WebhookRHOBSReceiverHandler) ServeHTTP(w http.ResponseWriter, r *http.R
 		return
 	}
 	var err error
-	var alertData AMReceiverData
+	var alertData AMReceiverMessage
 	err = json.NewDecoder(r.Body).Decode(&alertData)
 	if err != nil {
-		log.Errorf("Failed to process request body: %s\n", err)
+		packageLogger.FromContext(r.Context()).Errorf("Failed to process request body: %s\n", err)
 		http.Error(w, "Bad request body", http.StatusBadRequest)
-		metrics.SetRequestMetricFailure(consts.WebhookReceiverPath)
 		return
 	}
 
 	// process request
-	response := h.processAMReceiver(alertData, r.Context())
+	ctx := logging.WithFields(r.Context(), log.Fields{logging.FieldGroupKey: alertData.GroupKey})
+	start := time.Now()
+	response := h.processAMReceiver(alertData.AMReceiverData, ctx)
+	metrics.ObserveWebhookProcessing(metrics.AlertModeFleet, time.Since(start))
 
 	// write response
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(response.Code)
 	err = json.NewEncoder(w).Encode(response)
 	if err != nil {
-		log.Errorf("Failed to write to response: %s\n", err)
+		packageLogger.FromContext(r.Context()).Errorf("Failed to write to response: %s\n", err)
 		http.Error(w, "Failed to write to response", http.StatusInternalServerError)
-		metrics.SetRequestMetricFailure(consts.WebhookReceiverPath)
 		return
 	}
-
-	metrics.ResetMetric(metrics.MetricRequestFailure)
 }
 
 func (h *WebhookRHOBSReceiverHandler) processAMReceiver(d AMReceiverData, ctx context.Context) *AMReceiverResponse {
-	log.WithField("AMReceiverData", fmt.Sprintf("%+v", d)).Info("Process alert data")
+	packageLogger.FromContext(ctx).WithField("AMReceiverData", fmt.Sprintf("%+v", d)).Info("Process alert data")
 
 	// Handle each firing alert
 	for _, alert := range d.Alerts.Firing() {
-		err := h.processAlert(alert, true)
+		err := h.processAlert(ctx, alert, true)
 		if err != nil {
-			log.WithError(err).Error("a firing alert could not be successfully processed")
+			packageLogger.FromContext(ctx).WithError(err).Error("a firing alert could not be successfully processed")
 		}
 	}
 
 	// Handle resolved alerts
 	for _, alert := range d.Alerts.Resolved() {
-		err := h.processAlert(alert, false)
+		err := h.processAlert(ctx, alert, false)
 		if err != nil {
-			log.WithError(err).Error("a resolved alert could not be successfully processed")
+			packageLogger.FromContext(ctx).WithError(err).Error("a resolved alert could not be successfully processed")
 		}
 	}
 
@@ -119,38 +166,72 @@ func (h *WebhookRHOBSReceiverHandler) processAMReceiver(d AMReceiverData, ctx co
 }
 
 type fleetNotificationRetriever struct {
-	ctx                 context.Context
-	kubeCli             client.Client
-	fleetNotification   *oav1alpha1.FleetNotification
+	ctx               context.Context
+	kubeCli           client.Client
+	fleetNotification *oav1alpha1.FleetNotification
+	// namespace is the namespace of the ManagedFleetNotification, where its records are kept
+	namespace           string
 	managementClusterID string
 	hostedClusterID     string
+	// environment is the name of the OCM environment the notification is sent to, empty for the default one
+	environment string
+	now         func() time.Time
 }
 
-func newFleetNotificationRetriever(kubeCli client.Client, ctx context.Context, alert template.Alert) (*fleetNotificationRetriever, error) {
+// newFleetNotificationRetriever gets the ManagedFleetNotification of the alert from the first of the namespaces
+// defining it
+func newFleetNotificationRetriever(kubeCli client.Client, ctx context.Context, alert template.Alert, namespaces ...string) (*fleetNotificationRetriever, error) {
 	managedFleetNotificationName := alert.Labels[AMLabelTemplateName]
 	managedFleetNotification := &oav1alpha1.ManagedFleetNotification{}
-	err := kubeCli.Get(ctx, client.ObjectKey{
-		Namespace: OCMAgentNamespaceName,
-		Name:      managedFleetNotificationName,
-	}, managedFleetNotification)
+	var err error
+	for _, namespace := range namespacesOrDefault(namespaces) {
+		err = kubeCli.Get(ctx, client.ObjectKey{
+			Namespace: namespace,
+			Name:      managedFleetNotificationName,
+		}, managedFleetNotification)
+		if !errors.IsNotFound(err) {
+			break
+		}
+	}
 	if err != nil {
-		log.WithError(err).Error("unable to locate corresponding notification template")
+		packageLogger.FromContext(ctx).WithError(err).Error("unable to locate corresponding notification template")
 		return nil, err
 	}
 
+	namespace := managedFleetNotification.Namespace
+	if namespace == "" {
+		namespace = namespacesOrDefault(namespaces)[0]
+	}
+
+	// The label of the alert takes precedence, as the same notification may fire for hosted clusters of several environments
+	environment := alert.Labels[AMLabelOCMEnvironment]
+	if environment == "" {
+		environment = managedFleetNotification.Annotations[OCMEnvironmentAnnotation]
+	}
+
 	return &fleetNotificationRetriever{
 		ctx:                 ctx,
 		kubeCli:             kubeCli,
 		fleetNotification:   &managedFleetNotification.Spec.FleetNotification,
+		namespace:           namespace,
 		managementClusterID: alert.Labels[AMLabelAlertMCID],
 		hostedClusterID:     alert.Labels[AMLabelAlertHCID],
+		environment:         environment,
+		now:                 time.Now,
 	}, nil
 }
 
+// withContext returns a copy of the retriever making its calls with ctx.
+func (r *fleetNotificationRetriever) withContext(ctx context.Context) *fleetNotificationRetriever {
+	withContext := *r
+	withContext.ctx = ctx
+	return &withContext
+}
+
 func (r *fleetNotificationRetriever) retrieveFleetNotificationContext() (*fleetNotificationContext, error) {
 	managedFleetNotificationRecord := &oav1alpha1.ManagedFleetNotificationRecord{}
 	err := r.kubeCli.Get(r.ctx, client.ObjectKey{
-		Namespace: OCMAgentNamespaceName,
+		Namespace: r.namespace,
 		Name:      r.managementClusterID,
 	}, managedFleetNotificationRecord)
 
@@ -160,7 +241,7 @@ func (r *fleetNotificationRetriever) retrieveFleetNotificationContext() (*fleetN
 			managedFleetNotificationRecord = &oav1alpha1.ManagedFleetNotificationRecord{
 				ObjectMeta: v1.ObjectMeta{
 					Name:      r.managementClusterID,
-					Namespace: OCMAgentNamespaceName,
+					Namespace: r.namespace,
 				},
 			}
 			err = r.kubeCli.Create(r.ctx, managedFleetNotificationRecord)
@@ -223,11 +304,11 @@ type fleetNotificationContext struct {
 }
 
 func (c *fleetNotificationContext) canSendNotification() bool {
-	nowTime := time.Now()
+	nowTime := c.retriever.now()
 
 	// Cluster already in limited support -> nothing to do
 	if c.wasClusterInLimitedSupport {
-		log.WithFields(log.Fields{"notification": c.retriever.fleetNotification.Name}).Info("not sending a limited support notification as the previous one didn't resolve yet")
+		packageLogger.FromContext(c.retriever.ctx).WithFields(log.Fields{"notification": c.retriever.fleetNotification.Name}).Info("not sending a limited support notification as the previous one didn't resolve yet")
 		return false
 	}
 
@@ -265,7 +346,7 @@ func (c *fleetNotificationContext) inPlaceStatusUpdate() error {
 
 	err := c.retriever.kubeCli.Status().Update(c.retriever.ctx, c.managedFleetNotificationRecord)
 	if err != nil {
-		log.WithFields(log.Fields{LogFieldNotificationRecordName: c.managedFleetNotificationRecord.Name}).Infof("update of managedfleetnotificationrecord failed: %s", err.Error())
+		packageLogger.FromContext(c.retriever.ctx).WithFields(log.Fields{LogFieldNotificationRecordName: c.managedFleetNotificationRecord.Name}).Infof("update of managedfleetnotificationrecord failed: %s", err.Error())
 		return err
 	}
 	return nil
@@ -275,7 +356,7 @@ func (c *fleetNotificationContext) updateNotificationStatus(isCurrentlyFiring, c
 	if isCurrentlyFiring {
 		if canSendNotification {
 			c.notificationRecordItem.FiringNotificationSentCount++
-			c.notificationRecordItem.LastTransitionTime = &v1.Time{Time: time.Now()}
+			c.notificationRecordItem.LastTransitionTime = &v1.Time{Time: c.retriever.now()}
 		}
 	} else if c.retriever.fleetNotification.LimitedSupport {
 		c.notificationRecordItem.ResolvedNotificationSentCount = c.notificationRecordItem.FiringNotificationSentCount
@@ -310,12 +391,21 @@ func (c *fleetNotificationContext) restoreNotificationStatus() error {
 	return nil
 }
 
+// auditContext returns the audit context of the OCM calls made for the notification record.
+func (c *fleetNotificationContext) auditContext() ocm.AuditContext {
+	return ocm.AuditContext{
+		Caller: AuditCallerWebhookReceiver,
+		Object: auditObjectReference("ManagedFleetNotificationRecord", c.managedFleetNotificationRecord),
+	}
+}
+
 func (c *fleetNotificationContext) sendNotification(ocmCli ocm.OCMClient, alert template.Alert) error {
 	fleetNotification := c.retriever.fleetNotification
 	hostedClusterID := c.retriever.hostedClusterID
+	ocmCli = ocm.WithContext(ocm.WithAuditContext(ocmCli, c.auditContext()), c.retriever.ctx)
 
 	if fleetNotification.LimitedSupport { // Limited support case
-		log.WithFields(log.Fields{LogFieldNotificationName: fleetNotification.Name}).Info("will send limited support for notification")
+		packageLogger.FromContext(c.retriever.ctx).WithFields(log.Fields{LogFieldNotificationName: fleetNotification.Name}).Info("will send limited support for notification")
 		builder := &cmv1.LimitedSupportReasonBuilder{}
 		builder.Summary(fleetNotification.Summary)
 		builder.Details(fleetNotification.NotificationMessage)
@@ -330,12 +420,12 @@ func (c *fleetNotificationContext) sendNotification(ocmCli ocm.OCMClient, alert
 			return fmt.Errorf("limited support reason for fleetnotification '%s' could not be set for cluster %s, err: %w", fleetNotification.Name, hostedClusterID, err)
 		}
 	} else { // Service log case
-		log.WithFields(log.Fields{LogFieldNotificationName: fleetNotification.Name}).Info("will send servicelog for notification")
+		packageLogger.FromContext(c.retriever.ctx).WithFields(log.Fields{LogFieldNotificationName: fleetNotification.Name}).Info("will send servicelog for notification")
 		err := ocm.BuildAndSendServiceLog(
 			ocm.NewServiceLogBuilder(fleetNotification.Summary, fleetNotification.NotificationMessage, "", hostedClusterID, fleetNotification.Severity, fleetNotification.LogType, fleetNotification.References),
 			true, &alert, ocmCli)
 		if err != nil {
-			log.WithError(err).WithFields(log.Fields{LogFieldNotificationName: fleetNotification.Name, LogFieldIsFiring: true}).Error("unable to send service log for notification")
+			packageLogger.FromContext(c.retriever.ctx).WithError(err).WithFields(log.Fields{LogFieldNotificationName: fleetNotification.Name, LogFieldIsFiring: true}).Error("unable to send service log for notification")
 
 			return err
 		}
@@ -346,6 +436,7 @@ func (c *fleetNotificationContext) sendNotification(ocmCli ocm.OCMClient, alert
 
 func (c *fleetNotificationContext) removeLimitedSupport(ocmCli ocm.OCMClient) error {
 	hostedClusterID := c.retriever.hostedClusterID
+	ocmCli = ocm.WithContext(ocm.WithAuditContext(ocmCli, c.auditContext()), c.retriever.ctx)
 
 	limitedSupportReasons, err := ocmCli.GetLimitedSupportReasons(hostedClusterID)
 	if err != nil {
@@ -361,7 +452,7 @@ func (c *fleetNotificationContext) removeLimitedSupport(ocmCli ocm.OCMClient) er
 		// 1. removing limited support reasons potentially not created by OCM Agent.
 		// 2. do some kind of string matching which is prone to errors if the message format changes.
 		if strings.Contains(limitedSupportReason.Details(), fleetNotification.NotificationMessage) {
-			log.WithFields(log.Fields{LogFieldNotificationName: fleetNotification.Name}).Infof("will remove limited support reason '%s' for notification", limitedSupportReason.ID())
+			packageLogger.FromContext(c.retriever.ctx).WithFields(log.Fields{LogFieldNotificationName: fleetNotification.Name}).Infof("will remove limited support reason '%s' for notification", limitedSupportReason.ID())
 			err := ocmCli.RemoveLimitedSupport(hostedClusterID, limitedSupportReason.ID())
 			if err != nil {
 				return fmt.Errorf("limited support reason with ID '%s' couldn't be removed for cluster %s, err: %w", limitedSupportReason.ID(), hostedClusterID, err)
@@ -372,17 +463,50 @@ func (c *fleetNotificationContext) removeLimitedSupport(ocmCli ocm.OCMClient) er
 	return nil
 }
 
-func (h *WebhookRHOBSReceiverHandler) processAlert(alert template.Alert, isCurrentlyFiring bool) error {
+func (h *WebhookRHOBSReceiverHandler) processAlert(ctx context.Context, alert template.Alert, isCurrentlyFiring bool) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "process alert", trace.WithAttributes(
+		attribute.String(TraceAttributeAlertName, alert.Labels[AMLabelAlertName]),
+		attribute.String(TraceAttributeNotificationName, alert.Labels[AMLabelTemplateName]),
+		attribute.String(TraceAttributeHostedClusterID, alert.Labels[AMLabelAlertHCID]),
+		attribute.Bool(TraceAttributeIsFiring, isCurrentlyFiring),
+	))
+	defer func() { tracing.EndSpan(span, err) }()
+	start := time.Now()
+	notificationName := alert.Labels[AMLabelTemplateName]
+	outcome := metrics.AlertOutcomeFailed
+	defer func() {
+		metrics.ObserveAlertProcessing(metrics.AlertModeFleet, notificationName, outcome, time.Since(start))
+	}()
+	ctx = logging.WithFields(ctx, log.Fields{
+		logging.FieldNotification:        alert.Labels[AMLabelTemplateName],
+		logging.FieldManagementClusterID: alert.Labels[AMLabelAlertMCID],
+		logging.FieldHostedClusterID:     alert.Labels[AMLabelAlertHCID],
+	})
+	logger := packageLogger.FromContext(ctx)
+
 	// Filter actionable alert based on Label
 	if !isValidAlert(alert, true) {
-		log.WithField(LogFieldAlert, fmt.Sprintf("%+v", alert)).Info("alert does not meet valid criteria")
+		logger.WithField(LogFieldAlert, fmt.Sprintf("%+v", alert)).Info("alert does not meet valid criteria")
+		// Don't label the metrics with templates which don't exist
+		notificationName, outcome = "", metrics.AlertOutcomeInvalidAlert
 		return fmt.Errorf("alert does not meet valid criteria")
 	}
 
-	fleetNotificationRetriever, err := newFleetNotificationRetriever(h.c, context.Background(), alert)
+	fleetNotificationRetriever, err := newFleetNotificationRetriever(h.c, ctx, alert, h.namespaces...)
 	if err != nil {
+		if errors.IsNotFound(err) {
+			notificationName, outcome = "", metrics.AlertOutcomeNoTemplate
+		}
 		return fmt.Errorf("unable to find ManagedFleetNotification %s", alert.Labels[AMLabelTemplateName])
 	}
+	now := clockOrDefault(h.now)
+	fleetNotificationRetriever.now = now
+
+	ocmClient, err := h.ocmClient(fleetNotificationRetriever.environment)
+	if err != nil {
+		outcome = metrics.AlertOutcomeUnknownEnvironment
+		return err
+	}
 
 	// When an alert resolves, clear any rate-limit backoff entry so that
 	// if the alert fires again later, the send is not suppressed.
@@ -393,6 +517,7 @@ func (h *WebhookRHOBSReceiverHandler) processAlert(alert template.Alert, isCurre
 
 	if !fleetNotificationRetriever.fleetNotification.LimitedSupport && !isCurrentlyFiring {
 		metrics.ResetResponseMetricFailure(config.ServiceLogService, fleetNotificationRetriever.fleetNotification.Name, alert.Labels[AMLabelAlertName])
+		outcome = metrics.AlertOutcomeNothingToDo
 		return nil
 	}
 
@@ -400,10 +525,12 @@ func (h *WebhookRHOBSReceiverHandler) processAlert(alert template.Alert, isCurre
 	if isCurrentlyFiring {
 		rateLimitKey := alert.Labels[AMLabelTemplateName] + ":" + alert.Labels[AMLabelAlertHCID]
 		if backoffTime, ok := rateLimitBackoffs.Load(rateLimitKey); ok {
-			if time.Since(backoffTime.(time.Time)) < rateLimitRetryInterval {
-				log.WithFields(log.Fields{
+			if now().Sub(backoffTime.(time.Time)) < rateLimitRetryInterval {
+				logger.WithFields(log.Fields{
 					LogFieldNotificationName: alert.Labels[AMLabelTemplateName],
 				}).Warn("skipping alert due to OCM API rate-limit backoff")
+				h.recordRateLimitSuppression(fleetNotificationRetriever, backoffTime.(time.Time))
+				outcome = metrics.AlertOutcomeSuppressedRateLimit
 				return nil
 			}
 		}
@@ -411,8 +538,10 @@ func (h *WebhookRHOBSReceiverHandler) processAlert(alert template.Alert, isCurre
 
 	var c *fleetNotificationContext
 	canSend := false
-	err = retryOnConflictOrAlreadyExists(retryConfig, func() error {
-		c, err = fleetNotificationRetriever.retrieveFleetNotificationContext()
+	err = tracing.Retry(ctx, "retry ManagedFleetNotificationRecord status update", func(fn func() error) error {
+		return retryOnConflictOrAlreadyExists(retryConfig, fn)
+	}, func(ctx context.Context) error {
+		c, err = fleetNotificationRetriever.withContext(ctx).retrieveFleetNotificationContext()
 		if err != nil {
 			return err
 		}
@@ -426,14 +555,16 @@ func (h *WebhookRHOBSReceiverHandler) processAlert(alert template.Alert, isCurre
 	if err != nil {
 		return err
 	}
+	// The notification is sent within the span of the alert, the one of the retries has ended
+	c.retriever = fleetNotificationRetriever
 
 	fleetNotification := c.retriever.fleetNotification
 	alertName := alert.Labels[AMLabelAlertName]
 
 	if isCurrentlyFiring {
 		if canSend {
-			sendStartTime := time.Now()
-			err := c.sendNotification(h.ocm, alert)
+			sendStartTime := now()
+			err := c.sendNotification(ocmClient, alert)
 
 			var logService string
 			if fleetNotification.LimitedSupport { // Limited support case
@@ -446,8 +577,8 @@ func (h *WebhookRHOBSReceiverHandler) processAlert(alert template.Alert, isCurre
 				var rateLimitErr *ocm.RateLimitError
 				if stderrors.As(err, &rateLimitErr) {
 					rateLimitKey := alert.Labels[AMLabelTemplateName] + ":" + alert.Labels[AMLabelAlertHCID]
-					rateLimitBackoffs.Store(rateLimitKey, time.Now())
-					log.WithFields(log.Fields{
+					rateLimitBackoffs.Store(rateLimitKey, now())
+					logger.WithFields(log.Fields{
 						LogFieldNotificationName: fleetNotification.Name,
 					}).Warn("OCM API rate limit hit (HTTP 429), backing off for 30 minutes")
 				}
@@ -457,6 +588,9 @@ func (h *WebhookRHOBSReceiverHandler) processAlert(alert template.Alert, isCurre
 					metrics.CountFailedServiceLogs(fleetNotification.Name)
 				}
 				metrics.SetResponseMetricFailure(logService, fleetNotification.Name, alertName)
+				metrics.RecordFleetClusterNotification(fleetNotification.Name, c.retriever.hostedClusterID, true)
+				recordEvent(h.recorder, c.managedFleetNotificationRecord, corev1.EventTypeWarning, EventReasonNotificationFailed,
+					"%s for notification %s could not be sent to cluster %s: %v", c.notificationKind(), fleetNotification.Name, c.retriever.hostedClusterID, err)
 				_ = c.restoreNotificationStatus()
 				return err
 			}
@@ -478,7 +612,20 @@ func (h *WebhookRHOBSReceiverHandler) processAlert(alert template.Alert, isCurre
 				metrics.CountServiceLogSent(fleetNotification.Name, "firing")
 			}
 			metrics.ResetResponseMetricFailure(logService, fleetNotification.Name, alertName)
+			metrics.RecordFleetClusterNotification(fleetNotification.Name, c.retriever.hostedClusterID, false)
+			recordEvent(h.recorder, c.managedFleetNotificationRecord, corev1.EventTypeNormal, EventReasonNotificationSent,
+				"%s for notification %s sent to cluster %s", c.notificationKind(), fleetNotification.Name, c.retriever.hostedClusterID)
+			outcome = metrics.AlertOutcomeSent
 		} else {
+			if c.wasClusterInLimitedSupport {
+				recordEvent(h.recorder, c.managedFleetNotificationRecord, corev1.EventTypeNormal, EventReasonNotificationSuppressedLimitedSupport,
+					"Limited support for notification %s not sent to cluster %s: the previous limited support reason is still active", fleetNotification.Name, c.retriever.hostedClusterID)
+				outcome = metrics.AlertOutcomeSuppressedLimitedSupport
+			} else {
+				recordEvent(h.recorder, c.managedFleetNotificationRecord, corev1.EventTypeNormal, EventReasonNotificationSuppressedResendWindow,
+					"%s for notification %s not sent to cluster %s: one was already sent within the resend window", c.notificationKind(), fleetNotification.Name, c.retriever.hostedClusterID)
+				outcome = metrics.AlertOutcomeSuppressedResendWindow
+			}
 			var logService string
 			if fleetNotification.LimitedSupport {
 				logService = config.ClustersService
@@ -489,22 +636,58 @@ func (h *WebhookRHOBSReceiverHandler) processAlert(alert template.Alert, isCurre
 		}
 	} else {
 		if c.wasClusterInLimitedSupport {
-			err := c.removeLimitedSupport(h.ocm)
+			err := c.removeLimitedSupport(ocmClient)
 
 			if err != nil {
 				metrics.IncrementFailedLimitedSupportRemoved(fleetNotification.Name)
 				metrics.SetResponseMetricFailure(config.ClustersService, fleetNotification.Name, alertName)
+				metrics.RecordFleetClusterNotification(fleetNotification.Name, c.retriever.hostedClusterID, true)
+				recordEvent(h.recorder, c.managedFleetNotificationRecord, corev1.EventTypeWarning, EventReasonNotificationFailed,
+					"Limited support for notification %s could not be removed from cluster %s: %v", fleetNotification.Name, c.retriever.hostedClusterID, err)
 				_ = c.restoreNotificationStatus()
 				return err
 			}
 			metrics.IncrementLimitedSupportRemovedCount(fleetNotification.Name)
 			metrics.ResetResponseMetricFailure(config.ClustersService, fleetNotification.Name, alertName)
+			metrics.RecordFleetClusterNotification(fleetNotification.Name, c.retriever.hostedClusterID, false)
+			recordEvent(h.recorder, c.managedFleetNotificationRecord, corev1.EventTypeNormal, EventReasonNotificationSent,
+				"Limited support for resolved notification %s removed from cluster %s", fleetNotification.Name, c.retriever.hostedClusterID)
+			outcome = metrics.AlertOutcomeRemoved
+		} else {
+			outcome = metrics.AlertOutcomeNothingToDo
 		}
 	}
 
 	return nil
 }
 
+// recordRateLimitSuppression records an Event on the ManagedFleetNotificationRecord of the management cluster
+// when a notification is not sent because of an OCM API rate-limit backoff.
+func (h *WebhookRHOBSReceiverHandler) recordRateLimitSuppression(r *fleetNotificationRetriever, backoffTime time.Time) {
+	if h.recorder == nil {
+		return
+	}
+
+	managedFleetNotificationRecord := &oav1alpha1.ManagedFleetNotificationRecord{}
+	err := h.c.Get(r.ctx, client.ObjectKey{Namespace: r.namespace, Name: r.managementClusterID}, managedFleetNotificationRecord)
+	if err != nil {
+		packageLogger.FromContext(r.ctx).WithError(err).WithField(LogFieldNotificationName, r.fleetNotification.Name).Debug("unable to get ManagedFleetNotificationRecord to record rate-limit event")
+		return
+	}
+
+	recordEvent(h.recorder, managedFleetNotificationRecord, corev1.EventTypeNormal, EventReasonNotificationSuppressedRateLimit,
+		"Notification %s not sent to cluster %s: OCM API rate-limit backoff until %s", r.fleetNotification.Name, r.hostedClusterID,
+		backoffTime.Add(rateLimitRetryInterval).UTC().Format(time.RFC3339))
+}
+
+// notificationKind describes what is sent for the fleet notification in Event messages.
+func (c *fleetNotificationContext) notificationKind() string {
+	if c.retriever.fleetNotification.LimitedSupport {
+		return "Limited support"
+	}
+	return "Service log"
+}
+
 // The upstream implementation of `RetryOnConflict`
 // calls `IsConflict` which doesn't handle `AlreadyExists` as a conflict error,
 // even though it is meant to be a subcategory of conflict.
@@ -24,9 +24,11 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/ghttp"
 
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	testconst "github.com/openshift/ocm-agent/pkg/consts/test"
+	"github.com/openshift/ocm-agent/pkg/metrics"
 	"github.com/openshift/ocm-agent/pkg/ocm"
 	webhookreceivermock "github.com/openshift/ocm-agent/pkg/ocm/mocks"
 	clientmocks "github.com/openshift/ocm-agent/pkg/util/test/generated/mocks/client"
@@ -112,17 +114,17 @@ var _ = Describe("Webhook Handlers", func() {
 		Context("Alert is invalid", func() {
 			It("Reports error if alert does not have alertname label", func() {
 				delete(testAlertFiring.Labels, "alertname")
-				err := testHandler.processAlert(testAlertFiring, true)
+				err := testHandler.processAlert(context.TODO(), testAlertFiring, true)
 				Expect(err).Should(HaveOccurred())
 			})
 			It("Reports error if alert does not have managed_notification_template label", func() {
 				delete(testAlertFiring.Labels, "managed_notification_template")
-				err := testHandler.processAlert(testAlertFiring, true)
+				err := testHandler.processAlert(context.TODO(), testAlertFiring, true)
 				Expect(err).Should(HaveOccurred())
 			})
 			It("Reports error if alert does not have send_managed_notification label", func() {
 				delete(testAlertResolved.Labels, "send_managed_notification")
-				err := testHandler.processAlert(testAlertResolved, false)
+				err := testHandler.processAlert(context.TODO(), testAlertResolved, false)
 				Expect(err).Should(HaveOccurred())
 			})
 		})
@@ -152,7 +154,7 @@ var _ = Describe("Webhook Handlers", func() {
 			It("Should report an error if there is no ManagedFleetNotification", func() {
 				managedFleetNotification = nil
 
-				err := testHandler.processAlert(testAlertFiring, true)
+				err := testHandler.processAlert(context.TODO(), testAlertFiring, true)
 				Expect(err).Should(HaveOccurred())
 			})
 
@@ -217,13 +219,13 @@ var _ = Describe("Webhook Handlers", func() {
 							// Send limited support
 							mockOCMClient.EXPECT().SendLimitedSupport(testconst.TestHostedClusterID, limitedSupportReason).Return(nil)
 
-							err := testHandler.processAlert(testAlertFiring, true)
+							err := testHandler.processAlert(context.TODO(), testAlertFiring, true)
 							Expect(err).ShouldNot(HaveOccurred())
 							Expect(len(updatedNotificationRecordItems)).To(Equal(1))
 							assertRecordItem(&updatedNotificationRecordItems[0], 1, 0, 0)
 						})
 						It("Does nothing when processing a resolving alert", func() {
-							err := testHandler.processAlert(testAlertResolved, false)
+							err := testHandler.processAlert(context.TODO(), testAlertResolved, false)
 							Expect(err).ShouldNot(HaveOccurred())
 							Expect(len(updatedNotificationRecordItems)).To(Equal(1))
 							assertRecordItem(&updatedNotificationRecordItems[0], 0, 0, -1)
@@ -234,7 +236,7 @@ var _ = Describe("Webhook Handlers", func() {
 							// Send service log
 							mockOCMClient.EXPECT().SendServiceLog(serviceLog).Return(nil)
 
-							err := testHandler.processAlert(testAlertFiring, true)
+							err := testHandler.processAlert(context.TODO(), testAlertFiring, true)
 							Expect(err).ShouldNot(HaveOccurred())
 							Expect(len(updatedNotificationRecordItems)).To(Equal(1))
 							assertRecordItem(&updatedNotificationRecordItems[0], 1, 0, 0)
@@ -254,7 +256,7 @@ var _ = Describe("Webhook Handlers", func() {
 								// Send limited support
 								mockOCMClient.EXPECT().SendLimitedSupport(testconst.TestHostedClusterID, limitedSupportReason).Return(nil)
 
-								err := testHandler.processAlert(testAlertFiring, true)
+								err := testHandler.processAlert(context.TODO(), testAlertFiring, true)
 								Expect(err).ShouldNot(HaveOccurred())
 								Expect(len(updatedNotificationRecordItems)).To(Equal(1))
 								assertRecordItem(&updatedNotificationRecordItems[0], 1, 0, 0)
@@ -263,7 +265,7 @@ var _ = Describe("Webhook Handlers", func() {
 								// Send limited support
 								mockOCMClient.EXPECT().SendLimitedSupport(testconst.TestHostedClusterID, limitedSupportReason).Return(nil)
 
-								err := testHandler.processAlert(testAlertFiring, true)
+								err := testHandler.processAlert(context.TODO(), testAlertFiring, true)
 								Expect(err).ShouldNot(HaveOccurred())
 								Expect(len(updatedNotificationRecordItems)).To(Equal(1))
 								assertRecordItem(&updatedNotificationRecordItems[0], 1, 0, 0)
@@ -274,7 +276,7 @@ var _ = Describe("Webhook Handlers", func() {
 								// Send limited support
 								mockOCMClient.EXPECT().SendLimitedSupport(testconst.TestHostedClusterID, limitedSupportReason).Return(nil)
 
-								err := testHandler.processAlert(testAlertFiring, true)
+								err := testHandler.processAlert(context.TODO(), testAlertFiring, true)
 								Expect(err).ShouldNot(HaveOccurred())
 								Expect(len(updatedNotificationRecordItems)).To(Equal(1))
 								assertRecordItem(&updatedNotificationRecordItems[0], 43, 42, 0)
@@ -285,7 +287,7 @@ var _ = Describe("Webhook Handlers", func() {
 								// Send limited support
 								mockOCMClient.EXPECT().SendLimitedSupport(testconst.TestHostedClusterID, limitedSupportReason).Return(errors.New("cannot be put in LS"))
 
-								err := testHandler.processAlert(testAlertFiring, true)
+								err := testHandler.processAlert(context.TODO(), testAlertFiring, true)
 								Expect(err).Should(HaveOccurred())
 								Expect(len(updatedNotificationRecordItems)).To(Equal(2))
 								assertRecordItem(&updatedNotificationRecordItems[0], 43, 42, 0)
@@ -296,7 +298,7 @@ var _ = Describe("Webhook Handlers", func() {
 
 								initItemInRecord(managedFleetNotificationRecord, 42, 42, 90)
 
-								err := testHandler.processAlert(testAlertFiring, true)
+								err := testHandler.processAlert(context.TODO(), testAlertFiring, true)
 								Expect(err).Should(HaveOccurred())
 								Expect(len(updatedNotificationRecordItems)).To(Equal(1))
 								assertRecordItem(&updatedNotificationRecordItems[0], 43, 42, 0)
@@ -306,7 +308,7 @@ var _ = Describe("Webhook Handlers", func() {
 
 								initItemInRecord(managedFleetNotificationRecord, 42, 42, 90)
 
-								err := testHandler.processAlert(testAlertFiring, true)
+								err := testHandler.processAlert(context.TODO(), testAlertFiring, true)
 								Expect(err).Should(HaveOccurred())
 								Expect(len(updatedNotificationRecordItems)).To(Equal(5))
 								for k := 0; k < 5; k++ {
@@ -316,7 +318,7 @@ var _ = Describe("Webhook Handlers", func() {
 							It("Does nothing when status ManagedFleetNotificationRecord counters are equal and inside the no-resend time window", func() {
 								initItemInRecord(managedFleetNotificationRecord, 42, 42, 30)
 
-								err := testHandler.processAlert(testAlertFiring, true)
+								err := testHandler.processAlert(context.TODO(), testAlertFiring, true)
 								Expect(err).ShouldNot(HaveOccurred())
 								Expect(len(updatedNotificationRecordItems)).To(Equal(1))
 								assertRecordItem(&updatedNotificationRecordItems[0], 42, 42, 30)
@@ -324,7 +326,7 @@ var _ = Describe("Webhook Handlers", func() {
 							It("Does nothing when the status ManagedFleetNotificationRecord firing counter is already bigger than the resolved counter", func() {
 								initItemInRecord(managedFleetNotificationRecord, 43, 42, 90)
 
-								err := testHandler.processAlert(testAlertFiring, true)
+								err := testHandler.processAlert(context.TODO(), testAlertFiring, true)
 								Expect(err).ShouldNot(HaveOccurred())
 								Expect(len(updatedNotificationRecordItems)).To(Equal(1))
 								assertRecordItem(&updatedNotificationRecordItems[0], 43, 42, 90)
@@ -342,9 +344,9 @@ var _ = Describe("Webhook Handlers", func() {
 										},
 									)
 
-									err := testHandler.processAlert(testAlertFiring, true)
+									err := testHandler.processAlert(context.TODO(), testAlertFiring, true)
 									Expect(err).ShouldNot(HaveOccurred())
-									err = testHandler.processAlert(testAlertFiring, true)
+									err = testHandler.processAlert(context.TODO(), testAlertFiring, true)
 									Expect(err).ShouldNot(HaveOccurred())
 									Expect(len(updatedNotificationRecordItems)).To(Equal(2))
 									assertRecordItem(&updatedNotificationRecordItems[0], 43, 42, 0)
@@ -356,7 +358,7 @@ var _ = Describe("Webhook Handlers", func() {
 							It("Does nothing when status ManagedFleetNotificationRecord counters are already equal", func() {
 								initItemInRecord(managedFleetNotificationRecord, 42, 42, 90)
 
-								err := testHandler.processAlert(testAlertResolved, false)
+								err := testHandler.processAlert(context.TODO(), testAlertResolved, false)
 								Expect(err).ShouldNot(HaveOccurred())
 								Expect(len(updatedNotificationRecordItems)).To(Equal(1))
 								assertRecordItem(&updatedNotificationRecordItems[0], 42, 42, 90)
@@ -371,7 +373,7 @@ var _ = Describe("Webhook Handlers", func() {
 									mockOCMClient.EXPECT().RemoveLimitedSupport(testconst.TestHostedClusterID, limitedSupportReason.ID()).Return(nil),
 								)
 
-								err := testHandler.processAlert(testAlertResolved, false)
+								err := testHandler.processAlert(context.TODO(), testAlertResolved, false)
 								Expect(err).ShouldNot(HaveOccurred())
 								Expect(len(updatedNotificationRecordItems)).To(Equal(1))
 								assertRecordItem(&updatedNotificationRecordItems[0], 43, 43, 0)
@@ -386,7 +388,7 @@ var _ = Describe("Webhook Handlers", func() {
 									mockOCMClient.EXPECT().RemoveLimitedSupport(testconst.TestHostedClusterID, limitedSupportReason.ID()).Return(errors.New("cannot be removed from LS")),
 								)
 
-								err := testHandler.processAlert(testAlertResolved, false)
+								err := testHandler.processAlert(context.TODO(), testAlertResolved, false)
 								Expect(err).Should(HaveOccurred())
 								Expect(len(updatedNotificationRecordItems)).To(Equal(2))
 								assertRecordItem(&updatedNotificationRecordItems[0], 43, 43, 90)
@@ -402,7 +404,7 @@ var _ = Describe("Webhook Handlers", func() {
 									mockOCMClient.EXPECT().RemoveLimitedSupport(testconst.TestHostedClusterID, limitedSupportReason.ID()).Return(nil),
 								)
 
-								err := testHandler.processAlert(testAlertResolved, false)
+								err := testHandler.processAlert(context.TODO(), testAlertResolved, false)
 								Expect(err).ShouldNot(HaveOccurred())
 								Expect(len(updatedNotificationRecordItems)).To(Equal(1))
 								assertRecordItem(&updatedNotificationRecordItems[0], 43, 43, 10)
@@ -418,7 +420,7 @@ var _ = Describe("Webhook Handlers", func() {
 								// Send service log
 								mockOCMClient.EXPECT().SendServiceLog(serviceLog).Return(nil)
 
-								err := testHandler.processAlert(testAlertFiring, true)
+								err := testHandler.processAlert(context.TODO(), testAlertFiring, true)
 								Expect(err).ShouldNot(HaveOccurred())
 								Expect(len(updatedNotificationRecordItems)).To(Equal(1))
 								assertRecordItem(&updatedNotificationRecordItems[0], 1, 0, 0)
@@ -429,7 +431,7 @@ var _ = Describe("Webhook Handlers", func() {
 								// Send service log
 								mockOCMClient.EXPECT().SendServiceLog(serviceLog).Return(nil)
 
-								err := testHandler.processAlert(testAlertFiring, true)
+								err := testHandler.processAlert(context.TODO(), testAlertFiring, true)
 								Expect(err).ShouldNot(HaveOccurred())
 								Expect(len(updatedNotificationRecordItems)).To(Equal(1))
 								assertRecordItem(&updatedNotificationRecordItems[0], 43, 0, 0)
@@ -440,7 +442,7 @@ var _ = Describe("Webhook Handlers", func() {
 								// Send service log
 								mockOCMClient.EXPECT().SendServiceLog(serviceLog).Return(errors.New("cannot send SL"))
 
-								err := testHandler.processAlert(testAlertFiring, true)
+								err := testHandler.processAlert(context.TODO(), testAlertFiring, true)
 								Expect(err).Should(HaveOccurred())
 								Expect(len(updatedNotificationRecordItems)).To(Equal(2))
 								assertRecordItem(&updatedNotificationRecordItems[0], 43, 0, 0)
@@ -449,7 +451,7 @@ var _ = Describe("Webhook Handlers", func() {
 							It("Does nothing when inside the no-resend time window", func() {
 								initItemInRecord(managedFleetNotificationRecord, 42, 0, 30)
 
-								err := testHandler.processAlert(testAlertFiring, true)
+								err := testHandler.processAlert(context.TODO(), testAlertFiring, true)
 								Expect(err).ShouldNot(HaveOccurred())
 								Expect(len(updatedNotificationRecordItems)).To(Equal(1))
 								assertRecordItem(&updatedNotificationRecordItems[0], 42, 0, 30)
@@ -467,9 +469,9 @@ var _ = Describe("Webhook Handlers", func() {
 										},
 									)
 
-									err := testHandler.processAlert(testAlertFiring, true)
+									err := testHandler.processAlert(context.TODO(), testAlertFiring, true)
 									Expect(err).ShouldNot(HaveOccurred())
-									err = testHandler.processAlert(testAlertFiring, true)
+									err = testHandler.processAlert(context.TODO(), testAlertFiring, true)
 									Expect(err).ShouldNot(HaveOccurred())
 									Expect(len(updatedNotificationRecordItems)).To(Equal(2))
 									assertRecordItem(&updatedNotificationRecordItems[0], 43, 0, 0)
@@ -481,7 +483,7 @@ var _ = Describe("Webhook Handlers", func() {
 							It("Does nothing", func() {
 								initItemInRecord(managedFleetNotificationRecord, 42, 0, 90)
 
-								err := testHandler.processAlert(testAlertResolved, false)
+								err := testHandler.processAlert(context.TODO(), testAlertResolved, false)
 								Expect(err).ShouldNot(HaveOccurred())
 								Expect(len(updatedNotificationRecordItems)).To(Equal(0))
 							})
@@ -507,7 +509,7 @@ var _ = Describe("Webhook Handlers Additional Tests", func() {
 		mockClient = clientmocks.NewMockClient(mockCtrl)
 		mockStatusWriter = clientmocks.NewMockStatusWriter(mockCtrl)
 		mockOCMClient = webhookreceivermock.NewMockOCMClient(mockCtrl)
-		testHandler = NewWebhookRHOBSReceiverHandler(mockClient, mockOCMClient)
+		testHandler = NewWebhookRHOBSReceiverHandler(mockClient, mockOCMClient, record.NewFakeRecorder(10))
 	})
 
 	AfterEach(func() {
@@ -683,6 +685,7 @@ var _ = Describe("Rate-limit backoff behavior", func() {
 		serviceLog                     *ocm.ServiceLog
 		managedFleetNotification       *ocmagentv1alpha1.ManagedFleetNotification
 		managedFleetNotificationRecord *ocmagentv1alpha1.ManagedFleetNotificationRecord
+		fakeRecorder                   *record.FakeRecorder
 	)
 
 	BeforeEach(func() {
@@ -692,9 +695,11 @@ var _ = Describe("Rate-limit backoff behavior", func() {
 		mockClient = clientmocks.NewMockClient(mockCtrl)
 		mockStatusWriter = clientmocks.NewMockStatusWriter(mockCtrl)
 		mockOCMClient = webhookreceivermock.NewMockOCMClient(mockCtrl)
+		fakeRecorder = record.NewFakeRecorder(10)
 		testHandler = &WebhookRHOBSReceiverHandler{
-			c:   mockClient,
-			ocm: mockOCMClient,
+			c:        mockClient,
+			ocm:      mockOCMClient,
+			recorder: fakeRecorder,
 		}
 		testAlertFiring = testconst.NewTestAlert(false, true)
 
@@ -745,21 +750,26 @@ var _ = Describe("Rate-limit backoff behavior", func() {
 		rateLimitErr := &ocm.RateLimitError{Err: fmt.Errorf("rate limited")}
 		mockOCMClient.EXPECT().SendServiceLog(serviceLog).Return(rateLimitErr)
 
-		err := testHandler.processAlert(testAlertFiring, true)
+		err := testHandler.processAlert(context.TODO(), testAlertFiring, true)
 		Expect(err).To(HaveOccurred())
 
 		key := testconst.TestNotificationName + ":" + testconst.TestHostedClusterID
 		_, ok := rateLimitBackoffs.Load(key)
 		Expect(ok).To(BeTrue())
+		Expect(fakeRecorder.Events).To(Receive(HavePrefix("Warning " + EventReasonNotificationFailed)))
 	})
 
 	It("skips sending when within the rate-limit backoff window", func() {
 		key := testconst.TestNotificationName + ":" + testconst.TestHostedClusterID
 		rateLimitBackoffs.Store(key, time.Now())
 
+		suppressed := alertOutcomes(metrics.AlertModeFleet, testconst.TestNotificationName, metrics.AlertOutcomeSuppressedRateLimit)
+
 		// SendServiceLog should NOT be called because the backoff guard returns early
-		err := testHandler.processAlert(testAlertFiring, true)
+		err := testHandler.processAlert(context.TODO(), testAlertFiring, true)
 		Expect(err).ShouldNot(HaveOccurred())
+		Expect(alertOutcomes(metrics.AlertModeFleet, testconst.TestNotificationName, metrics.AlertOutcomeSuppressedRateLimit)).To(Equal(suppressed + 1))
+		Expect(fakeRecorder.Events).To(Receive(HavePrefix("Normal " + EventReasonNotificationSuppressedRateLimit)))
 	})
 
 	It("proceeds normally after the backoff window expires", func() {
@@ -768,7 +778,7 @@ var _ = Describe("Rate-limit backoff behavior", func() {
 
 		mockOCMClient.EXPECT().SendServiceLog(serviceLog).Return(nil)
 
-		err := testHandler.processAlert(testAlertFiring, true)
+		err := testHandler.processAlert(context.TODO(), testAlertFiring, true)
 		Expect(err).ShouldNot(HaveOccurred())
 	})
 
@@ -778,11 +788,13 @@ var _ = Describe("Rate-limit backoff behavior", func() {
 
 		mockOCMClient.EXPECT().SendServiceLog(serviceLog).Return(nil)
 
-		err := testHandler.processAlert(testAlertFiring, true)
+		err := testHandler.processAlert(context.TODO(), testAlertFiring, true)
 		Expect(err).ShouldNot(HaveOccurred())
 
 		_, ok := rateLimitBackoffs.Load(key)
 		Expect(ok).To(BeFalse())
+		Expect(fakeRecorder.Events).To(Receive(Equal(fmt.Sprintf("Normal %s Service log for notification %s sent to cluster %s",
+			EventReasonNotificationSent, testconst.TestNotificationName, testconst.TestHostedClusterID))))
 	})
 
 	It("clears backoff entry when a resolved alert is received", func() {
@@ -790,7 +802,7 @@ var _ = Describe("Rate-limit backoff behavior", func() {
 		rateLimitBackoffs.Store(key, time.Now())
 
 		testAlertResolved := testconst.NewTestAlert(true, true)
-		err := testHandler.processAlert(testAlertResolved, false)
+		err := testHandler.processAlert(context.TODO(), testAlertResolved, false)
 		Expect(err).ShouldNot(HaveOccurred())
 
 		_, ok := rateLimitBackoffs.Load(key)
@@ -811,7 +823,7 @@ var _ = Describe("Rate-limit backoff behavior", func() {
 			},
 		)
 
-		err := testHandler.processAlert(testAlertFiring, true)
+		err := testHandler.processAlert(context.TODO(), testAlertFiring, true)
 		Expect(err).ShouldNot(HaveOccurred())
 
 		// The fresh backoff must survive the success-path cleanup.
@@ -819,3 +831,151 @@ var _ = Describe("Rate-limit backoff behavior", func() {
 		Expect(ok).To(BeTrue())
 	})
 })
+
+var _ = Describe("Webhook RHOBS receiver in several namespaces", func() {
+	const stagingNamespace = "ocm-agent-staging"
+
+	var (
+		mockCtrl                 *gomock.Controller
+		mockClient               *clientmocks.MockClient
+		managedFleetNotification ocmagentv1alpha1.ManagedFleetNotification
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = clientmocks.NewMockClient(mockCtrl)
+		managedFleetNotification = testconst.NewManagedFleetNotification(false)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("keeps the records in the namespace of the ManagedFleetNotification", func() {
+		gomock.InOrder(
+			mockClient.EXPECT().Get(gomock.Any(), client.ObjectKey{Namespace: stagingNamespace, Name: managedFleetNotification.Name}, gomock.Any()).
+				Return(kerrors.NewNotFound(schema.GroupResource{}, managedFleetNotification.Name)),
+			mockClient.EXPECT().Get(gomock.Any(), client.ObjectKey{Namespace: OCMAgentNamespaceName, Name: managedFleetNotification.Name}, gomock.Any()).
+				SetArg(2, managedFleetNotification).Return(nil),
+			mockClient.EXPECT().Get(gomock.Any(), client.ObjectKey{Namespace: OCMAgentNamespaceName, Name: testconst.TestManagedClusterID}, gomock.Any()).
+				Return(kerrors.NewNotFound(schema.GroupResource{}, testconst.TestManagedClusterID)),
+			mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+					assertRecordMetadata(obj.(*ocmagentv1alpha1.ManagedFleetNotificationRecord))
+					return nil
+				}),
+		)
+
+		retriever, err := newFleetNotificationRetriever(mockClient, context.TODO(), testconst.NewTestAlert(false, true), stagingNamespace, OCMAgentNamespaceName)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(retriever.namespace).To(Equal(OCMAgentNamespaceName))
+
+		_, err = retriever.retrieveFleetNotificationContext()
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	It("reports the ManagedFleetNotification missing from every namespace", func() {
+		mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(kerrors.NewNotFound(schema.GroupResource{}, managedFleetNotification.Name)).Times(2)
+
+		_, err := newFleetNotificationRetriever(mockClient, context.TODO(), testconst.NewTestAlert(false, true), stagingNamespace, OCMAgentNamespaceName)
+		Expect(kerrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("doesn't look further when a namespace can't be read", func() {
+		mockClient.EXPECT().Get(gomock.Any(), client.ObjectKey{Namespace: stagingNamespace, Name: managedFleetNotification.Name}, gomock.Any()).
+			Return(kerrors.NewForbidden(schema.GroupResource{}, managedFleetNotification.Name, errors.New("forbidden")))
+
+		_, err := newFleetNotificationRetriever(mockClient, context.TODO(), testconst.NewTestAlert(false, true), stagingNamespace, OCMAgentNamespaceName)
+		Expect(kerrors.IsForbidden(err)).To(BeTrue())
+	})
+})
+
+var _ = Describe("Webhook RHOBS receiver with several OCM environments", func() {
+	var (
+		mockCtrl                 *gomock.Controller
+		mockClient               *clientmocks.MockClient
+		mockStatusWriter         *clientmocks.MockStatusWriter
+		defaultOCMClient         *webhookreceivermock.MockOCMClient
+		stageOCMClient           *webhookreceivermock.MockOCMClient
+		integrationOCMClient     *webhookreceivermock.MockOCMClient
+		testHandler              *WebhookRHOBSReceiverHandler
+		testAlertFiring          template.Alert
+		managedFleetNotification ocmagentv1alpha1.ManagedFleetNotification
+	)
+
+	BeforeEach(func() {
+		clearRateLimitBackoffs()
+
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = clientmocks.NewMockClient(mockCtrl)
+		mockStatusWriter = clientmocks.NewMockStatusWriter(mockCtrl)
+		defaultOCMClient = webhookreceivermock.NewMockOCMClient(mockCtrl)
+		stageOCMClient = webhookreceivermock.NewMockOCMClient(mockCtrl)
+		integrationOCMClient = webhookreceivermock.NewMockOCMClient(mockCtrl)
+		testHandler = NewWebhookRHOBSReceiverHandler(mockClient, defaultOCMClient, nil).
+			WithEnvironments(map[string]ocm.OCMClient{"stage": stageOCMClient, "integration": integrationOCMClient})
+		testAlertFiring = testconst.NewTestAlert(false, true)
+		managedFleetNotification = testconst.NewManagedFleetNotification(false)
+
+		mockClient.EXPECT().Get(gomock.Any(), client.ObjectKey{Namespace: OCMAgentNamespaceName, Name: managedFleetNotification.Name}, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, key client.ObjectKey, res *ocmagentv1alpha1.ManagedFleetNotification, opts ...client.GetOption) error {
+				*res = managedFleetNotification
+				return nil
+			}).AnyTimes()
+	})
+
+	AfterEach(func() {
+		clearRateLimitBackoffs()
+		mockCtrl.Finish()
+	})
+
+	expectRecordUpdate := func() {
+		record := testconst.NewManagedFleetNotificationRecordWithStatus()
+		mockClient.EXPECT().Get(gomock.Any(), client.ObjectKey{Namespace: OCMAgentNamespaceName, Name: testconst.TestManagedClusterID}, gomock.Any()).
+			SetArg(2, record).Return(nil)
+		mockClient.EXPECT().Status().Return(mockStatusWriter)
+		mockStatusWriter.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+	}
+
+	It("sends the notification to the environment of the alert label", func() {
+		testAlertFiring.Labels[AMLabelOCMEnvironment] = "stage"
+		expectRecordUpdate()
+		stageOCMClient.EXPECT().SendServiceLog(gomock.Any()).Return(nil)
+
+		Expect(testHandler.processAlert(context.TODO(), testAlertFiring, true)).To(Succeed())
+	})
+
+	It("sends the notification to the environment of the ManagedFleetNotification annotation", func() {
+		managedFleetNotification.Annotations = map[string]string{OCMEnvironmentAnnotation: "stage"}
+		expectRecordUpdate()
+		stageOCMClient.EXPECT().SendServiceLog(gomock.Any()).Return(nil)
+
+		Expect(testHandler.processAlert(context.TODO(), testAlertFiring, true)).To(Succeed())
+	})
+
+	It("prefers the alert label over the ManagedFleetNotification annotation", func() {
+		managedFleetNotification.Annotations = map[string]string{OCMEnvironmentAnnotation: "integration"}
+		testAlertFiring.Labels[AMLabelOCMEnvironment] = "stage"
+		expectRecordUpdate()
+		stageOCMClient.EXPECT().SendServiceLog(gomock.Any()).Return(nil)
+
+		Expect(testHandler.processAlert(context.TODO(), testAlertFiring, true)).To(Succeed())
+	})
+
+	It("sends the notification to the default environment without label nor annotation", func() {
+		expectRecordUpdate()
+		defaultOCMClient.EXPECT().SendServiceLog(gomock.Any()).Return(nil)
+
+		Expect(testHandler.processAlert(context.TODO(), testAlertFiring, true)).To(Succeed())
+	})
+
+	It("fails alerts of an unknown environment without updating the record", func() {
+		testAlertFiring.Labels[AMLabelOCMEnvironment] = "production"
+		unknown := alertOutcomes(metrics.AlertModeFleet, testconst.TestNotificationName, metrics.AlertOutcomeUnknownEnvironment)
+
+		err := testHandler.processAlert(context.TODO(), testAlertFiring, true)
+		Expect(err).To(MatchError(ContainSubstring("unknown OCM environment 'production'")))
+		Expect(alertOutcomes(metrics.AlertModeFleet, testconst.TestNotificationName, metrics.AlertOutcomeUnknownEnvironment)).To(Equal(unknown + 1))
+	})
+})
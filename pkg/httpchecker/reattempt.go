@@ -4,7 +4,12 @@ import (
 	"math/rand"
 	"time"
 
-	log "github.com/sirupsen/logrus"
+	"github.com/openshift/ocm-agent/pkg/logging"
+)
+
+var (
+	// packageLogger logs at the level of the httpchecker package, which can be changed at runtime
+	packageLogger = logging.ForPackage(logging.PackageHTTPChecker)
 )
 
 func init() {
@@ -20,7 +25,7 @@ func Reattempt(attempts int, sleep time.Duration, f func() error) error {
 	if err := f(); err != nil {
 		if s, ok := err.(stop); ok {
 			// Log the original error for later checking
-			log.Errorf("connection check failed with error: %s", s.error)
+			packageLogger.Errorf("connection check failed with error: %s", s.error)
 		}
 
 		if attempts--; attempts > 0 {
@@ -3,6 +3,9 @@ package k8s
 import (
 	oav1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
 
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -21,14 +24,24 @@ func NewClient() (client.Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	scheme := runtime.NewScheme()
-	_ = addKnownTypes(scheme)
 	c, err := client.New(cfg, client.Options{
-		Scheme: scheme,
+		Scheme: newScheme(),
 	})
 	return c, err
 }
 
+// newScheme returns a scheme with the agent's custom resources and the Kubernetes types it uses
+func newScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	_ = addKnownTypes(scheme)
+	// TokenReview and SubjectAccessReview are used to authorize the callers of the proxy routes
+	_ = authenticationv1.AddToScheme(scheme)
+	_ = authorizationv1.AddToScheme(scheme)
+	// The pull secret is read when the cluster credentials are taken from the cluster
+	_ = corev1.AddToScheme(scheme)
+	return scheme
+}
+
 func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&oav1alpha1.OcmAgent{},
@@ -0,0 +1,80 @@
+package k8s
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// PullSecretNamespace and PullSecretName locate the global pull secret of the cluster
+	PullSecretNamespace = "openshift-config"
+	PullSecretName      = "pull-secret" //#nosec G101 -- This is a false positive
+	// PullSecretOCMRegistry is the registry of the pull secret whose auth authenticates the cluster to OCM
+	PullSecretOCMRegistry = "cloud.openshift.com"
+	// ClusterVersionName is the name of the ClusterVersion holding the cluster ID
+	ClusterVersionName = "version"
+)
+
+// ClusterVersionGVK is read unstructured, so that the OpenShift config API isn't needed for a single field
+var ClusterVersionGVK = schema.GroupVersionKind{Group: "config.openshift.io", Version: "v1", Kind: "ClusterVersion"}
+
+// ClusterCredentials are the credentials the cluster authenticates to OCM with in classic mode
+type ClusterCredentials struct {
+	ClusterID   string
+	AccessToken string
+}
+
+// GetClusterCredentials reads the cluster ID from the ClusterVersion and the access token from the
+// cloud.openshift.com auth of the pull secret of the cluster
+func GetClusterCredentials(ctx context.Context, c client.Reader) (ClusterCredentials, error) {
+	clusterVersion := &unstructured.Unstructured{}
+	clusterVersion.SetGroupVersionKind(ClusterVersionGVK)
+	if err := c.Get(ctx, types.NamespacedName{Name: ClusterVersionName}, clusterVersion); err != nil {
+		return ClusterCredentials{}, fmt.Errorf("can't get the ClusterVersion: %w", err)
+	}
+	clusterID, _, err := unstructured.NestedString(clusterVersion.Object, "spec", "clusterID")
+	if err != nil || clusterID == "" {
+		return ClusterCredentials{}, fmt.Errorf("the ClusterVersion has no cluster ID")
+	}
+
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: PullSecretNamespace, Name: PullSecretName}, secret); err != nil {
+		return ClusterCredentials{}, fmt.Errorf("can't get the pull secret: %w", err)
+	}
+	accessToken, err := pullSecretAuth(secret.Data[corev1.DockerConfigJsonKey], PullSecretOCMRegistry)
+	if err != nil {
+		return ClusterCredentials{}, err
+	}
+
+	return ClusterCredentials{ClusterID: clusterID, AccessToken: accessToken}, nil
+}
+
+// pullSecretAuth returns the auth of the registry in a .dockerconfigjson pull secret
+func pullSecretAuth(dockerConfigJSON []byte, registry string) (string, error) {
+	var dockerConfig struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(dockerConfigJSON, &dockerConfig); err != nil {
+		return "", fmt.Errorf("can't parse the pull secret: %w", err)
+	}
+	auth := strings.TrimSpace(dockerConfig.Auths[registry].Auth)
+	if auth == "" {
+		return "", fmt.Errorf("the pull secret has no auth for %s", registry)
+	}
+	// The auth is already base64 encoded, as OCM expects it
+	if _, err := base64.StdEncoding.DecodeString(auth); err != nil {
+		return "", fmt.Errorf("the auth of %s in the pull secret is not base64 encoded: %w", registry, err)
+	}
+	return auth, nil
+}
@@ -0,0 +1,66 @@
+package k8s
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clientmocks "github.com/openshift/ocm-agent/pkg/util/test/generated/mocks/client"
+)
+
+var _ = Describe("Cluster credentials", func() {
+	const clusterID = "bd845de4-5c16-4067-a868-15b02d55ccef"
+
+	var (
+		mockCtrl   *gomock.Controller
+		mockReader *clientmocks.MockReader
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockReader = clientmocks.NewMockReader(mockCtrl)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	expectClusterVersion := func() {
+		mockReader.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: ClusterVersionName}, gomock.AssignableToTypeOf(&unstructured.Unstructured{})).DoAndReturn(
+			func(ctx context.Context, key types.NamespacedName, obj client.Object, opts ...client.GetOption) error {
+				Expect(obj.GetObjectKind().GroupVersionKind()).To(Equal(ClusterVersionGVK))
+				return unstructured.SetNestedField(obj.(*unstructured.Unstructured).Object, clusterID, "spec", "clusterID")
+			})
+	}
+
+	expectPullSecret := func(dockerConfigJSON string) {
+		mockReader.EXPECT().Get(gomock.Any(), types.NamespacedName{Namespace: PullSecretNamespace, Name: PullSecretName}, gomock.AssignableToTypeOf(&corev1.Secret{})).DoAndReturn(
+			func(ctx context.Context, key types.NamespacedName, secret *corev1.Secret, opts ...client.GetOption) error {
+				secret.Data = map[string][]byte{corev1.DockerConfigJsonKey: []byte(dockerConfigJSON)}
+				return nil
+			})
+	}
+
+	It("reads the cluster ID and the cloud.openshift.com auth of the pull secret", func() {
+		expectClusterVersion()
+		expectPullSecret(`{"auths": {"quay.io": {"auth": "cXVheQ=="}, "cloud.openshift.com": {"auth": "b2NtLXRva2Vu"}}}`)
+
+		credentials, err := GetClusterCredentials(context.Background(), mockReader)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(credentials).To(Equal(ClusterCredentials{ClusterID: clusterID, AccessToken: "b2NtLXRva2Vu"}))
+	})
+
+	It("fails when the pull secret has no auth for OCM", func() {
+		expectClusterVersion()
+		expectPullSecret(`{"auths": {"quay.io": {"auth": "cXVheQ=="}}}`)
+
+		_, err := GetClusterCredentials(context.Background(), mockReader)
+		Expect(err).To(MatchError(ContainSubstring("no auth for cloud.openshift.com")))
+	})
+})
@@ -0,0 +1,29 @@
+package k8s
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// EventSourceComponent is the component recorded as the source of the Events posted by the agent
+const EventSourceComponent = "ocm-agent"
+
+// NewEventRecorder builds and returns a recorder posting Events about the agent's custom resources,
+// or error if the client can't be configured
+func NewEventRecorder() (record.EventRecorder, error) {
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: clientset.CoreV1().Events("")})
+	return broadcaster.NewRecorder(newScheme(), corev1.EventSource{Component: EventSourceComponent}), nil
+}
@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// HeaderCorrelationID is the header the correlation ID of a request is read from and returned in
+	HeaderCorrelationID = "X-Correlation-Id"
+
+	// Fields of the request-scoped logger
+	FieldCorrelationID       = "correlation_id"
+	FieldGroupKey            = "group_key"
+	FieldNotification        = "notification"
+	FieldClusterID           = "cluster_id"
+	FieldManagementClusterID = "management_cluster_id"
+	FieldHostedClusterID     = "hosted_cluster_id"
+)
+
+var (
+	// correlationIDRegex restricts the correlation IDs accepted from callers, to keep them out of the way of the log format
+	correlationIDRegex = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying entry as the request-scoped logger.
+func NewContext(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, contextKey{}, entry)
+}
+
+// FromContext returns the request-scoped logger carried by ctx, or the standard logger if there is none.
+// The entry is bound to ctx so that hooks, such as the tracing one, can use it.
+func FromContext(ctx context.Context) *logrus.Entry {
+	if ctx == nil {
+		return logrus.NewEntry(logrus.StandardLogger())
+	}
+	if entry, ok := ctx.Value(contextKey{}).(*logrus.Entry); ok {
+		return entry.WithContext(ctx)
+	}
+	return logrus.StandardLogger().WithContext(ctx)
+}
+
+// WithFields returns a copy of ctx whose request-scoped logger has the given fields added.
+func WithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	return NewContext(ctx, FromContext(ctx).WithFields(fields))
+}
+
+// CorrelationID returns the correlation ID of the request ctx belongs to, if any.
+func CorrelationID(ctx context.Context) string {
+	correlationID, _ := FromContext(ctx).Data[FieldCorrelationID].(string)
+	return correlationID
+}
+
+// Middleware adds a request-scoped logger with the correlation ID of the request to its context.
+// The correlation ID sent by the caller in the X-Correlation-Id header is used if present and valid,
+// a new one is generated otherwise. It's returned in the same header of the response.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		correlationID := r.Header.Get(HeaderCorrelationID)
+		if !correlationIDRegex.MatchString(correlationID) {
+			correlationID = uuid.NewString()
+		}
+		w.Header().Set(HeaderCorrelationID, correlationID)
+
+		ctx := WithFields(r.Context(), logrus.Fields{FieldCorrelationID: correlationID})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,251 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Packages whose log level can be changed on their own
+	PackageHandlers    = "handlers"
+	PackageOCM         = "ocm"
+	PackageHTTPChecker = "httpchecker"
+)
+
+// PackageLogger is the logger of a package whose level can be changed independently of the standard logger.
+// Entries are formatted, written and passed to the hooks of the standard logger.
+type PackageLogger struct {
+	*logrus.Logger
+}
+
+// FromContext returns the request-scoped logger carried by ctx, logging at the level of the package.
+func (p *PackageLogger) FromContext(ctx context.Context) *logrus.Entry {
+	entry := FromContext(ctx)
+	entry.Logger = p.Logger
+	return entry
+}
+
+// standardFormatter formats entries with the current formatter of the standard logger
+type standardFormatter struct{}
+
+func (standardFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	return logrus.StandardLogger().Formatter.Format(entry)
+}
+
+// standardWriter writes to the current output of the standard logger
+type standardWriter struct{}
+
+func (standardWriter) Write(p []byte) (int, error) {
+	return logrus.StandardLogger().Out.Write(p)
+}
+
+// standardHooks fires the current hooks of the standard logger
+type standardHooks struct{}
+
+func (standardHooks) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (standardHooks) Fire(entry *logrus.Entry) error {
+	return logrus.StandardLogger().Hooks.Fire(entry.Level, entry)
+}
+
+// levelOverride is a log level set at runtime, reverted when it expires
+type levelOverride struct {
+	level      logrus.Level
+	expires    time.Time
+	generation int
+}
+
+// levelState keeps the log levels set at runtime on top of the configured level
+type levelState struct {
+	mutex      sync.Mutex
+	configured logrus.Level
+	global     *levelOverride
+	packages   map[string]*levelOverride
+	loggers    map[string]*PackageLogger
+	generation int
+}
+
+var levels = &levelState{
+	configured: logrus.InfoLevel,
+	packages:   make(map[string]*levelOverride),
+	loggers:    make(map[string]*PackageLogger),
+}
+
+// ForPackage returns the logger of the given package, one of PackageHandlers, PackageOCM or PackageHTTPChecker.
+func ForPackage(name string) *PackageLogger {
+	levels.mutex.Lock()
+	defer levels.mutex.Unlock()
+
+	if logger, ok := levels.loggers[name]; ok {
+		return logger
+	}
+	logger := &PackageLogger{
+		Logger: &logrus.Logger{
+			Out:       standardWriter{},
+			Formatter: standardFormatter{},
+			Hooks:     logrus.LevelHooks{},
+			Level:     logrus.StandardLogger().GetLevel(),
+			ExitFunc:  logrus.StandardLogger().ExitFunc,
+		},
+	}
+	logger.AddHook(standardHooks{})
+	levels.loggers[name] = logger
+	levels.apply()
+	return logger
+}
+
+// Packages returns the names of the packages whose log level can be changed on their own.
+func Packages() []string {
+	return []string{PackageHandlers, PackageHTTPChecker, PackageOCM}
+}
+
+func isPackage(name string) bool {
+	for _, p := range Packages() {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// apply sets the level of the standard and package loggers from the configured level and the overrides.
+// The mutex must be held.
+func (s *levelState) apply() {
+	globalLevel := s.configured
+	if s.global != nil {
+		globalLevel = s.global.level
+	}
+	logrus.SetLevel(globalLevel)
+
+	for name, logger := range s.loggers {
+		if override, ok := s.packages[name]; ok {
+			logger.SetLevel(override.level)
+		} else {
+			logger.SetLevel(globalLevel)
+		}
+	}
+}
+
+// setConfiguredLevel sets the level the standard logger reverts to when no override is set.
+func setConfiguredLevel(level logrus.Level) {
+	levels.mutex.Lock()
+	defer levels.mutex.Unlock()
+	levels.configured = level
+	levels.apply()
+}
+
+// SetLevel changes the log level of the given package, or of all packages without their own level if pkg is empty,
+// until ttl has passed.
+func SetLevel(pkg string, level logrus.Level, ttl time.Duration) error {
+	if pkg != "" && !isPackage(pkg) {
+		return fmt.Errorf("unknown package %q, must be one of %v", pkg, Packages())
+	}
+	if ttl <= 0 {
+		return fmt.Errorf("the duration of the log level change must be positive")
+	}
+
+	levels.mutex.Lock()
+	defer levels.mutex.Unlock()
+
+	levels.generation++
+	override := &levelOverride{level: level, expires: time.Now().Add(ttl), generation: levels.generation}
+	if pkg == "" {
+		levels.global = override
+	} else {
+		levels.packages[pkg] = override
+	}
+	levels.apply()
+
+	time.AfterFunc(ttl, func() {
+		levels.mutex.Lock()
+		defer levels.mutex.Unlock()
+		// Only revert the override this timer was started for, not one set since
+		if levels.revert(pkg, override.generation) {
+			logrus.WithFields(logrus.Fields{"package": pkg, "level": level.String()}).Info("Log level change expired")
+		}
+	})
+	return nil
+}
+
+// ResetLevel reverts the log level of the given package, or the level of all packages including those changed
+// on their own if pkg is empty, to the configured one.
+func ResetLevel(pkg string) error {
+	if pkg != "" && !isPackage(pkg) {
+		return fmt.Errorf("unknown package %q, must be one of %v", pkg, Packages())
+	}
+
+	levels.mutex.Lock()
+	defer levels.mutex.Unlock()
+	if pkg == "" {
+		// The timers of the removed overrides find no override left to revert
+		levels.global = nil
+		levels.packages = make(map[string]*levelOverride)
+		levels.apply()
+		return nil
+	}
+	levels.revert(pkg, 0)
+	return nil
+}
+
+// revert removes the override of pkg, or the global one if pkg is empty, if it has the given generation
+// or if generation is 0. The mutex must be held.
+func (s *levelState) revert(pkg string, generation int) bool {
+	var override *levelOverride
+	if pkg == "" {
+		override = s.global
+	} else {
+		override = s.packages[pkg]
+	}
+	if override == nil || (generation != 0 && override.generation != generation) {
+		return false
+	}
+
+	if pkg == "" {
+		s.global = nil
+	} else {
+		delete(s.packages, pkg)
+	}
+	s.apply()
+	return true
+}
+
+// Level describes the log level of the standard logger or of a package.
+type Level struct {
+	Level string `json:"level"`
+	// Expires is when the level set at runtime reverts, if it was
+	Expires *time.Time `json:"expires,omitempty"`
+}
+
+// Levels describes the current log levels.
+type Levels struct {
+	Level
+	Packages map[string]Level `json:"packages"`
+}
+
+// CurrentLevels returns the current log level of the standard logger and of every package.
+func CurrentLevels() Levels {
+	levels.mutex.Lock()
+	defer levels.mutex.Unlock()
+
+	describe := func(override *levelOverride, fallback Level) Level {
+		if override == nil {
+			return fallback
+		}
+		expires := override.expires.UTC()
+		return Level{Level: override.level.String(), Expires: &expires}
+	}
+
+	current := Levels{Packages: make(map[string]Level)}
+	current.Level = describe(levels.global, Level{Level: levels.configured.String()})
+
+	for _, name := range Packages() {
+		current.Packages[name] = describe(levels.packages[name], Level{Level: current.Level.Level})
+	}
+	return current
+}
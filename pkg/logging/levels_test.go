@@ -0,0 +1,103 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Log levels", func() {
+	var (
+		out         bytes.Buffer
+		previousOut = logrus.StandardLogger().Out
+		ocmLogger   *PackageLogger
+	)
+
+	BeforeEach(func() {
+		out.Reset()
+		logrus.SetOutput(&out)
+		setConfiguredLevel(logrus.InfoLevel)
+		ocmLogger = ForPackage(PackageOCM)
+	})
+
+	AfterEach(func() {
+		Expect(ResetLevel("")).To(Succeed())
+		logrus.SetOutput(previousOut)
+	})
+
+	It("follows the configured level", func() {
+		Expect(Configure(logrus.StandardLogger(), FormatText, "warning")).To(Succeed())
+		Expect(ocmLogger.GetLevel()).To(Equal(logrus.WarnLevel))
+		Expect(CurrentLevels().Packages).To(HaveKeyWithValue(PackageOCM, Level{Level: "warning"}))
+	})
+
+	It("changes the level of a single package", func() {
+		Expect(SetLevel(PackageOCM, logrus.DebugLevel, time.Hour)).To(Succeed())
+
+		ocmLogger.Debug("kept")
+		logrus.Debug("skipped")
+		Expect(out.String()).To(ContainSubstring("kept"))
+		Expect(out.String()).ToNot(ContainSubstring("skipped"))
+
+		current := CurrentLevels()
+		Expect(current.Level).To(Equal(Level{Level: "info"}))
+		Expect(current.Packages[PackageOCM].Level).To(Equal("debug"))
+		Expect(current.Packages[PackageOCM].Expires).ToNot(BeNil())
+		Expect(current.Packages[PackageHandlers]).To(Equal(Level{Level: "info"}))
+	})
+
+	It("resets the level of every package, including those changed on their own", func() {
+		Expect(SetLevel("", logrus.WarnLevel, time.Hour)).To(Succeed())
+		Expect(SetLevel(PackageOCM, logrus.DebugLevel, time.Hour)).To(Succeed())
+
+		Expect(ResetLevel("")).To(Succeed())
+
+		Expect(logrus.GetLevel()).To(Equal(logrus.InfoLevel))
+		Expect(ocmLogger.GetLevel()).To(Equal(logrus.InfoLevel))
+		current := CurrentLevels()
+		Expect(current.Level).To(Equal(Level{Level: "info"}))
+		for _, pkg := range Packages() {
+			Expect(current.Packages[pkg]).To(Equal(Level{Level: "info"}))
+		}
+	})
+
+	It("keeps the request-scoped fields of package loggers", func() {
+		ctx := WithFields(context.Background(), logrus.Fields{FieldCorrelationID: "abc"})
+		Expect(SetLevel(PackageOCM, logrus.DebugLevel, time.Hour)).To(Succeed())
+
+		ocmLogger.FromContext(ctx).Debug("kept")
+		Expect(out.String()).To(ContainSubstring("correlation_id=abc"))
+	})
+
+	It("reverts the level when it expires", func() {
+		Expect(SetLevel("", logrus.DebugLevel, 50*time.Millisecond)).To(Succeed())
+		Expect(logrus.GetLevel()).To(Equal(logrus.DebugLevel))
+		Expect(ocmLogger.GetLevel()).To(Equal(logrus.DebugLevel))
+
+		Eventually(logrus.GetLevel).Should(Equal(logrus.InfoLevel))
+		Expect(ocmLogger.GetLevel()).To(Equal(logrus.InfoLevel))
+	})
+
+	It("does not revert a level set again since", func() {
+		Expect(SetLevel(PackageOCM, logrus.DebugLevel, 50*time.Millisecond)).To(Succeed())
+		Expect(SetLevel(PackageOCM, logrus.TraceLevel, time.Hour)).To(Succeed())
+
+		Consistently(ocmLogger.GetLevel, 200*time.Millisecond).Should(Equal(logrus.TraceLevel))
+	})
+
+	It("resets the level", func() {
+		Expect(SetLevel(PackageOCM, logrus.ErrorLevel, time.Hour)).To(Succeed())
+		Expect(ResetLevel(PackageOCM)).To(Succeed())
+		Expect(ocmLogger.GetLevel()).To(Equal(logrus.InfoLevel))
+	})
+
+	It("rejects unknown packages and durations", func() {
+		Expect(SetLevel("unknown", logrus.DebugLevel, time.Hour)).To(MatchError(ContainSubstring(`unknown package "unknown"`)))
+		Expect(SetLevel(PackageOCM, logrus.DebugLevel, 0)).ToNot(Succeed())
+		Expect(ResetLevel("unknown")).ToNot(Succeed())
+	})
+})
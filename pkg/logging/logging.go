@@ -1,11 +1,18 @@
 package logging
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	// Formats of the log entries
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
 var (
 	DebugLogLevel = logrus.DebugLevel
 )
@@ -17,9 +24,45 @@ func NewLogger() (logger *logrus.Logger) {
 		Formatter: new(logrus.TextFormatter),
 		Level:     logrus.InfoLevel,
 	}
-	logger.SetFormatter(&logrus.TextFormatter{
+	logger.SetFormatter(newTextFormatter())
+	return logger
+}
+
+func newTextFormatter() logrus.Formatter {
+	return &logrus.TextFormatter{
 		FullTimestamp: true,
 		PadLevelText:  false,
-	})
-	return logger
+	}
+}
+
+// NewFormatter returns the formatter of the given format, FormatText or FormatJSON.
+func NewFormatter(format string) (logrus.Formatter, error) {
+	switch format {
+	case FormatText:
+		return newTextFormatter(), nil
+	case FormatJSON:
+		return &logrus.JSONFormatter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q, must be one of %s or %s", format, FormatText, FormatJSON)
+	}
+}
+
+// Configure sets the format and level of logger.
+func Configure(logger *logrus.Logger, format, level string) error {
+	formatter, err := NewFormatter(format)
+	if err != nil {
+		return err
+	}
+	logLevel, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	logger.SetFormatter(formatter)
+	if logger == logrus.StandardLogger() {
+		// The package loggers follow the level of the standard logger unless changed at runtime
+		setConfiguredLevel(logLevel)
+	} else {
+		logger.SetLevel(logLevel)
+	}
+	return nil
 }
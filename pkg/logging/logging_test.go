@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+)
+
+var _ = Describe("Logging", func() {
+	var (
+		out    bytes.Buffer
+		logger *logrus.Logger
+	)
+
+	BeforeEach(func() {
+		out.Reset()
+		logger = NewLogger()
+		logger.SetOutput(&out)
+	})
+
+	Context("Configure", func() {
+		It("sets the JSON format and level", func() {
+			Expect(Configure(logger, FormatJSON, "warning")).To(Succeed())
+
+			logger.Info("skipped")
+			logger.WithField("key", "value").Warn("kept")
+
+			var entry map[string]interface{}
+			Expect(json.Unmarshal(out.Bytes(), &entry)).To(Succeed())
+			Expect(entry).To(HaveKeyWithValue("msg", "kept"))
+			Expect(entry).To(HaveKeyWithValue("key", "value"))
+		})
+
+		It("fails on unknown formats and levels", func() {
+			Expect(Configure(logger, "xml", "info")).To(MatchError(ContainSubstring(`unknown log format "xml"`)))
+			Expect(Configure(logger, FormatText, "loud")).ToNot(Succeed())
+		})
+	})
+
+	Context("Request-scoped logger", func() {
+		It("accumulates the fields added to the context", func() {
+			ctx := NewContext(context.Background(), logrus.NewEntry(logger))
+			ctx = WithFields(ctx, logrus.Fields{FieldCorrelationID: "abc"})
+			ctx = WithFields(ctx, logrus.Fields{FieldNotification: "LoggingErrors"})
+
+			entry := FromContext(ctx)
+			Expect(entry.Data).To(HaveKeyWithValue(FieldCorrelationID, "abc"))
+			Expect(entry.Data).To(HaveKeyWithValue(FieldNotification, "LoggingErrors"))
+			Expect(entry.Context).To(Equal(ctx))
+			Expect(CorrelationID(ctx)).To(Equal("abc"))
+		})
+
+		It("falls back to the standard logger", func() {
+			Expect(FromContext(context.Background()).Logger).To(Equal(logrus.StandardLogger()))
+			Expect(CorrelationID(context.Background())).To(BeEmpty())
+		})
+	})
+
+	Context("Middleware", func() {
+		var correlationID string
+		handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			correlationID = CorrelationID(r.Context())
+		}))
+
+		It("uses the correlation ID sent by the caller", func() {
+			req := httptest.NewRequest(http.MethodPost, "/alertmanager-receiver", nil)
+			req.Header.Set(HeaderCorrelationID, "req-123")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			Expect(correlationID).To(Equal("req-123"))
+			Expect(rec.Header().Get(HeaderCorrelationID)).To(Equal("req-123"))
+		})
+
+		It("generates a correlation ID when none or an invalid one is sent", func() {
+			req := httptest.NewRequest(http.MethodPost, "/alertmanager-receiver", nil)
+			req.Header.Set(HeaderCorrelationID, "bad\nid")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			Expect(correlationID).ToNot(BeEmpty())
+			Expect(correlationID).ToNot(Equal("bad\nid"))
+			Expect(rec.Header().Get(HeaderCorrelationID)).To(Equal(correlationID))
+		})
+	})
+})
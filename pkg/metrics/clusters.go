@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Default number of hosted clusters the per-cluster fleet metrics are kept for
+	DefaultMaxClusterSeries = 100
+	// Default time the per-cluster fleet metrics of a hosted cluster are kept without update
+	DefaultClusterSeriesTTL = time.Hour
+)
+
+// ClusterMetricsOptions configures the fleet mode metrics labeled by hosted cluster.
+type ClusterMetricsOptions struct {
+	// Enabled exposes the metrics labeled by hosted cluster
+	Enabled bool
+	// MaxClusters is the number of label sets kept per metric, those with the fewest failures
+	// and the least recently updated are dropped first
+	MaxClusters int
+	// TTL is how long the series of a hosted cluster are kept without update
+	TTL time.Duration
+}
+
+var (
+	metricFleetClusterNotificationFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ocm_agent_fleet_cluster_notification_failures_total",
+			Help: "A count of the notifications which could not be sent to or removed from the hosted cluster for the fleetNotification template",
+		}, []string{"template", "hosted_cluster_id"})
+
+	metricFleetClusterNotificationFailing = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ocm_agent_fleet_cluster_notification_failing",
+			Help: "Indicates that the last notification for the fleetNotification template failed for the hosted cluster",
+		}, []string{"template", "hosted_cluster_id"})
+
+	metricFleetClusterNotificationLastFailure = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ocm_agent_fleet_cluster_notification_last_failure_timestamp_seconds",
+			Help: "Time the last notification for the fleetNotification template failed for the hosted cluster",
+		}, []string{"template", "hosted_cluster_id"})
+
+	// The series are scored by their number of failures, so that the failing hosted clusters are kept
+	clusterSeriesExpiry = newSeriesExpiry(DefaultClusterSeriesTTL, 0,
+		metricFleetClusterNotificationFailures, metricFleetClusterNotificationFailing, metricFleetClusterNotificationLastFailure)
+
+	clusterMetricsMutex   sync.RWMutex
+	clusterMetricsOptions = ClusterMetricsOptions{MaxClusters: DefaultMaxClusterSeries, TTL: DefaultClusterSeriesTTL}
+)
+
+// ConfigureClusterMetrics sets whether and for how many hosted clusters the fleet metrics labeled by
+// hosted cluster are exposed. It also applies to the hosted clusters of the fleet status metrics.
+func ConfigureClusterMetrics(opts ClusterMetricsOptions) {
+	clusterMetricsMutex.Lock()
+	defer clusterMetricsMutex.Unlock()
+	clusterMetricsOptions = opts
+
+	max := opts.MaxClusters
+	if !opts.Enabled {
+		max = 0
+	}
+	clusterSeriesExpiry.configure(opts.TTL, max)
+}
+
+func getClusterMetricsOptions() ClusterMetricsOptions {
+	clusterMetricsMutex.RLock()
+	defer clusterMetricsMutex.RUnlock()
+	return clusterMetricsOptions
+}
+
+// RecordFleetClusterNotification records whether sending or removing the notification of the template
+// for the hosted cluster succeeded. It does nothing unless the per-cluster metrics are enabled.
+func RecordFleetClusterNotification(template, hostedClusterID string, failed bool) {
+	if !getClusterMetricsOptions().Enabled {
+		return
+	}
+	if !failed {
+		// Only the hosted clusters which failed before are worth a series
+		if clusterSeriesExpiry.has(template, hostedClusterID) {
+			metricFleetClusterNotificationFailing.WithLabelValues(template, hostedClusterID).Set(0)
+			clusterSeriesExpiry.touch(template, hostedClusterID)
+		}
+		return
+	}
+	metricFleetClusterNotificationFailures.WithLabelValues(template, hostedClusterID).Inc()
+	metricFleetClusterNotificationFailing.WithLabelValues(template, hostedClusterID).Set(1)
+	metricFleetClusterNotificationLastFailure.WithLabelValues(template, hostedClusterID).SetToCurrentTime()
+	clusterSeriesExpiry.add(1, template, hostedClusterID)
+}
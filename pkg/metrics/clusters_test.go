@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _ = Describe("Fleet cluster metrics", func() {
+	const template = "oidc-deleted"
+
+	BeforeEach(func() {
+		ConfigureClusterMetrics(ClusterMetricsOptions{Enabled: true, MaxClusters: 2, TTL: time.Hour})
+	})
+
+	AfterEach(func() {
+		ConfigureClusterMetrics(ClusterMetricsOptions{MaxClusters: DefaultMaxClusterSeries, TTL: DefaultClusterSeriesTTL})
+	})
+
+	It("doesn't record anything unless enabled", func() {
+		ConfigureClusterMetrics(ClusterMetricsOptions{MaxClusters: DefaultMaxClusterSeries, TTL: DefaultClusterSeriesTTL})
+		RecordFleetClusterNotification(template, "hc-1", true)
+
+		Expect(testutil.CollectAndCount(clusterSeriesExpiry)).To(Equal(0))
+	})
+
+	It("records the failures of a hosted cluster until it succeeds", func() {
+		RecordFleetClusterNotification(template, "hc-1", true)
+		RecordFleetClusterNotification(template, "hc-1", true)
+		Expect(testutil.ToFloat64(metricFleetClusterNotificationFailures.WithLabelValues(template, "hc-1"))).To(Equal(float64(2)))
+		Expect(testutil.ToFloat64(metricFleetClusterNotificationFailing.WithLabelValues(template, "hc-1"))).To(Equal(float64(1)))
+
+		RecordFleetClusterNotification(template, "hc-1", false)
+		Expect(testutil.ToFloat64(metricFleetClusterNotificationFailing.WithLabelValues(template, "hc-1"))).To(Equal(float64(0)))
+	})
+
+	It("doesn't create series for hosted clusters which never failed", func() {
+		RecordFleetClusterNotification(template, "hc-1", false)
+
+		Expect(testutil.CollectAndCount(clusterSeriesExpiry)).To(Equal(0))
+	})
+
+	It("keeps the hosted clusters with the most failures", func() {
+		RecordFleetClusterNotification(template, "hc-1", true)
+		RecordFleetClusterNotification(template, "hc-1", true)
+		RecordFleetClusterNotification(template, "hc-2", true)
+		RecordFleetClusterNotification(template, "hc-3", true)
+
+		Expect(clusterSeriesExpiry.has(template, "hc-1")).To(BeTrue())
+		Expect(clusterSeriesExpiry.has(template, "hc-2")).To(BeFalse())
+		Expect(clusterSeriesExpiry.has(template, "hc-3")).To(BeTrue())
+		Expect(testutil.CollectAndCount(metricFleetClusterNotificationFailures)).To(Equal(2))
+	})
+})
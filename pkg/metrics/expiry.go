@@ -0,0 +1,141 @@
+package metrics
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// How long the series of the failure metrics are kept without being updated
+	FailureSeriesTTL = 24 * time.Hour
+	// Maximum number of label sets kept per failure metric, the least recently updated are dropped first
+	MaxFailureSeries = 500
+)
+
+// metricVec is a vector of metrics whose series can be deleted, such as a GaugeVec or CounterVec
+type metricVec interface {
+	prometheus.Collector
+	DeleteLabelValues(labelValues ...string) bool
+}
+
+// seriesExpiry drops the label sets of metric vectors sharing the same labels once they weren't updated for ttl,
+// or when there are more than max of them, so that their cardinality stays bounded.
+// Beyond max, the series with the lowest score are dropped first, then the least recently updated.
+type seriesExpiry struct {
+	vecs []metricVec
+	ttl  time.Duration
+	max  int
+	now  func() time.Time
+
+	mutex   sync.Mutex
+	updated map[string]series
+}
+
+type series struct {
+	labelValues []string
+	score       float64
+	updated     time.Time
+}
+
+func newSeriesExpiry(ttl time.Duration, max int, vecs ...metricVec) *seriesExpiry {
+	return &seriesExpiry{
+		vecs:    vecs,
+		ttl:     ttl,
+		max:     max,
+		now:     time.Now,
+		updated: make(map[string]series),
+	}
+}
+
+// touch records that the series with the given label values were updated.
+func (e *seriesExpiry) touch(labelValues ...string) {
+	e.add(0, labelValues...)
+}
+
+// add records that the series with the given label values were updated and adds delta to their score.
+func (e *seriesExpiry) add(delta float64, labelValues ...string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	key := strings.Join(labelValues, "\xff")
+	e.updated[key] = series{labelValues: labelValues, score: e.updated[key].score + delta, updated: e.now()}
+	e.expire()
+}
+
+// has returns whether the series with the given label values are tracked.
+func (e *seriesExpiry) has(labelValues ...string) bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	_, ok := e.updated[strings.Join(labelValues, "\xff")]
+	return ok
+}
+
+// configure changes how long series are kept without update and how many are kept at most.
+func (e *seriesExpiry) configure(ttl time.Duration, max int) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.ttl = ttl
+	e.max = max
+	e.expire()
+}
+
+// expire drops the expired series and, above the maximum, the lowest scored and least recently updated ones.
+// The mutex must be held.
+func (e *seriesExpiry) expire() {
+	var kept []string
+	for key, s := range e.updated {
+		if e.now().Sub(s.updated) >= e.ttl {
+			e.delete(key)
+			continue
+		}
+		kept = append(kept, key)
+	}
+	if len(kept) <= e.max {
+		return
+	}
+	sort.Slice(kept, func(i, j int) bool {
+		a, b := e.updated[kept[i]], e.updated[kept[j]]
+		if a.score != b.score {
+			return a.score < b.score
+		}
+		return a.updated.Before(b.updated)
+	})
+	for _, key := range kept[:len(kept)-e.max] {
+		e.delete(key)
+	}
+}
+
+func (e *seriesExpiry) delete(key string) {
+	for _, vec := range e.vecs {
+		vec.DeleteLabelValues(e.updated[key].labelValues...)
+	}
+	delete(e.updated, key)
+}
+
+// reset forgets all the series, which must be reset by the caller.
+func (e *seriesExpiry) reset() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.updated = make(map[string]series)
+}
+
+// Describe implements prometheus.Collector.
+func (e *seriesExpiry) Describe(ch chan<- *prometheus.Desc) {
+	for _, vec := range e.vecs {
+		vec.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector, dropping the expired series first.
+func (e *seriesExpiry) Collect(ch chan<- prometheus.Metric) {
+	e.mutex.Lock()
+	e.expire()
+	e.mutex.Unlock()
+
+	for _, vec := range e.vecs {
+		vec.Collect(ch)
+	}
+}
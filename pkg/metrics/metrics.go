@@ -2,12 +2,43 @@ package metrics
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/openshift/ocm-agent/pkg/consts"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+const (
+	// OCM services called by the agent, as recorded in the ocm_service label
+	OCMServiceServiceLogs  = "service_logs"
+	OCMServiceClustersMgmt = "clusters_mgmt"
+
+	// OCM environment of the connection configured by the flags, recorded as an empty ocm_environment label
+	OCMEnvironmentDefault = ""
+	// OCM environment of the connection of the fleet mode receiver served besides the classic one
+	OCMEnvironmentFleet = "fleet"
+
+	// Status class of OCM calls failing without a response
+	OCMStatusClassError = "error"
+
+	// Modes the webhook receiver processes alerts in
+	AlertModeClassic = "classic"
+	AlertModeFleet   = "fleet"
+
+	// Outcomes of processing an alert
+	AlertOutcomeSent                     = "sent"
+	AlertOutcomeRemoved                  = "removed"
+	AlertOutcomeNothingToDo              = "nothing_to_do"
+	AlertOutcomeSuppressedResendWindow   = "suppressed_resend_window"
+	AlertOutcomeSuppressedLimitedSupport = "suppressed_limited_support"
+	AlertOutcomeSuppressedRateLimit      = "suppressed_rate_limit"
+	AlertOutcomeInvalidAlert             = "invalid_alert"
+	AlertOutcomeNoTemplate               = "no_template"
+	AlertOutcomeUnknownEnvironment       = "unknown_environment"
+	AlertOutcomeFailed                   = "failed"
+)
+
 var (
 	metricRequestsTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
@@ -33,6 +64,22 @@ var (
 			Help: "Indicates that OCM Agent could not successfully process a request",
 		}, []string{"path"})
 
+	metricRequestLastSuccess = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ocm_agent_request_last_success_timestamp_seconds",
+			Help: "Time OCM Agent last successfully processed a request on the path",
+		}, []string{"path"})
+
+	metricRequestLastFailure = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ocm_agent_request_last_failure_timestamp_seconds",
+			Help: "Time OCM Agent last failed to process a request on the path",
+		}, []string{"path"})
+
+	// The request paths and alert names of the failure metrics are dropped once they are not updated anymore
+	requestFailureExpiry  = newSeriesExpiry(FailureSeriesTTL, MaxFailureSeries, MetricRequestFailure, metricRequestLastSuccess, metricRequestLastFailure)
+	responseFailureExpiry = newSeriesExpiry(FailureSeriesTTL, MaxFailureSeries, MetricResponseFailure)
+
 	MetricResponseFailure = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "ocm_agent_response_failure",
@@ -51,12 +98,6 @@ var (
 			Help: "A count of service logs which failed to be sent. This includes service logs which failed to be formatted.",
 		}, []string{"ocm_service", "template"})
 
-	metricServiceLogSentTotal = prometheus.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Name: "ocm_agent_service_log_sent_total",
-			Help: "A total number of service log being sent based on managedNotification template",
-		}, []string{"ocm_service", "template"})
-
 	metricLimitedSupportSentTotal = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "ocm_agent_limited_support_sent_total",
@@ -87,20 +128,100 @@ var (
 			Help: "Pull Secret auth token is not valid",
 		}, []string{})
 
+	metricOCMTokenExpiry = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ocm_agent_ocm_token_expiry_timestamp_seconds",
+			Help: "Time the access token of the OCM connection expires",
+		}, []string{"ocm_environment"})
+
+	metricOCMTokenLastRefresh = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ocm_agent_ocm_token_last_refresh_timestamp_seconds",
+			Help: "Time the OCM connection last obtained a new access token",
+		}, []string{"ocm_environment"})
+
+	metricOCMTokenRefreshFailuresTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ocm_agent_ocm_token_refresh_failures_total",
+			Help: "A count of the failures of the OCM connection to obtain an access token",
+		}, []string{"ocm_environment"})
+
+	metricOCMReachable = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ocm_agent_ocm_reachable",
+			Help: "Indicates that the last connection check could reach OCM",
+		}, []string{"ocm_environment"})
+
+	metricOCMCredentialsReloadsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ocm_agent_ocm_credentials_reloads_total",
+			Help: "A count of the attempts to reload the OCM connection after its credentials changed, by result",
+		}, []string{"ocm_environment", "result"})
+
+	metricOCMRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "ocm_agent_ocm_request_duration_seconds",
+			Help:    "Duration of the calls to OCM, including retries, by OCM service, operation and status class of the last response",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		}, []string{"ocm_environment", "ocm_service", "operation", "status_class"})
+
+	metricOCMRateLimitedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ocm_agent_ocm_rate_limited_total",
+			Help: "A count of OCM responses with HTTP 429 Too Many Requests, including those retried",
+		}, []string{"ocm_environment", "ocm_service", "operation"})
+
+	metricOCMRetriesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ocm_agent_ocm_request_retries_total",
+			Help: "A count of OCM requests retried after a failed attempt",
+		}, []string{"ocm_environment", "ocm_service", "operation"})
+
+	metricWebhookProcessingDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "ocm_agent_webhook_processing_duration_seconds",
+			Help:    "Duration of processing all the alerts of a notification received from Alertmanager",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"mode"})
+
+	metricAlertProcessingDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "ocm_agent_alert_processing_duration_seconds",
+			Help:    "Duration of processing a single alert by outcome",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		}, []string{"mode", "outcome"})
+
+	metricAlertOutcomesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ocm_agent_alert_outcomes_total",
+			Help: "A count of the alerts processed by notification template and outcome",
+		}, []string{"mode", "template", "outcome"})
+
 	metricsList = []prometheus.Collector{
 		metricRequestsTotal,
 		metricFailedRequestsTotal,
 		metricRequestsByService,
-		MetricRequestFailure,
-		MetricResponseFailure,
+		requestFailureExpiry,
+		responseFailureExpiry,
+		clusterSeriesExpiry,
 		metricServiceLogSent,
 		metricFailedServiceLogsTotal,
-		metricServiceLogSentTotal,
 		metricPullSecretInvalid,
+		metricOCMTokenExpiry,
+		metricOCMTokenLastRefresh,
+		metricOCMTokenRefreshFailuresTotal,
+		metricOCMReachable,
+		metricOCMCredentialsReloadsTotal,
 		metricLimitedSupportSentTotal,
 		metricLimitedSupportRemovedTotal,
 		metricFailedLimitedSupportSendsTotal,
 		metricFailedLimitedSupportRemovalsTotal,
+		metricOCMRequestDuration,
+		metricOCMRateLimitedTotal,
+		metricOCMRetriesTotal,
+		metricWebhookProcessingDuration,
+		metricAlertProcessingDuration,
+		metricAlertOutcomesTotal,
 	}
 )
 
@@ -137,14 +258,21 @@ func PrometheusMiddleware(ph http.Handler) http.Handler {
 
 		rw := NewResponseWriter(w)
 		ph.ServeHTTP(rw, r)
-		statusCode := rw.statusCode
-		if statusCode != http.StatusOK {
+		if isSuccessStatus(rw.statusCode) {
+			SetRequestMetricSuccess(path)
+		} else {
 			metricFailedRequestsTotal.WithLabelValues().Inc()
 			SetRequestMetricFailure(path)
 		}
 	})
 }
 
+// isSuccessStatus reports whether a request answered with the status succeeded: any 2xx status, e.g. an upgrade
+// policy created or deleted through the proxy, or 304 Not Modified for a cached proxy response
+func isSuccessStatus(statusCode int) bool {
+	return (statusCode >= 200 && statusCode < 300) || statusCode == http.StatusNotModified
+}
+
 // getRouteName safely extracts route from the request, preferring gorilla mux route if available
 func getRouteName(r *http.Request) string {
 	if mux.CurrentRoute(r) != nil {
@@ -164,13 +292,30 @@ func SetResponseMetricFailure(service string, notificationName string, alertName
 		"notification_name": notificationName,
 		"alert_name":        alertName,
 	}).Set(float64(1))
+	responseFailureExpiry.touch(service, notificationName, alertName)
 }
 
-// SetRequestMetricFailure sets the metric when a call on ocm agent service with path has failed
+// SetRequestMetricFailure sets the metric and the last failure time when a call on ocm agent service with path has failed
 func SetRequestMetricFailure(path string) {
 	MetricRequestFailure.With(prometheus.Labels{
 		"path": path,
 	}).Set(float64(1))
+	metricRequestLastFailure.With(prometheus.Labels{
+		"path": path,
+	}).SetToCurrentTime()
+	requestFailureExpiry.touch(path)
+}
+
+// SetRequestMetricSuccess resets the failure metric and sets the last success time when a call on ocm agent service
+// with path has succeeded. The failure metrics of the other paths are left as they are.
+func SetRequestMetricSuccess(path string) {
+	MetricRequestFailure.With(prometheus.Labels{
+		"path": path,
+	}).Set(float64(0))
+	metricRequestLastSuccess.With(prometheus.Labels{
+		"path": path,
+	}).SetToCurrentTime()
+	requestFailureExpiry.touch(path)
 }
 
 // CountServiceLogSent counts the total number of service log sent by notification template
@@ -190,14 +335,6 @@ func CountFailedServiceLogs(template string) {
 	}).Inc()
 }
 
-// SetTotalServiceLogCount used to set the total sent service log number based on the managedNotification status
-func SetTotalServiceLogCount(template string, count int32) {
-	metricServiceLogSentTotal.With(prometheus.Labels{
-		"ocm_service": "service_logs",
-		"template":    template,
-	}).Set(float64(count))
-}
-
 // IncrementLimitedSupportSentCount increments the total sent limited support number
 func IncrementLimitedSupportSentCount(template string) {
 	metricLimitedSupportSentTotal.With(prometheus.Labels{
@@ -240,9 +377,38 @@ func SetPullSecretInvalidMetricFailure() {
 	metricPullSecretInvalid.WithLabelValues().Set(float64(1))
 }
 
-// ResetMetric reset the metric with Gauge values
-func ResetMetric(m *prometheus.GaugeVec) {
-	m.Reset()
+// SetOCMTokenRefreshed records that the connection of the OCM environment obtained a new access token expiring at
+// expiry, which is zero when the token doesn't expire or its expiry is unknown
+func SetOCMTokenRefreshed(environment string, refreshed, expiry time.Time) {
+	metricOCMTokenLastRefresh.WithLabelValues(environment).Set(float64(refreshed.Unix()))
+	if expiry.IsZero() {
+		metricOCMTokenExpiry.DeleteLabelValues(environment)
+		return
+	}
+	metricOCMTokenExpiry.WithLabelValues(environment).Set(float64(expiry.Unix()))
+}
+
+// CountOCMTokenRefreshFailure counts a failure of the connection of the OCM environment to obtain an access token
+func CountOCMTokenRefreshFailure(environment string) {
+	metricOCMTokenRefreshFailuresTotal.WithLabelValues(environment).Inc()
+}
+
+// SetOCMReachable sets whether the last connection check could reach the OCM environment
+func SetOCMReachable(environment string, reachable bool) {
+	value := 0.0
+	if reachable {
+		value = 1
+	}
+	metricOCMReachable.WithLabelValues(environment).Set(value)
+}
+
+// CountOCMCredentialsReload counts an attempt to reload the connection of the OCM environment with changed credentials
+func CountOCMCredentialsReload(environment string, success bool) {
+	result := "success"
+	if !success {
+		result = "failure"
+	}
+	metricOCMCredentialsReloadsTotal.WithLabelValues(environment, result).Inc()
 }
 
 // ResetRequestMetricFailure with labels
@@ -252,4 +418,55 @@ func ResetResponseMetricFailure(service string, notificationName string, alertNa
 		"notification_name": notificationName,
 		"alert_name":        alertName,
 	}).Set(float64(0))
+	responseFailureExpiry.touch(service, notificationName, alertName)
+}
+
+// ObserveOCMRequest records the duration of a call to the OCM environment and the status class of its last response,
+// e.g. 2xx, or OCMStatusClassError if it failed without a response
+func ObserveOCMRequest(environment, service, operation, statusClass string, duration time.Duration) {
+	metricOCMRequestDuration.With(prometheus.Labels{
+		"ocm_environment": environment,
+		"ocm_service":     service,
+		"operation":       operation,
+		"status_class":    statusClass,
+	}).Observe(duration.Seconds())
+}
+
+// CountOCMRateLimited counts the responses of a call to the OCM environment rejected with HTTP 429
+func CountOCMRateLimited(environment, service, operation string, count int) {
+	metricOCMRateLimitedTotal.With(prometheus.Labels{
+		"ocm_environment": environment,
+		"ocm_service":     service,
+		"operation":       operation,
+	}).Add(float64(count))
+}
+
+// CountOCMRetries counts the requests of a call to the OCM environment which were retried
+func CountOCMRetries(environment, service, operation string, count int) {
+	metricOCMRetriesTotal.With(prometheus.Labels{
+		"ocm_environment": environment,
+		"ocm_service":     service,
+		"operation":       operation,
+	}).Add(float64(count))
+}
+
+// ObserveWebhookProcessing records the duration of processing the alerts of a notification received from Alertmanager
+func ObserveWebhookProcessing(mode string, duration time.Duration) {
+	metricWebhookProcessingDuration.With(prometheus.Labels{
+		"mode": mode,
+	}).Observe(duration.Seconds())
+}
+
+// ObserveAlertProcessing counts the outcome of processing an alert for a notification template and records its duration.
+// The template should be empty for alerts which can't be mapped to a template, to bound the cardinality of the metric.
+func ObserveAlertProcessing(mode, template, outcome string, duration time.Duration) {
+	metricAlertOutcomesTotal.With(prometheus.Labels{
+		"mode":     mode,
+		"template": template,
+		"outcome":  outcome,
+	}).Inc()
+	metricAlertProcessingDuration.With(prometheus.Labels{
+		"mode":    mode,
+		"outcome": outcome,
+	}).Observe(duration.Seconds())
 }
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -71,6 +73,25 @@ var _ = Describe("Webhook Handlers", func() {
 			})
 		})
 
+		DescribeTable("counts the 2xx and 304 responses as successes",
+			func(status int) {
+				promHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(status)
+				})
+				server.AppendHandlers(PrometheusMiddleware(promHandler).ServeHTTP)
+				resp, err = http.Get(server.URL() + testPath)
+				Expect(err).To(BeNil())
+				Expect(resp.StatusCode).Should(Equal(status))
+
+				Expect(testutil.ToFloat64(metricFailedRequestsTotal.WithLabelValues())).To(BeZero())
+				Expect(testutil.ToFloat64(MetricRequestFailure.WithLabelValues(testPath))).To(BeZero())
+				Expect(testutil.ToFloat64(metricRequestLastSuccess.WithLabelValues(testPath))).ToNot(BeZero())
+			},
+			Entry("201 Created", http.StatusCreated),
+			Entry("204 No Content", http.StatusNoContent),
+			Entry("304 Not Modified", http.StatusNotModified),
+		)
+
 		When("testing an unsuccessful call", func() {
 			var (
 				failedReqTotalHeader = `
@@ -138,8 +159,52 @@ var _ = Describe("Webhook Handlers", func() {
 				expectedMetric := fmt.Sprintf("%s%s%d\n", metricHelpHeader, metricValueHeader, 1)
 				err := testutil.CollectAndCompare(MetricRequestFailure, strings.NewReader(expectedMetric))
 				Expect(err).To(BeNil())
+				Expect(testutil.ToFloat64(metricRequestLastFailure.WithLabelValues(testPath))).To(BeNumerically(">", 0))
 			})
 		})
+		When("a request on the path succeeds", func() {
+			It("only resets the metric of the path", func() {
+				SetRequestMetricFailure(testPath)
+				SetRequestMetricFailure("/other-path")
+				SetRequestMetricSuccess(testPath)
+				Expect(testutil.ToFloat64(MetricRequestFailure.WithLabelValues(testPath))).To(Equal(float64(0)))
+				Expect(testutil.ToFloat64(MetricRequestFailure.WithLabelValues("/other-path"))).To(Equal(float64(1)))
+				Expect(testutil.ToFloat64(metricRequestLastSuccess.WithLabelValues(testPath))).To(BeNumerically(">", 0))
+			})
+		})
+	})
+
+	Context("Failure series expiry", func() {
+		var (
+			now    time.Time
+			gauge  *prometheus.GaugeVec
+			expiry *seriesExpiry
+		)
+		BeforeEach(func() {
+			now = time.Now()
+			gauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test_failure", Help: "test"}, []string{"path"})
+			expiry = newSeriesExpiry(time.Hour, 2, gauge)
+			expiry.now = func() time.Time { return now }
+		})
+		It("drops the series which were not updated within the TTL", func() {
+			gauge.WithLabelValues("/a").Set(1)
+			expiry.touch("/a")
+			now = now.Add(30 * time.Minute)
+			gauge.WithLabelValues("/b").Set(1)
+			expiry.touch("/b")
+			now = now.Add(45 * time.Minute)
+			Expect(testutil.CollectAndCount(expiry)).To(Equal(1))
+			Expect(testutil.ToFloat64(gauge.WithLabelValues("/b"))).To(Equal(float64(1)))
+		})
+		It("drops the least recently updated series above the maximum", func() {
+			for _, path := range []string{"/a", "/b", "/c"} {
+				now = now.Add(time.Minute)
+				gauge.WithLabelValues(path).Set(1)
+				expiry.touch(path)
+			}
+			Expect(testutil.CollectAndCount(expiry)).To(Equal(2))
+			Expect(testutil.ToFloat64(gauge.WithLabelValues("/c"))).To(Equal(float64(1)))
+		})
 	})
 
 	Context("Service Log Sent metric", func() {
@@ -246,6 +311,37 @@ var _ = Describe("Webhook Handlers", func() {
 		})
 	})
 
+	Context("OCM request metrics", func() {
+		It("observes the duration of OCM calls by status class", func() {
+			ObserveOCMRequest(OCMEnvironmentDefault, OCMServiceClustersMgmt, "GetCluster", "2xx", 200*time.Millisecond)
+			ObserveOCMRequest(OCMEnvironmentDefault, OCMServiceClustersMgmt, "GetCluster", OCMStatusClassError, time.Second)
+
+			Expect(testutil.CollectAndCount(metricOCMRequestDuration)).To(Equal(2))
+		})
+
+		It("counts rate limited responses and retries by OCM environment", func() {
+			CountOCMRateLimited("stage", OCMServiceServiceLogs, "SendServiceLog", 2)
+			CountOCMRetries("stage", OCMServiceServiceLogs, "SendServiceLog", 1)
+
+			Expect(testutil.ToFloat64(metricOCMRateLimitedTotal.WithLabelValues("stage", OCMServiceServiceLogs, "SendServiceLog"))).To(Equal(float64(2)))
+			Expect(testutil.ToFloat64(metricOCMRetriesTotal.WithLabelValues("stage", OCMServiceServiceLogs, "SendServiceLog"))).To(Equal(float64(1)))
+		})
+	})
+
+	Context("Alert processing metrics", func() {
+		It("counts the outcomes of alerts and observes their processing duration", func() {
+			ObserveWebhookProcessing(AlertModeFleet, time.Second)
+			ObserveAlertProcessing(AlertModeFleet, testTemplate, AlertOutcomeSent, 100*time.Millisecond)
+			ObserveAlertProcessing(AlertModeFleet, testTemplate, AlertOutcomeSent, 200*time.Millisecond)
+			ObserveAlertProcessing(AlertModeFleet, "", AlertOutcomeInvalidAlert, time.Millisecond)
+
+			Expect(testutil.ToFloat64(metricAlertOutcomesTotal.WithLabelValues(AlertModeFleet, testTemplate, AlertOutcomeSent))).To(Equal(float64(2)))
+			Expect(testutil.ToFloat64(metricAlertOutcomesTotal.WithLabelValues(AlertModeFleet, "", AlertOutcomeInvalidAlert))).To(Equal(float64(1)))
+			Expect(testutil.CollectAndCount(metricAlertProcessingDuration)).To(Equal(2))
+			Expect(testutil.CollectAndCount(metricWebhookProcessingDuration)).To(Equal(1))
+		})
+	})
+
 	Context("Pull Secret Invalid metric", func() {
 		var (
 			metricHelpHeader = `
@@ -269,7 +365,11 @@ func resetMetrics() {
 	metricServiceLogSent.Reset()
 	metricFailedServiceLogsTotal.Reset()
 	MetricRequestFailure.Reset()
+	metricRequestLastSuccess.Reset()
+	metricRequestLastFailure.Reset()
+	requestFailureExpiry.reset()
 	MetricResponseFailure.Reset()
+	responseFailureExpiry.reset()
 	metricRequestsTotal.Reset()
 	metricFailedRequestsTotal.Reset()
 	metricRequestsByService.Reset()
@@ -278,4 +378,10 @@ func resetMetrics() {
 	metricFailedLimitedSupportSendsTotal.Reset()
 	metricLimitedSupportRemovedTotal.Reset()
 	metricLimitedSupportSentTotal.Reset()
+	metricOCMRequestDuration.Reset()
+	metricOCMRateLimitedTotal.Reset()
+	metricOCMRetriesTotal.Reset()
+	metricWebhookProcessingDuration.Reset()
+	metricAlertProcessingDuration.Reset()
+	metricAlertOutcomesTotal.Reset()
 }
@@ -0,0 +1,284 @@
+package metrics
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	oav1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// How long the notification custom resources listed for a scrape are reused by the following scrapes
+	DefaultStatusCacheTTL = 30 * time.Second
+	// How long a scrape waits for the notification custom resources to be listed, shorter than the default
+	// Prometheus scrape timeout of 10 seconds so that the last listed metrics are exposed instead
+	DefaultStatusListTimeout = 5 * time.Second
+
+	// Values of the state label of ocm_agent_fleet_notification_sent_total
+	NotificationStateFiring   = "firing"
+	NotificationStateResolved = "resolved"
+)
+
+var (
+	descServiceLogSentTotal = prometheus.NewDesc(
+		"ocm_agent_service_log_sent_total",
+		"A total number of service log being sent based on managedNotification template",
+		[]string{"ocm_service", "template"}, nil)
+
+	descNotificationFiring = prometheus.NewDesc(
+		"ocm_agent_notification_firing",
+		"Indicates that the alert of the managedNotification template is firing",
+		[]string{"template"}, nil)
+
+	descNotificationLastSent = prometheus.NewDesc(
+		"ocm_agent_notification_last_sent_timestamp_seconds",
+		"Time the last service log was sent for the managedNotification template",
+		[]string{"template"}, nil)
+
+	descFleetNotificationSentTotal = prometheus.NewDesc(
+		"ocm_agent_fleet_notification_sent_total",
+		"A total number of notifications sent to a hosted cluster for the fleetNotification template, by state of the alert",
+		[]string{"template", "management_cluster_id", "hosted_cluster_id", "state"}, nil)
+
+	descFleetNotificationLastSent = prometheus.NewDesc(
+		"ocm_agent_fleet_notification_last_sent_timestamp_seconds",
+		"Time the last notification was sent to a hosted cluster for the fleetNotification template",
+		[]string{"template", "management_cluster_id", "hosted_cluster_id"}, nil)
+
+	descFleetLimitedSupportActive = prometheus.NewDesc(
+		"ocm_agent_fleet_limited_support_active",
+		"Number of hosted clusters in limited support for the fleetNotification template",
+		[]string{"template", "management_cluster_id", "hosted_cluster_id"}, nil)
+)
+
+// StatusCollector exposes metrics derived from the status of the notification custom resources,
+// so that they are available right after a restart rather than only once a notification is sent.
+type StatusCollector struct {
+	c          client.Reader
+	namespaces []string
+	fleetMode  bool
+	ttl        time.Duration
+	timeout    time.Duration
+	now        func() time.Time
+
+	mutex    sync.Mutex
+	listedAt time.Time
+	snapshot []prometheus.Metric
+}
+
+// NewStatusCollector returns a collector reading the ManagedNotifications, or in fleet mode the
+// ManagedFleetNotifications and ManagedFleetNotificationRecords, of the namespaces at most once per ttl.
+// As for the webhook receivers, a template defined in several namespaces is taken from the first one.
+func NewStatusCollector(c client.Reader, namespaces []string, fleetMode bool, ttl time.Duration) *StatusCollector {
+	return &StatusCollector{
+		c:          c,
+		namespaces: namespaces,
+		fleetMode:  fleetMode,
+		ttl:        ttl,
+		timeout:    DefaultStatusListTimeout,
+		now:        time.Now,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (s *StatusCollector) Describe(ch chan<- *prometheus.Desc) {
+	if s.fleetMode {
+		ch <- descFleetNotificationSentTotal
+		ch <- descFleetNotificationLastSent
+		ch <- descFleetLimitedSupportActive
+		return
+	}
+	ch <- descServiceLogSentTotal
+	ch <- descNotificationFiring
+	ch <- descNotificationLastSent
+}
+
+// Collect implements prometheus.Collector.
+// When the custom resources can't be listed within the timeout, the metrics of the last successful listing,
+// if any, are exposed.
+func (s *StatusCollector) Collect(ch chan<- prometheus.Metric) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.listedAt.IsZero() || s.now().Sub(s.listedAt) >= s.ttl {
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		var snapshot []prometheus.Metric
+		var err error
+		if s.fleetMode {
+			snapshot, err = s.collectFleet(ctx)
+		} else {
+			snapshot, err = s.collectClassic(ctx)
+		}
+		cancel()
+		if err != nil {
+			log.WithError(err).Warn("Failed to list the notification custom resources for the status metrics")
+		} else {
+			s.snapshot = snapshot
+		}
+		// Don't retry on every scrape while the API server is unavailable
+		s.listedAt = s.now()
+	}
+
+	for _, m := range s.snapshot {
+		ch <- m
+	}
+}
+
+func (s *StatusCollector) collectClassic(ctx context.Context) ([]prometheus.Metric, error) {
+	var managedNotifications []oav1alpha1.ManagedNotification
+	for _, namespace := range s.namespaces {
+		list := &oav1alpha1.ManagedNotificationList{}
+		if err := s.c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			return nil, err
+		}
+		managedNotifications = append(managedNotifications, list.Items...)
+	}
+
+	var snapshot []prometheus.Metric
+	seen := make(map[string]bool)
+	for _, managedNotification := range managedNotifications {
+		for _, record := range managedNotification.Status.NotificationRecords {
+			if seen[record.Name] {
+				continue
+			}
+			seen[record.Name] = true
+			snapshot = append(snapshot,
+				prometheus.MustNewConstMetric(descServiceLogSentTotal, prometheus.GaugeValue,
+					float64(record.ServiceLogSentCount), OCMServiceServiceLogs, record.Name))
+
+			firing := 0.0
+			if condition := record.Conditions.GetCondition(oav1alpha1.ConditionAlertFiring); condition != nil && condition.Status == corev1.ConditionTrue {
+				firing = 1
+			}
+			snapshot = append(snapshot, prometheus.MustNewConstMetric(descNotificationFiring, prometheus.GaugeValue, firing, record.Name))
+
+			if condition := record.Conditions.GetCondition(oav1alpha1.ConditionServiceLogSent); condition != nil &&
+				condition.Status == corev1.ConditionTrue && condition.LastTransitionTime != nil {
+				snapshot = append(snapshot, prometheus.MustNewConstMetric(descNotificationLastSent, prometheus.GaugeValue,
+					float64(condition.LastTransitionTime.Unix()), record.Name))
+			}
+		}
+	}
+	return snapshot, nil
+}
+
+func (s *StatusCollector) collectFleet(ctx context.Context) ([]prometheus.Metric, error) {
+	limitedSupport := make(map[string]bool)
+	records := &oav1alpha1.ManagedFleetNotificationRecordList{}
+	for _, namespace := range s.namespaces {
+		fleetNotifications := &oav1alpha1.ManagedFleetNotificationList{}
+		if err := s.c.List(ctx, fleetNotifications, client.InNamespace(namespace)); err != nil {
+			return nil, err
+		}
+		for _, fleetNotification := range fleetNotifications.Items {
+			if _, ok := limitedSupport[fleetNotification.Spec.FleetNotification.Name]; !ok {
+				limitedSupport[fleetNotification.Spec.FleetNotification.Name] = fleetNotification.Spec.FleetNotification.LimitedSupport
+			}
+		}
+
+		namespaceRecords := &oav1alpha1.ManagedFleetNotificationRecordList{}
+		if err := s.c.List(ctx, namespaceRecords, client.InNamespace(namespace)); err != nil {
+			return nil, err
+		}
+		records.Items = append(records.Items, namespaceRecords.Items...)
+	}
+
+	// Without per-cluster metrics, or beyond the most recently notified hosted clusters, the items are
+	// aggregated under an empty hosted_cluster_id so that the cardinality doesn't grow with the fleet
+	clusters := getClusterMetricsOptions()
+	keptClusters := make(map[string]bool)
+	if clusters.Enabled {
+		var items []oav1alpha1.NotificationRecordItem
+		for _, record := range records.Items {
+			for _, byName := range record.Status.NotificationRecordByName {
+				items = append(items, byName.NotificationRecordItems...)
+			}
+		}
+		sort.SliceStable(items, func(i, j int) bool {
+			return lastTransition(items[i]).After(lastTransition(items[j]))
+		})
+		for _, item := range items {
+			if len(keptClusters) >= clusters.MaxClusters {
+				break
+			}
+			keptClusters[item.HostedClusterID] = true
+		}
+	}
+
+	type fleetSeries struct {
+		labels         []string
+		firing         int
+		resolved       int
+		lastSent       time.Time
+		limitedSupport bool
+		inLS           int
+	}
+	var order []string
+	aggregated := make(map[string]*fleetSeries)
+	for _, record := range records.Items {
+		managementClusterID := record.Status.ManagementCluster
+		if managementClusterID == "" {
+			managementClusterID = record.Name
+		}
+		for _, byName := range record.Status.NotificationRecordByName {
+			for _, item := range byName.NotificationRecordItems {
+				hostedClusterID := ""
+				if keptClusters[item.HostedClusterID] {
+					hostedClusterID = item.HostedClusterID
+				}
+				labels := []string{byName.NotificationName, managementClusterID, hostedClusterID}
+				key := strings.Join(labels, "\xff")
+				series, ok := aggregated[key]
+				if !ok {
+					series = &fleetSeries{labels: labels, limitedSupport: limitedSupport[byName.NotificationName]}
+					aggregated[key] = series
+					order = append(order, key)
+				}
+				series.firing += item.FiringNotificationSentCount
+				series.resolved += item.ResolvedNotificationSentCount
+				if t := lastTransition(item); t.After(series.lastSent) {
+					series.lastSent = t
+				}
+				// The firing count is ahead of the resolved count while a limited support reason is active
+				if item.FiringNotificationSentCount > item.ResolvedNotificationSentCount {
+					series.inLS++
+				}
+			}
+		}
+	}
+
+	var snapshot []prometheus.Metric
+	for _, key := range order {
+		series := aggregated[key]
+		snapshot = append(snapshot,
+			prometheus.MustNewConstMetric(descFleetNotificationSentTotal, prometheus.GaugeValue,
+				float64(series.firing), append(series.labels, NotificationStateFiring)...),
+			prometheus.MustNewConstMetric(descFleetNotificationSentTotal, prometheus.GaugeValue,
+				float64(series.resolved), append(series.labels, NotificationStateResolved)...))
+
+		if !series.lastSent.IsZero() {
+			snapshot = append(snapshot, prometheus.MustNewConstMetric(descFleetNotificationLastSent, prometheus.GaugeValue,
+				float64(series.lastSent.Unix()), series.labels...))
+		}
+
+		if series.limitedSupport {
+			snapshot = append(snapshot, prometheus.MustNewConstMetric(descFleetLimitedSupportActive, prometheus.GaugeValue,
+				float64(series.inLS), series.labels...))
+		}
+	}
+	return snapshot, nil
+}
+
+func lastTransition(item oav1alpha1.NotificationRecordItem) time.Time {
+	if item.LastTransitionTime == nil {
+		return time.Time{}
+	}
+	return item.LastTransitionTime.Time
+}
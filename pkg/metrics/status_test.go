@@ -0,0 +1,240 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	oav1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clientmocks "github.com/openshift/ocm-agent/pkg/util/test/generated/mocks/client"
+)
+
+var _ = Describe("Status collector", func() {
+	const namespace = "openshift-ocm-agent-operator"
+
+	var (
+		mockCtrl   *gomock.Controller
+		mockReader *clientmocks.MockReader
+		now        time.Time
+		sentTime   = metav1.NewTime(time.Unix(1700000000, 0))
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockReader = clientmocks.NewMockReader(mockCtrl)
+		now = time.Now()
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Context("in classic mode", func() {
+		var collector *StatusCollector
+
+		BeforeEach(func() {
+			collector = NewStatusCollector(mockReader, []string{namespace}, false, time.Minute)
+			collector.now = func() time.Time { return now }
+		})
+
+		listManagedNotifications := func() *gomock.Call {
+			return mockReader.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&oav1alpha1.ManagedNotificationList{}), client.InNamespace(namespace)).DoAndReturn(
+				func(ctx context.Context, list *oav1alpha1.ManagedNotificationList, opts ...client.ListOption) error {
+					list.Items = []oav1alpha1.ManagedNotification{{
+						Status: oav1alpha1.ManagedNotificationStatus{
+							NotificationRecords: oav1alpha1.NotificationRecords{{
+								Name:                "LoggingVolumeFillingUp",
+								ServiceLogSentCount: 3,
+								Conditions: oav1alpha1.Conditions{
+									{Type: oav1alpha1.ConditionAlertFiring, Status: corev1.ConditionTrue, LastTransitionTime: &sentTime},
+									{Type: oav1alpha1.ConditionServiceLogSent, Status: corev1.ConditionTrue, LastTransitionTime: &sentTime},
+								},
+							}},
+						},
+					}}
+					return nil
+				})
+		}
+
+		It("exposes the sent count, firing state and last sent time of the notifications", func() {
+			listManagedNotifications()
+
+			expected := `
+# HELP ocm_agent_notification_firing Indicates that the alert of the managedNotification template is firing
+# TYPE ocm_agent_notification_firing gauge
+ocm_agent_notification_firing{template="LoggingVolumeFillingUp"} 1
+# HELP ocm_agent_notification_last_sent_timestamp_seconds Time the last service log was sent for the managedNotification template
+# TYPE ocm_agent_notification_last_sent_timestamp_seconds gauge
+ocm_agent_notification_last_sent_timestamp_seconds{template="LoggingVolumeFillingUp"} 1.7e+09
+# HELP ocm_agent_service_log_sent_total A total number of service log being sent based on managedNotification template
+# TYPE ocm_agent_service_log_sent_total gauge
+ocm_agent_service_log_sent_total{ocm_service="service_logs",template="LoggingVolumeFillingUp"} 3
+`
+			Expect(testutil.CollectAndCompare(collector, strings.NewReader(expected))).To(Succeed())
+		})
+
+		It("reuses the listed notifications until the cache expires", func() {
+			listManagedNotifications().Times(2)
+
+			Expect(testutil.CollectAndCount(collector)).To(Equal(3))
+			now = now.Add(30 * time.Second)
+			Expect(testutil.CollectAndCount(collector)).To(Equal(3))
+			now = now.Add(time.Minute)
+			Expect(testutil.CollectAndCount(collector)).To(Equal(3))
+		})
+
+		It("keeps exposing the last listed notifications when listing fails", func() {
+			listManagedNotifications()
+			mockReader.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).Return(fmt.Errorf("a fake error"))
+
+			Expect(testutil.CollectAndCount(collector)).To(Equal(3))
+			now = now.Add(time.Minute)
+			Expect(testutil.CollectAndCount(collector)).To(Equal(3))
+		})
+
+		It("keeps exposing the last listed notifications when listing times out", func() {
+			collector.timeout = 10 * time.Millisecond
+			listManagedNotifications()
+			mockReader.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+					<-ctx.Done()
+					return ctx.Err()
+				})
+
+			Expect(testutil.CollectAndCount(collector)).To(Equal(3))
+			now = now.Add(time.Minute)
+			Expect(testutil.CollectAndCount(collector)).To(Equal(3))
+		})
+
+		It("exposes no notifications when the first listing times out", func() {
+			collector.timeout = 10 * time.Millisecond
+			mockReader.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+					<-ctx.Done()
+					return ctx.Err()
+				})
+
+			Expect(testutil.CollectAndCount(collector)).To(Equal(0))
+		})
+	})
+
+	Context("in several namespaces", func() {
+		It("exposes the templates defined in several namespaces once, from the first namespace", func() {
+			collector := NewStatusCollector(mockReader, []string{namespace, "ocm-agent-staging"}, false, time.Minute)
+			for namespace, record := range map[string]oav1alpha1.NotificationRecord{
+				namespace:           {Name: "LoggingVolumeFillingUp", ServiceLogSentCount: 3},
+				"ocm-agent-staging": {Name: "LoggingVolumeFillingUp", ServiceLogSentCount: 5},
+			} {
+				mockReader.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&oav1alpha1.ManagedNotificationList{}), client.InNamespace(namespace)).DoAndReturn(
+					func(ctx context.Context, list *oav1alpha1.ManagedNotificationList, opts ...client.ListOption) error {
+						list.Items = []oav1alpha1.ManagedNotification{{
+							Status: oav1alpha1.ManagedNotificationStatus{NotificationRecords: oav1alpha1.NotificationRecords{record}},
+						}}
+						return nil
+					})
+			}
+
+			expected := `
+# HELP ocm_agent_service_log_sent_total A total number of service log being sent based on managedNotification template
+# TYPE ocm_agent_service_log_sent_total gauge
+ocm_agent_service_log_sent_total{ocm_service="service_logs",template="LoggingVolumeFillingUp"} 3
+`
+			Expect(testutil.CollectAndCompare(collector, strings.NewReader(expected), "ocm_agent_service_log_sent_total")).To(Succeed())
+		})
+	})
+
+	Context("in fleet mode", func() {
+		var collector *StatusCollector
+		recentTime := metav1.NewTime(sentTime.Add(time.Hour))
+
+		BeforeEach(func() {
+			collector = NewStatusCollector(mockReader, []string{namespace}, true, time.Minute)
+			mockReader.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&oav1alpha1.ManagedFleetNotificationList{}), client.InNamespace(namespace)).DoAndReturn(
+				func(ctx context.Context, list *oav1alpha1.ManagedFleetNotificationList, opts ...client.ListOption) error {
+					list.Items = []oav1alpha1.ManagedFleetNotification{{
+						Spec: oav1alpha1.ManagedFleetNotificationSpec{
+							FleetNotification: oav1alpha1.FleetNotification{Name: "oidc-deleted", LimitedSupport: true},
+						},
+					}}
+					return nil
+				})
+			mockReader.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&oav1alpha1.ManagedFleetNotificationRecordList{}), client.InNamespace(namespace)).DoAndReturn(
+				func(ctx context.Context, list *oav1alpha1.ManagedFleetNotificationRecordList, opts ...client.ListOption) error {
+					list.Items = []oav1alpha1.ManagedFleetNotificationRecord{{
+						Status: oav1alpha1.ManagedFleetNotificationRecordStatus{
+							ManagementCluster: "mc",
+							NotificationRecordByName: []oav1alpha1.NotificationRecordByName{{
+								NotificationName: "oidc-deleted",
+								NotificationRecordItems: []oav1alpha1.NotificationRecordItem{
+									{
+										HostedClusterID:               "hc-1",
+										FiringNotificationSentCount:   2,
+										ResolvedNotificationSentCount: 1,
+										LastTransitionTime:            &sentTime,
+									},
+									{
+										HostedClusterID:               "hc-2",
+										FiringNotificationSentCount:   1,
+										ResolvedNotificationSentCount: 1,
+										LastTransitionTime:            &recentTime,
+									},
+								},
+							}},
+						},
+					}}
+					return nil
+				})
+		})
+
+		AfterEach(func() {
+			ConfigureClusterMetrics(ClusterMetricsOptions{MaxClusters: DefaultMaxClusterSeries, TTL: DefaultClusterSeriesTTL})
+		})
+
+		It("aggregates the hosted clusters by default", func() {
+			expected := `
+# HELP ocm_agent_fleet_limited_support_active Number of hosted clusters in limited support for the fleetNotification template
+# TYPE ocm_agent_fleet_limited_support_active gauge
+ocm_agent_fleet_limited_support_active{hosted_cluster_id="",management_cluster_id="mc",template="oidc-deleted"} 1
+# HELP ocm_agent_fleet_notification_last_sent_timestamp_seconds Time the last notification was sent to a hosted cluster for the fleetNotification template
+# TYPE ocm_agent_fleet_notification_last_sent_timestamp_seconds gauge
+ocm_agent_fleet_notification_last_sent_timestamp_seconds{hosted_cluster_id="",management_cluster_id="mc",template="oidc-deleted"} 1.7000036e+09
+# HELP ocm_agent_fleet_notification_sent_total A total number of notifications sent to a hosted cluster for the fleetNotification template, by state of the alert
+# TYPE ocm_agent_fleet_notification_sent_total gauge
+ocm_agent_fleet_notification_sent_total{hosted_cluster_id="",management_cluster_id="mc",state="firing",template="oidc-deleted"} 3
+ocm_agent_fleet_notification_sent_total{hosted_cluster_id="",management_cluster_id="mc",state="resolved",template="oidc-deleted"} 2
+`
+			Expect(testutil.CollectAndCompare(collector, strings.NewReader(expected))).To(Succeed())
+		})
+
+		It("exposes the most recently notified hosted clusters when the per-cluster metrics are enabled", func() {
+			ConfigureClusterMetrics(ClusterMetricsOptions{Enabled: true, MaxClusters: 1, TTL: time.Hour})
+
+			expected := `
+# HELP ocm_agent_fleet_limited_support_active Number of hosted clusters in limited support for the fleetNotification template
+# TYPE ocm_agent_fleet_limited_support_active gauge
+ocm_agent_fleet_limited_support_active{hosted_cluster_id="",management_cluster_id="mc",template="oidc-deleted"} 1
+ocm_agent_fleet_limited_support_active{hosted_cluster_id="hc-2",management_cluster_id="mc",template="oidc-deleted"} 0
+# HELP ocm_agent_fleet_notification_last_sent_timestamp_seconds Time the last notification was sent to a hosted cluster for the fleetNotification template
+# TYPE ocm_agent_fleet_notification_last_sent_timestamp_seconds gauge
+ocm_agent_fleet_notification_last_sent_timestamp_seconds{hosted_cluster_id="",management_cluster_id="mc",template="oidc-deleted"} 1.7e+09
+ocm_agent_fleet_notification_last_sent_timestamp_seconds{hosted_cluster_id="hc-2",management_cluster_id="mc",template="oidc-deleted"} 1.7000036e+09
+# HELP ocm_agent_fleet_notification_sent_total A total number of notifications sent to a hosted cluster for the fleetNotification template, by state of the alert
+# TYPE ocm_agent_fleet_notification_sent_total gauge
+ocm_agent_fleet_notification_sent_total{hosted_cluster_id="",management_cluster_id="mc",state="firing",template="oidc-deleted"} 2
+ocm_agent_fleet_notification_sent_total{hosted_cluster_id="",management_cluster_id="mc",state="resolved",template="oidc-deleted"} 1
+ocm_agent_fleet_notification_sent_total{hosted_cluster_id="hc-2",management_cluster_id="mc",state="firing",template="oidc-deleted"} 1
+ocm_agent_fleet_notification_sent_total{hosted_cluster_id="hc-2",management_cluster_id="mc",state="resolved",template="oidc-deleted"} 1
+`
+			Expect(testutil.CollectAndCompare(collector, strings.NewReader(expected))).To(Succeed())
+		})
+	})
+})
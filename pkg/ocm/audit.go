@@ -0,0 +1,268 @@
+package ocm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	slv1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/openshift/ocm-agent/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// Outcomes of an audited OCM call
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+
+	// Reasons of the Events recorded for audited OCM calls
+	AuditEventReasonSucceeded = "OCMRequestSucceeded"
+	AuditEventReasonFailed    = "OCMRequestFailed"
+)
+
+// AuditRecord describes a single mutating call the agent made to OCM.
+type AuditRecord struct {
+	Time           time.Time               `json:"time"`
+	Caller         string                  `json:"caller"`
+	ClusterID      string                  `json:"cluster_id"`
+	Operation      string                  `json:"operation"`
+	OperationID    string                  `json:"operation_id,omitempty"`
+	Summary        string                  `json:"summary"`
+	Outcome        string                  `json:"outcome"`
+	Error          string                  `json:"error,omitempty"`
+	LatencySeconds float64                 `json:"latency_seconds"`
+	Object         *corev1.ObjectReference `json:"object,omitempty"`
+	CorrelationID  string                  `json:"correlation_id,omitempty"`
+}
+
+// AuditSink receives the records of audited OCM calls.
+type AuditSink interface {
+	Record(record AuditRecord)
+}
+
+// AuditSinks records to every sink in the list.
+type AuditSinks []AuditSink
+
+func (s AuditSinks) Record(record AuditRecord) {
+	for _, sink := range s {
+		sink.Record(record)
+	}
+}
+
+type jsonAuditSink struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewJSONAuditSink returns an AuditSink writing every record as a single JSON line to w.
+func NewJSONAuditSink(w io.Writer) AuditSink {
+	return &jsonAuditSink{encoder: json.NewEncoder(w)}
+}
+
+func (s *jsonAuditSink) Record(record AuditRecord) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.encoder.Encode(record); err != nil {
+		packageLogger.WithError(err).Error("Failed to write audit record")
+	}
+}
+
+type eventAuditSink struct {
+	recorder record.EventRecorder
+}
+
+// NewEventAuditSink returns an AuditSink recording a Kubernetes Event on the object related to the record,
+// such as the ManagedNotification or ManagedFleetNotificationRecord the call was made for.
+// Records without a related object are skipped.
+func NewEventAuditSink(recorder record.EventRecorder) AuditSink {
+	return &eventAuditSink{recorder: recorder}
+}
+
+func (s *eventAuditSink) Record(record AuditRecord) {
+	if record.Object == nil {
+		return
+	}
+
+	eventType, reason := corev1.EventTypeNormal, AuditEventReasonSucceeded
+	if record.Outcome != AuditOutcomeSuccess {
+		eventType, reason = corev1.EventTypeWarning, AuditEventReasonFailed
+	}
+	message := fmt.Sprintf("%s for cluster %s (operation ID %q)", record.Summary, record.ClusterID, record.OperationID)
+	if record.Error != "" {
+		message += ": " + record.Error
+	}
+	s.recorder.Event(record.Object, eventType, reason, message)
+}
+
+// AuditContext identifies who a mutating OCM call is made for.
+type AuditContext struct {
+	// Caller is the user or component the call is made on behalf of
+	Caller string
+	// Object is the Kubernetes object the call relates to, if any
+	Object *corev1.ObjectReference
+}
+
+// operationIDClient is implemented by OCM clients, and the decorators wrapping them, able to report the OCM operation ID of the
+// mutating calls whose OCMClient method doesn't return it.
+type operationIDClient interface {
+	sendServiceLog(logEntry *slv1.LogEntry) (string, error)
+	sendLimitedSupport(clusterUUID string, lsReason *cmv1.LimitedSupportReason) (string, error)
+	removeLimitedSupport(clusterUUID string, lsReasonID string) (string, error)
+}
+
+// auditingOCMClient wraps an OCMClient and records every mutating call to an AuditSink.
+// Read calls are forwarded unchanged.
+type auditingOCMClient struct {
+	OCMClient
+	sink    AuditSink
+	context AuditContext
+	ctx     context.Context
+	now     func() time.Time
+}
+
+// NewAuditingOcmClient returns an OCMClient recording the mutating calls of the given client to sink.
+func NewAuditingOcmClient(o OCMClient, sink AuditSink) OCMClient {
+	return &auditingOCMClient{
+		OCMClient: o,
+		sink:      sink,
+		ctx:       context.Background(),
+		now:       time.Now,
+	}
+}
+
+func (a *auditingOCMClient) withContext(ctx context.Context) OCMClient {
+	withContext := *a
+	withContext.ctx = ctx
+	withContext.OCMClient = WithContext(a.OCMClient, ctx)
+	return &withContext
+}
+
+// WithAuditContext returns a client recording its mutating calls for the given audit context.
+// Clients which aren't audited are returned as is, tracing clients are looked through.
+func WithAuditContext(o OCMClient, auditContext AuditContext) OCMClient {
+	if t, ok := o.(*tracingOCMClient); ok {
+		withContext := *t
+		withContext.OCMClient = WithAuditContext(t.OCMClient, auditContext)
+		return &withContext
+	}
+	a, ok := o.(*auditingOCMClient)
+	if !ok {
+		return o
+	}
+	withContext := *a
+	withContext.context = auditContext
+	return &withContext
+}
+
+// audit runs call and records its outcome and latency. The OCM operation ID and error of the call are returned.
+func (a *auditingOCMClient) audit(operation, clusterID, summary string, call func() (string, error)) (string, error) {
+	start := a.now()
+	operationID, err := call()
+
+	record := AuditRecord{
+		Time:           start.UTC(),
+		Caller:         a.context.Caller,
+		ClusterID:      clusterID,
+		Operation:      operation,
+		OperationID:    operationID,
+		Summary:        summary,
+		Outcome:        AuditOutcomeSuccess,
+		LatencySeconds: a.now().Sub(start).Seconds(),
+		Object:         a.context.Object,
+		CorrelationID:  logging.CorrelationID(a.ctx),
+	}
+	if err != nil {
+		record.Outcome = AuditOutcomeFailure
+		record.Error = err.Error()
+	}
+	a.sink.Record(record)
+
+	return operationID, err
+}
+
+func (a *auditingOCMClient) SendServiceLog(logEntry *slv1.LogEntry) error {
+	_, err := a.sendServiceLog(logEntry)
+	return err
+}
+
+func (a *auditingOCMClient) sendServiceLog(logEntry *slv1.LogEntry) (string, error) {
+	summary := fmt.Sprintf("Posted %s service log %q", logEntry.Severity(), logEntry.Summary())
+	return a.audit("SendServiceLog", logEntry.ClusterUUID(), summary, func() (string, error) {
+		if o, ok := a.OCMClient.(operationIDClient); ok {
+			return o.sendServiceLog(logEntry)
+		}
+		return "", a.OCMClient.SendServiceLog(logEntry)
+	})
+}
+
+func (a *auditingOCMClient) SendLimitedSupport(clusterUUID string, lsReason *cmv1.LimitedSupportReason) error {
+	_, err := a.sendLimitedSupport(clusterUUID, lsReason)
+	return err
+}
+
+func (a *auditingOCMClient) sendLimitedSupport(clusterUUID string, lsReason *cmv1.LimitedSupportReason) (string, error) {
+	summary := fmt.Sprintf("Added limited support reason %q", lsReason.Summary())
+	return a.audit("SendLimitedSupport", clusterUUID, summary, func() (string, error) {
+		if o, ok := a.OCMClient.(operationIDClient); ok {
+			return o.sendLimitedSupport(clusterUUID, lsReason)
+		}
+		return "", a.OCMClient.SendLimitedSupport(clusterUUID, lsReason)
+	})
+}
+
+func (a *auditingOCMClient) RemoveLimitedSupport(clusterUUID string, lsReasonID string) error {
+	_, err := a.removeLimitedSupport(clusterUUID, lsReasonID)
+	return err
+}
+
+func (a *auditingOCMClient) removeLimitedSupport(clusterUUID string, lsReasonID string) (string, error) {
+	summary := fmt.Sprintf("Removed limited support reason %s", lsReasonID)
+	return a.audit("RemoveLimitedSupport", clusterUUID, summary, func() (string, error) {
+		if o, ok := a.OCMClient.(operationIDClient); ok {
+			return o.removeLimitedSupport(clusterUUID, lsReasonID)
+		}
+		return "", a.OCMClient.RemoveLimitedSupport(clusterUUID, lsReasonID)
+	})
+}
+
+func (a *auditingOCMClient) UpdateUpgradePolicyState(clusterID string, upgradePolicyID string, policyState *cmv1.UpgradePolicyState) (*cmv1.UpgradePolicyState, string, error) {
+	var updatedState *cmv1.UpgradePolicyState
+	summary := fmt.Sprintf("Set state of upgrade policy %s to %q", upgradePolicyID, policyState.Value())
+	operationID, err := a.audit("UpdateUpgradePolicyState", clusterID, summary, func() (operationID string, err error) {
+		updatedState, operationID, err = a.OCMClient.UpdateUpgradePolicyState(clusterID, upgradePolicyID, policyState)
+		return operationID, err
+	})
+	return updatedState, operationID, err
+}
+
+func (a *auditingOCMClient) CreateUpgradePolicy(clusterID string, policy *cmv1.UpgradePolicy) (*cmv1.UpgradePolicy, string, error) {
+	var createdPolicy *cmv1.UpgradePolicy
+	summary := fmt.Sprintf("Created %s upgrade policy to version %q", policy.ScheduleType(), policy.Version())
+	operationID, err := a.audit("CreateUpgradePolicy", clusterID, summary, func() (operationID string, err error) {
+		createdPolicy, operationID, err = a.OCMClient.CreateUpgradePolicy(clusterID, policy)
+		return operationID, err
+	})
+	return createdPolicy, operationID, err
+}
+
+func (a *auditingOCMClient) UpdateUpgradePolicy(clusterID string, upgradePolicyID string, policy *cmv1.UpgradePolicy) (*cmv1.UpgradePolicy, string, error) {
+	var updatedPolicy *cmv1.UpgradePolicy
+	summary := fmt.Sprintf("Updated upgrade policy %s", upgradePolicyID)
+	operationID, err := a.audit("UpdateUpgradePolicy", clusterID, summary, func() (operationID string, err error) {
+		updatedPolicy, operationID, err = a.OCMClient.UpdateUpgradePolicy(clusterID, upgradePolicyID, policy)
+		return operationID, err
+	})
+	return updatedPolicy, operationID, err
+}
+
+func (a *auditingOCMClient) DeleteUpgradePolicy(clusterID string, upgradePolicyID string) (string, error) {
+	summary := fmt.Sprintf("Deleted upgrade policy %s", upgradePolicyID)
+	return a.audit("DeleteUpgradePolicy", clusterID, summary, func() (string, error) {
+		return a.OCMClient.DeleteUpgradePolicy(clusterID, upgradePolicyID)
+	})
+}
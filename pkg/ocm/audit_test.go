@@ -0,0 +1,154 @@
+package ocm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/ghttp"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	slv1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	. "github.com/openshift-online/ocm-sdk-go/testing"
+	"github.com/openshift/ocm-agent/pkg/logging"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// recordingAuditSink keeps the records in memory
+type recordingAuditSink struct {
+	records []AuditRecord
+}
+
+func (s *recordingAuditSink) Record(record AuditRecord) {
+	s.records = append(s.records, record)
+}
+
+// fakeWriteOCMClient answers the upgrade policy state update with the configured error
+type fakeWriteOCMClient struct {
+	OCMClient
+	err error
+}
+
+func (f *fakeWriteOCMClient) UpdateUpgradePolicyState(clusterID string, upgradePolicyID string, policyState *cmv1.UpgradePolicyState) (*cmv1.UpgradePolicyState, string, error) {
+	if f.err != nil {
+		return nil, "op-failed", f.err
+	}
+	return policyState, "op-update", nil
+}
+
+var _ = Describe("Auditing OCM client", func() {
+	var (
+		sink        *recordingAuditSink
+		policyState *cmv1.UpgradePolicyState
+		object      *corev1.ObjectReference
+	)
+
+	BeforeEach(func() {
+		sink = &recordingAuditSink{}
+		policyState, _ = cmv1.NewUpgradePolicyState().Value(cmv1.UpgradePolicyStateValueCancelled).Build()
+		object = &corev1.ObjectReference{Kind: "ManagedFleetNotificationRecord", Namespace: "openshift-ocm-agent-operator", Name: "mc-id"}
+	})
+
+	It("records the caller, cluster, operation ID and outcome of mutating calls", func() {
+		client := WithAuditContext(NewAuditingOcmClient(&fakeWriteOCMClient{}, sink), AuditContext{Caller: "system:serviceaccount:muo:muo", Object: object})
+
+		_, operationID, err := client.UpdateUpgradePolicyState("cluster-id", "policy-id", policyState)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(operationID).To(Equal("op-update"))
+
+		Expect(sink.records).To(HaveLen(1))
+		record := sink.records[0]
+		Expect(record.Caller).To(Equal("system:serviceaccount:muo:muo"))
+		Expect(record.ClusterID).To(Equal("cluster-id"))
+		Expect(record.Operation).To(Equal("UpdateUpgradePolicyState"))
+		Expect(record.OperationID).To(Equal("op-update"))
+		Expect(record.Summary).To(Equal(`Set state of upgrade policy policy-id to "cancelled"`))
+		Expect(record.Outcome).To(Equal(AuditOutcomeSuccess))
+		Expect(record.Object).To(Equal(object))
+		Expect(record.LatencySeconds).To(BeNumerically(">=", 0))
+	})
+
+	It("records failed calls with their error", func() {
+		client := NewAuditingOcmClient(&fakeWriteOCMClient{err: errors.New("unexpected status: 500")}, sink)
+
+		_, _, err := client.UpdateUpgradePolicyState("cluster-id", "policy-id", policyState)
+		Expect(err).To(MatchError("unexpected status: 500"))
+
+		Expect(sink.records).To(HaveLen(1))
+		Expect(sink.records[0].Outcome).To(Equal(AuditOutcomeFailure))
+		Expect(sink.records[0].Error).To(Equal("unexpected status: 500"))
+		Expect(sink.records[0].OperationID).To(Equal("op-failed"))
+	})
+
+	It("records the correlation ID of the request the call is made for", func() {
+		ctx := logging.WithFields(context.Background(), log.Fields{logging.FieldCorrelationID: "correlation-id"})
+		client := WithContext(NewAuditingOcmClient(&fakeWriteOCMClient{}, sink), ctx)
+
+		_, _, err := client.UpdateUpgradePolicyState("cluster-id", "policy-id", policyState)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(sink.records).To(HaveLen(1))
+		Expect(sink.records[0].CorrelationID).To(Equal("correlation-id"))
+	})
+
+	It("doesn't change the audit context of clients that aren't audited", func() {
+		client := &fakeWriteOCMClient{}
+		Expect(WithAuditContext(client, AuditContext{Caller: "someone"})).To(BeIdenticalTo(client))
+	})
+
+	It("records the OCM operation ID of service log posts", func() {
+		mockServer := NewServer()
+		defer mockServer.Close()
+		mockServer.AppendHandlers(
+			RespondWith(http.StatusCreated, `{}`, http.Header{
+				"Content-Type":       []string{"application/json"},
+				OcmOperationIdHeader: []string{"op-service-log"},
+			}),
+		)
+		connection, err := sdk.NewConnectionBuilder().
+			URL(mockServer.URL()).
+			Tokens(MakeTokenString("Bearer", 15*time.Minute)).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		logEntry, _ := slv1.NewLogEntry().ClusterUUID("cluster-uuid").Summary("Test summary").Severity(slv1.SeverityWarning).Build()
+		err = NewAuditingOcmClient(NewOcmClient(connection), sink).SendServiceLog(logEntry)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(sink.records).To(HaveLen(1))
+		Expect(sink.records[0].ClusterID).To(Equal("cluster-uuid"))
+		Expect(sink.records[0].OperationID).To(Equal("op-service-log"))
+		Expect(sink.records[0].Summary).To(Equal(`Posted Warning service log "Test summary"`))
+	})
+
+	It("writes records as JSON lines", func() {
+		var out bytes.Buffer
+		jsonSink := NewJSONAuditSink(&out)
+		jsonSink.Record(AuditRecord{Caller: "a", Outcome: AuditOutcomeSuccess})
+		jsonSink.Record(AuditRecord{Caller: "b", Outcome: AuditOutcomeFailure})
+
+		lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+		Expect(lines).To(HaveLen(2))
+		var record AuditRecord
+		Expect(json.Unmarshal(lines[1], &record)).To(Succeed())
+		Expect(record.Caller).To(Equal("b"))
+	})
+
+	It("records Events on the related object", func() {
+		recorder := record.NewFakeRecorder(2)
+		eventSink := NewEventAuditSink(recorder)
+
+		eventSink.Record(AuditRecord{Summary: "Removed limited support reason 123", ClusterID: "hc-id", OperationID: "op", Outcome: AuditOutcomeFailure, Error: "boom", Object: object})
+		eventSink.Record(AuditRecord{Summary: "Without object", Outcome: AuditOutcomeSuccess})
+
+		Expect(recorder.Events).To(HaveLen(1))
+		Expect(<-recorder.Events).To(Equal(`Warning OCMRequestFailed Removed limited support reason 123 for cluster hc-id (operation ID "op"): boom`))
+	})
+})
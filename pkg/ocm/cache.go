@@ -0,0 +1,217 @@
+package ocm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+// StaleResponseError is returned alongside cached data when OCM could not be reached and the
+// cached value is older than the cache TTL. Callers may still serve the returned value.
+type StaleResponseError struct {
+	Err error
+	Age time.Duration
+}
+
+func (e *StaleResponseError) Error() string {
+	return fmt.Sprintf("serving stale response (age %s): %v", e.Age.Round(time.Second), e.Err)
+}
+
+func (e *StaleResponseError) Unwrap() error {
+	return e.Err
+}
+
+// IsStaleResponse returns true if the error indicates that the returned value is stale cached data.
+func IsStaleResponse(err error) bool {
+	var staleErr *StaleResponseError
+	return errors.As(err, &staleErr)
+}
+
+type cacheEntry struct {
+	value       interface{}
+	operationID string
+	fetchedAt   time.Time
+}
+
+// cachingOCMClient wraps an OCMClient and caches the results of the read-only cluster and
+// upgrade policy calls. Mutating calls are always forwarded and invalidate the cluster's upgrade policy entries.
+type cachingOCMClient struct {
+	OCMClient
+	ttl      time.Duration
+	maxStale time.Duration
+	now      func() time.Time
+
+	ctx context.Context
+
+	// Shared by the copies made for request contexts
+	mutex   *sync.Mutex
+	entries map[string]cacheEntry
+	// generation is incremented by every invalidation, the responses of the calls sent before are not cached
+	generation *uint64
+}
+
+// NewCachingOcmClient returns an OCMClient which caches GetCluster and the upgrade policy read calls
+// of the given client for ttl. When OCM is unavailable, cached entries younger than maxStale are returned
+// together with a StaleResponseError.
+func NewCachingOcmClient(o OCMClient, ttl, maxStale time.Duration) OCMClient {
+	return &cachingOCMClient{
+		OCMClient:  o,
+		ttl:        ttl,
+		maxStale:   maxStale,
+		now:        time.Now,
+		ctx:        context.Background(),
+		mutex:      &sync.Mutex{},
+		entries:    make(map[string]cacheEntry),
+		generation: new(uint64),
+	}
+}
+
+func (c *cachingOCMClient) withContext(ctx context.Context) OCMClient {
+	withContext := *c
+	withContext.ctx = ctx
+	withContext.OCMClient = WithContext(c.OCMClient, ctx)
+	return &withContext
+}
+
+func clusterCacheKey(clusterID string) string {
+	return "cluster/" + clusterID
+}
+
+func upgradePoliciesCacheKey(clusterID string) string {
+	return "upgrade_policies/" + clusterID
+}
+
+// upgradePolicyListCacheKey identifies a list request by its cluster and list options.
+func upgradePolicyListCacheKey(clusterID string, opts ListOptions) string {
+	return fmt.Sprintf("%s?page=%d&size=%d&search=%s&order=%s", upgradePoliciesCacheKey(clusterID), opts.Page, opts.Size, opts.Search, opts.Order)
+}
+
+func upgradePolicyCacheKey(clusterID, upgradePolicyID string) string {
+	return upgradePoliciesCacheKey(clusterID) + "/" + upgradePolicyID
+}
+
+func upgradePolicyStateCacheKey(clusterID, upgradePolicyID string) string {
+	return upgradePolicyCacheKey(clusterID, upgradePolicyID) + "/state"
+}
+
+// get returns the cached value for key if it is still fresh, otherwise it calls fetch.
+// If fetch fails because OCM is unavailable and a cached value younger than maxStale exists, that value
+// is returned with a StaleResponseError wrapping the fetch error.
+func (c *cachingOCMClient) get(key string, fetch func() (interface{}, string, error)) (interface{}, string, error) {
+	c.mutex.Lock()
+	entry, found := c.entries[key]
+	generation := *c.generation
+	c.mutex.Unlock()
+
+	if found && c.now().Sub(entry.fetchedAt) < c.ttl {
+		packageLogger.FromContext(c.ctx).WithField("key", key).Debug("Serving OCM response from cache")
+		return entry.value, entry.operationID, nil
+	}
+
+	value, operationID, err := fetch()
+	if err != nil {
+		age := c.now().Sub(entry.fetchedAt)
+		if found && age < c.maxStale && isUnavailable(err) {
+			packageLogger.FromContext(c.ctx).WithError(err).WithField("key", key).Warn("OCM request failed, serving stale response from cache")
+			return entry.value, entry.operationID, &StaleResponseError{Err: err, Age: age}
+		}
+		return nil, operationID, err
+	}
+
+	// A response fetched while an entry was invalidated may predate the change that invalidated it
+	c.mutex.Lock()
+	if *c.generation == generation {
+		c.entries[key] = cacheEntry{value: value, operationID: operationID, fetchedAt: c.now()}
+	}
+	c.mutex.Unlock()
+
+	return value, operationID, nil
+}
+
+// isUnavailable reports whether a call failed because OCM couldn't be reached, failed or rate limited the agent,
+// in which case stale data may be served. Other client errors, e.g. a deleted policy, are returned to the caller.
+func isUnavailable(err error) bool {
+	status := errorStatus(err)
+	return status == 0 || status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// invalidateUpgradePolicies removes the upgrade policy lists and every single policy or policy state entry of a cluster.
+// The responses of the calls in flight, of any cluster, aren't cached either.
+func (c *cachingOCMClient) invalidateUpgradePolicies(clusterID string) {
+	prefix := upgradePoliciesCacheKey(clusterID)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	*c.generation++
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix+"?") || strings.HasPrefix(key, prefix+"/") {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func (c *cachingOCMClient) GetCluster(clusterID string) (*cmv1.Cluster, string, error) {
+	value, operationID, err := c.get(clusterCacheKey(clusterID), func() (interface{}, string, error) {
+		return c.OCMClient.GetCluster(clusterID)
+	})
+	cluster, _ := value.(*cmv1.Cluster)
+	return cluster, operationID, err
+}
+
+func (c *cachingOCMClient) GetUpgradePolicy(clusterID string, upgradePolicyID string) (*cmv1.UpgradePolicy, string, error) {
+	value, operationID, err := c.get(upgradePolicyCacheKey(clusterID, upgradePolicyID), func() (interface{}, string, error) {
+		return c.OCMClient.GetUpgradePolicy(clusterID, upgradePolicyID)
+	})
+	policy, _ := value.(*cmv1.UpgradePolicy)
+	return policy, operationID, err
+}
+
+func (c *cachingOCMClient) GetUpgradePolicyState(clusterID string, upgradePolicyID string) (*cmv1.UpgradePolicyState, string, error) {
+	value, operationID, err := c.get(upgradePolicyStateCacheKey(clusterID, upgradePolicyID), func() (interface{}, string, error) {
+		return c.OCMClient.GetUpgradePolicyState(clusterID, upgradePolicyID)
+	})
+	policyState, _ := value.(*cmv1.UpgradePolicyState)
+	return policyState, operationID, err
+}
+
+func (c *cachingOCMClient) GetUpgradePolicies(clusterID string, opts ListOptions) ([]*cmv1.UpgradePolicy, string, error) {
+	value, operationID, err := c.get(upgradePolicyListCacheKey(clusterID, opts), func() (interface{}, string, error) {
+		return c.OCMClient.GetUpgradePolicies(clusterID, opts)
+	})
+	policies, _ := value.([]*cmv1.UpgradePolicy)
+	return policies, operationID, err
+}
+
+// UpdateUpgradePolicyState forwards the update and drops every cached upgrade policy entry of the cluster,
+// as a state change may also be reflected in the policies themselves. The entries are dropped before the
+// update, so that they're not served while it's sent, and after it, so that the responses of the calls
+// sent meanwhile aren't cached.
+func (c *cachingOCMClient) UpdateUpgradePolicyState(clusterID string, upgradePolicyID string, policyState *cmv1.UpgradePolicyState) (*cmv1.UpgradePolicyState, string, error) {
+	c.invalidateUpgradePolicies(clusterID)
+	defer c.invalidateUpgradePolicies(clusterID)
+	return c.OCMClient.UpdateUpgradePolicyState(clusterID, upgradePolicyID, policyState)
+}
+
+func (c *cachingOCMClient) CreateUpgradePolicy(clusterID string, policy *cmv1.UpgradePolicy) (*cmv1.UpgradePolicy, string, error) {
+	c.invalidateUpgradePolicies(clusterID)
+	defer c.invalidateUpgradePolicies(clusterID)
+	return c.OCMClient.CreateUpgradePolicy(clusterID, policy)
+}
+
+func (c *cachingOCMClient) UpdateUpgradePolicy(clusterID string, upgradePolicyID string, policy *cmv1.UpgradePolicy) (*cmv1.UpgradePolicy, string, error) {
+	c.invalidateUpgradePolicies(clusterID)
+	defer c.invalidateUpgradePolicies(clusterID)
+	return c.OCMClient.UpdateUpgradePolicy(clusterID, upgradePolicyID, policy)
+}
+
+func (c *cachingOCMClient) DeleteUpgradePolicy(clusterID string, upgradePolicyID string) (string, error) {
+	c.invalidateUpgradePolicies(clusterID)
+	defer c.invalidateUpgradePolicies(clusterID)
+	return c.OCMClient.DeleteUpgradePolicy(clusterID, upgradePolicyID)
+}
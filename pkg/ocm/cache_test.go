@@ -0,0 +1,202 @@
+package ocm
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	ocmerrors "github.com/openshift-online/ocm-sdk-go/errors"
+)
+
+// fakeReadOCMClient counts the read calls and returns the configured values
+type fakeReadOCMClient struct {
+	OCMClient
+	calls       int
+	err         error
+	cluster     *cmv1.Cluster
+	policies    []*cmv1.UpgradePolicy
+	policyState *cmv1.UpgradePolicyState
+	// onRead is called by the read calls before they return, as if OCM was answering meanwhile
+	onRead func()
+}
+
+func (f *fakeReadOCMClient) GetCluster(clusterID string) (*cmv1.Cluster, string, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, "op-error", f.err
+	}
+	return f.cluster, "op-cluster", nil
+}
+
+func (f *fakeReadOCMClient) GetUpgradePolicies(clusterID string, opts ListOptions) ([]*cmv1.UpgradePolicy, string, error) {
+	f.calls++
+	if f.onRead != nil {
+		f.onRead()
+	}
+	if f.err != nil {
+		return nil, "op-error", f.err
+	}
+	return f.policies, "op-policies", nil
+}
+
+func (f *fakeReadOCMClient) GetUpgradePolicyState(clusterID string, upgradePolicyID string) (*cmv1.UpgradePolicyState, string, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, "op-error", f.err
+	}
+	return f.policyState, "op-state", nil
+}
+
+func (f *fakeReadOCMClient) UpdateUpgradePolicyState(clusterID string, upgradePolicyID string, policyState *cmv1.UpgradePolicyState) (*cmv1.UpgradePolicyState, string, error) {
+	return policyState, "op-update", nil
+}
+
+func (f *fakeReadOCMClient) DeleteUpgradePolicy(clusterID string, upgradePolicyID string) (string, error) {
+	return "op-delete", nil
+}
+
+var _ = Describe("Caching OCM client", func() {
+	var (
+		fake    *fakeReadOCMClient
+		client  *cachingOCMClient
+		nowTime time.Time
+	)
+
+	BeforeEach(func() {
+		cluster, _ := cmv1.NewCluster().ID("cluster-id").Build()
+		policy, _ := cmv1.NewUpgradePolicy().ID("policy-id").Build()
+		policyState, _ := cmv1.NewUpgradePolicyState().Value(cmv1.UpgradePolicyStateValuePending).Build()
+		fake = &fakeReadOCMClient{cluster: cluster, policies: []*cmv1.UpgradePolicy{policy}, policyState: policyState}
+
+		nowTime = time.Now()
+		client = NewCachingOcmClient(fake, time.Minute, 10*time.Minute).(*cachingOCMClient)
+		client.now = func() time.Time { return nowTime }
+	})
+
+	It("serves fresh entries from the cache", func() {
+		for i := 0; i < 3; i++ {
+			cluster, opID, err := client.GetCluster("cluster-id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(opID).To(Equal("op-cluster"))
+			Expect(cluster.ID()).To(Equal("cluster-id"))
+		}
+		Expect(fake.calls).To(Equal(1))
+	})
+
+	It("refreshes entries older than the TTL", func() {
+		_, _, _ = client.GetUpgradePolicies("cluster-id", ListOptions{})
+		nowTime = nowTime.Add(2 * time.Minute)
+		_, _, _ = client.GetUpgradePolicies("cluster-id", ListOptions{})
+		Expect(fake.calls).To(Equal(2))
+	})
+
+	It("caches upgrade policy lists per list options", func() {
+		_, _, _ = client.GetUpgradePolicies("cluster-id", ListOptions{})
+		_, _, _ = client.GetUpgradePolicies("cluster-id", ListOptions{Page: 2, Size: 10})
+		_, _, _ = client.GetUpgradePolicies("cluster-id", ListOptions{Page: 2, Size: 10})
+		Expect(fake.calls).To(Equal(2))
+	})
+
+	It("serves stale entries with a StaleResponseError when OCM fails", func() {
+		_, _, _ = client.GetCluster("cluster-id")
+		nowTime = nowTime.Add(5 * time.Minute)
+		fake.err = errors.New("ocm unavailable")
+
+		cluster, opID, err := client.GetCluster("cluster-id")
+		Expect(IsStaleResponse(err)).To(BeTrue())
+		Expect(errors.Unwrap(err)).To(MatchError("ocm unavailable"))
+		Expect(opID).To(Equal("op-cluster"))
+		Expect(cluster.ID()).To(Equal("cluster-id"))
+	})
+
+	It("serves stale entries when OCM answers with a server error or rate limits", func() {
+		_, _, _ = client.GetCluster("cluster-id")
+		nowTime = nowTime.Add(5 * time.Minute)
+
+		for _, status := range []int{http.StatusServiceUnavailable, http.StatusTooManyRequests} {
+			fake.err = &unexpectedStatusError{status: status}
+			cluster, _, err := client.GetCluster("cluster-id")
+			Expect(IsStaleResponse(err)).To(BeTrue())
+			Expect(cluster.ID()).To(Equal("cluster-id"))
+		}
+	})
+
+	It("returns the OCM client errors rather than stale entries", func() {
+		_, _, _ = client.GetCluster("cluster-id")
+		nowTime = nowTime.Add(5 * time.Minute)
+
+		sdkErr, err := ocmerrors.NewError().Status(http.StatusNotFound).Reason("Cluster not found").Build()
+		Expect(err).ToNot(HaveOccurred())
+		for _, fake.err = range []error{sdkErr, &unexpectedStatusError{status: http.StatusNotFound}} {
+			cluster, opID, err := client.GetCluster("cluster-id")
+			Expect(IsStaleResponse(err)).To(BeFalse())
+			Expect(err).To(MatchError(fake.err))
+			Expect(opID).To(Equal("op-error"))
+			Expect(cluster).To(BeNil())
+		}
+	})
+
+	It("returns the OCM error when the cached entry is too old", func() {
+		_, _, _ = client.GetCluster("cluster-id")
+		nowTime = nowTime.Add(11 * time.Minute)
+		fake.err = errors.New("ocm unavailable")
+
+		cluster, opID, err := client.GetCluster("cluster-id")
+		Expect(IsStaleResponse(err)).To(BeFalse())
+		Expect(err).To(MatchError("ocm unavailable"))
+		Expect(opID).To(Equal("op-error"))
+		Expect(cluster).To(BeNil())
+	})
+
+	It("does not cache errors", func() {
+		fake.err = errors.New("ocm unavailable")
+		_, _, err := client.GetCluster("cluster-id")
+		Expect(err).To(HaveOccurred())
+
+		fake.err = nil
+		_, _, err = client.GetCluster("cluster-id")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fake.calls).To(Equal(2))
+	})
+
+	It("invalidates the cluster's upgrade policy entries on state update", func() {
+		_, _, _ = client.GetUpgradePolicies("cluster-id", ListOptions{})
+		_, _, _ = client.GetUpgradePolicyState("cluster-id", "policy-id")
+		_, _, _ = client.GetCluster("cluster-id")
+		Expect(fake.calls).To(Equal(3))
+
+		_, _, err := client.UpdateUpgradePolicyState("cluster-id", "policy-id", fake.policyState)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, _, _ = client.GetUpgradePolicies("cluster-id", ListOptions{})
+		_, _, _ = client.GetUpgradePolicyState("cluster-id", "policy-id")
+		_, _, _ = client.GetCluster("cluster-id")
+		Expect(fake.calls).To(Equal(5))
+	})
+
+	It("doesn't cache the responses of the calls sent while the entries are invalidated", func() {
+		fake.onRead = func() {
+			fake.onRead = nil
+			_, err := client.DeleteUpgradePolicy("cluster-id", "policy-id")
+			Expect(err).ToNot(HaveOccurred())
+		}
+		_, _, _ = client.GetUpgradePolicies("cluster-id", ListOptions{})
+
+		_, _, _ = client.GetUpgradePolicies("cluster-id", ListOptions{})
+		_, _, _ = client.GetUpgradePolicies("cluster-id", ListOptions{})
+		Expect(fake.calls).To(Equal(2))
+	})
+
+	It("invalidates the cluster's upgrade policy entries when a policy is deleted", func() {
+		_, _, _ = client.GetUpgradePolicies("cluster-id", ListOptions{})
+
+		_, err := client.DeleteUpgradePolicy("cluster-id", "policy-id")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, _, _ = client.GetUpgradePolicies("cluster-id", ListOptions{})
+		Expect(fake.calls).To(Equal(2))
+	})
+})
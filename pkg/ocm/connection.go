@@ -1,17 +1,46 @@
 package ocm
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	sdk "github.com/openshift-online/ocm-sdk-go"
-	log "github.com/sirupsen/logrus"
 )
 
+const (
+	// DefaultRetryLimit is the number of times the SDK retries a failed OCM call by default
+	DefaultRetryLimit = 2
+	// DefaultRetryInterval is the time before the first retry of a failed OCM call by default
+	DefaultRetryInterval = time.Second
+	// DefaultRetryJitter is the random variation of the interval between retries by default
+	DefaultRetryJitter = 0.2
+)
+
+// RetryPolicy configures how the SDK retries failed OCM calls, the interval is doubled on every retry.
+type RetryPolicy struct {
+	Limit    int
+	Interval time.Duration
+	Jitter   float64
+}
+
+// DefaultRetryPolicy returns the retry policy of the SDK.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{Limit: DefaultRetryLimit, Interval: DefaultRetryInterval, Jitter: DefaultRetryJitter}
+}
+
+// Apply configures the SDK connection builder with the retry policy.
+func (p RetryPolicy) Apply(builder *sdk.ConnectionBuilder) *sdk.ConnectionBuilder {
+	return builder.RetryLimit(p.Limit).RetryInterval(p.Interval).RetryJitter(p.Jitter)
+}
+
 // ConnectionBuilder contains the information and logic needed to build a connection to OCM. Don't
 // create instances of this type directly; use the NewConnection function instead.
 type ConnectionBuilder struct {
 	logger           *sdk.Logger
 	transportWrapper sdk.TransportWrapper
+	retryPolicy      *RetryPolicy
+	transport        *TransportOptions
 }
 
 // NewConnection creates a builder that can then be used to configure and build an OCM connection.
@@ -36,6 +65,12 @@ func (b *ConnectionBuilder) Build(baseUrl string, clusterId string, accessToken
 	if b.transportWrapper != nil {
 		builder.TransportWrapper(b.transportWrapper)
 	}
+	if b.retryPolicy != nil {
+		b.retryPolicy.Apply(builder)
+	}
+	if b.transport != nil {
+		b.transport.Apply(builder)
+	}
 
 	// Create the connection:
 	result, err = builder.Build()
@@ -56,22 +91,36 @@ func (b *ConnectionBuilder) TransportWrapper(wrapper sdk.TransportWrapper) *Conn
 	return b
 }
 
+func (b *ConnectionBuilder) RetryPolicy(policy RetryPolicy) *ConnectionBuilder {
+	b.retryPolicy = &policy
+	return b
+}
+
+func (b *ConnectionBuilder) Transport(options TransportOptions) *ConnectionBuilder {
+	b.transport = &options
+	return b
+}
+
 // Adapted from https://github.com/gdbranco/rosa/blob/9c5d9a00eef233a7989aca5ddca6762dc0f4d01d/pkg/ocm/clusters.go#L371
 func GetInternalIDByExternalID(externalID string, ocm *sdk.Connection) (string, error) {
-	log.Debugf("Getting internal ID from external ID %s", externalID)
+	return getInternalIDByExternalID(context.Background(), externalID, ocm)
+}
+
+func getInternalIDByExternalID(ctx context.Context, externalID string, ocm *sdk.Connection) (string, error) {
+	packageLogger.FromContext(ctx).Debugf("Getting internal ID from external ID %s", externalID)
 	query := fmt.Sprintf("external_id = '%s'", externalID)
 
 	response, err := ocm.ClustersMgmt().V1().Clusters().List().
 		Search(query).
 		Page(1).
 		Size(1).
-		Send()
+		SendContext(ctx)
 	if err != nil {
-		log.Error(err)
+		packageLogger.Error(err)
 		return "", err
 	}
 	if response.Total() < 1 {
-		log.Errorf("Cluster with external id %s not found in OCM database.", externalID)
+		packageLogger.Errorf("Cluster with external id %s not found in OCM database.", externalID)
 		return "", fmt.Errorf("cluster with external id %s not found in OCM database", externalID)
 	}
 	cluster := response.Items().Slice()[0]
@@ -0,0 +1,116 @@
+package fake
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Fault makes the fake delay or fail the requests of an operation.
+type Fault struct {
+	// Operation is the operation of the requests the fault applies to, all operations when empty
+	Operation string `json:"operation,omitempty"`
+	// Status is the HTTP status returned instead of handling the request, only the latency is applied when zero
+	Status int `json:"status,omitempty"`
+	// Latency is the time waited before answering the request
+	Latency metav1.Duration `json:"latency,omitempty"`
+	// Times is the number of requests the fault applies to before it is removed, unlimited when zero
+	Times int `json:"times,omitempty"`
+	// Rate is the probability for a matching request to be faulted, from 0 to 1, all requests when zero
+	Rate float64 `json:"rate,omitempty"`
+	// RetryAfter is the number of seconds sent in the Retry-After header of 429 responses
+	RetryAfter int `json:"retryAfter,omitempty"`
+}
+
+// Validate checks that the fault can be applied.
+func (f Fault) Validate() error {
+	if f.Status != 0 && (f.Status < 100 || f.Status > 599) {
+		return fmt.Errorf("invalid status %d", f.Status)
+	}
+	if f.Status == 0 && f.Latency.Duration <= 0 {
+		return fmt.Errorf("fault requires a status or a latency")
+	}
+	if f.Latency.Duration < 0 || f.Times < 0 || f.RetryAfter < 0 {
+		return fmt.Errorf("latency, times and retryAfter can't be negative")
+	}
+	if f.Rate < 0 || f.Rate > 1 {
+		return fmt.Errorf("rate must be between 0 and 1")
+	}
+	return nil
+}
+
+// Faults returns the faults still to be applied, in the order they are matched.
+func (s *Server) Faults() []Fault {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	faults := []Fault{}
+	for _, f := range s.faults {
+		faults = append(faults, *f)
+	}
+	return faults
+}
+
+// AddFault adds a fault, matched after the existing ones.
+func (s *Server) AddFault(fault Fault) error {
+	if err := fault.Validate(); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.faults = append(s.faults, &fault)
+	return nil
+}
+
+// SetFaults replaces the faults.
+func (s *Server) SetFaults(faults []Fault) error {
+	var added []*Fault
+	for i := range faults {
+		if err := faults[i].Validate(); err != nil {
+			return fmt.Errorf("fault %d: %w", i, err)
+		}
+		fault := faults[i]
+		added = append(added, &fault)
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.faults = added
+	return nil
+}
+
+// ClearFaults removes the faults.
+func (s *Server) ClearFaults() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.faults = nil
+}
+
+// takeFault returns a copy of the first fault applying to a request of the operation, and removes it once it has been
+// applied the given number of times. It returns nil when no fault applies.
+func (s *Server) takeFault(operation string) *Fault {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i, f := range s.faults {
+		if f.Operation != "" && f.Operation != operation {
+			continue
+		}
+		if f.Rate > 0 && rand.Float64() >= f.Rate { //#nosec G404 -- Faults don't need a secure random source
+			continue
+		}
+		applied := *f
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return &applied
+	}
+	return nil
+}
+
+// isRateLimit returns whether the fault answers with too many requests.
+func (f Fault) isRateLimit() bool {
+	return f.Status == http.StatusTooManyRequests
+}
@@ -0,0 +1,249 @@
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	slv1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+)
+
+// InspectionPathPrefix is the path prefix of the inspection API of the fake, which isn't recorded nor faulted
+const InspectionPathPrefix = "/_fake"
+
+// Call is a request received by the fake for an OCM operation.
+type Call struct {
+	Time        time.Time       `json:"time"`
+	Operation   string          `json:"operation"`
+	Method      string          `json:"method"`
+	Path        string          `json:"path"`
+	Query       string          `json:"query,omitempty"`
+	Body        json.RawMessage `json:"body,omitempty"`
+	Status      int             `json:"status"`
+	OperationID string          `json:"operationID"`
+	Faulted     bool            `json:"faulted,omitempty"`
+	Duration    time.Duration   `json:"duration"`
+}
+
+// State is the content of the fake as returned by the inspection API, with the OCM resources as sent by OCM.
+type State struct {
+	Clusters    []ClusterState           `json:"clusters"`
+	ServiceLogs []map[string]interface{} `json:"serviceLogs"`
+}
+
+// ClusterState is a cluster of the fake and its subresources.
+type ClusterState struct {
+	Cluster               map[string]interface{}   `json:"cluster"`
+	LimitedSupportReasons []map[string]interface{} `json:"limitedSupportReasons"`
+	UpgradePolicies       []UpgradePolicyState     `json:"upgradePolicies"`
+}
+
+// UpgradePolicyState is an upgrade policy of the fake and its state.
+type UpgradePolicyState struct {
+	Policy map[string]interface{} `json:"policy"`
+	State  map[string]interface{} `json:"state"`
+}
+
+func (s *Server) record(call Call) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.calls = append(s.calls, call)
+}
+
+// Calls returns the requests received for the given operations, or for all operations when none is given.
+func (s *Server) Calls(operations ...string) []Call {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	calls := []Call{}
+	for _, call := range s.calls {
+		if len(operations) == 0 || contains(operations, call.Operation) {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// State returns the clusters, limited support reasons, upgrade policies and service logs of the fake.
+func (s *Server) State() State {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	state := State{Clusters: []ClusterState{}, ServiceLogs: []map[string]interface{}{}}
+	for _, c := range s.clusters {
+		clusterState := ClusterState{
+			Cluster:               clone(c.object),
+			LimitedSupportReasons: []map[string]interface{}{},
+			UpgradePolicies:       []UpgradePolicyState{},
+		}
+		for _, reason := range c.reasons {
+			clusterState.LimitedSupportReasons = append(clusterState.LimitedSupportReasons, clone(reason))
+		}
+		for _, p := range c.policies {
+			clusterState.UpgradePolicies = append(clusterState.UpgradePolicies, UpgradePolicyState{Policy: clone(p.object), State: clone(p.state)})
+		}
+		state.Clusters = append(state.Clusters, clusterState)
+	}
+	for _, entry := range s.serviceLogs {
+		state.ServiceLogs = append(state.ServiceLogs, clone(entry))
+	}
+	return state
+}
+
+// ServiceLogs returns the service logs received by the fake, in the order they were received.
+func (s *Server) ServiceLogs() []*slv1.LogEntry {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return unmarshalAll(s.serviceLogs, slv1.UnmarshalLogEntry)
+}
+
+// LimitedSupportReasons returns the limited support reasons of the cluster with the internal ID.
+func (s *Server) LimitedSupportReasons(clusterID string) []*cmv1.LimitedSupportReason {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, c := range s.clusters {
+		if c.object["id"] == clusterID {
+			return unmarshalAll(c.reasons, cmv1.UnmarshalLimitedSupportReason)
+		}
+	}
+	return nil
+}
+
+// UpgradePolicies returns the upgrade policies of the cluster with the internal ID.
+func (s *Server) UpgradePolicies(clusterID string) []*cmv1.UpgradePolicy {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, c := range s.clusters {
+		if c.object["id"] == clusterID {
+			var policies []object
+			for _, p := range c.policies {
+				policies = append(policies, p.object)
+			}
+			return unmarshalAll(policies, cmv1.UnmarshalUpgradePolicy)
+		}
+	}
+	return nil
+}
+
+// Reset removes the recorded calls, the faults, the service logs and the limited support reasons and upgrade policies
+// of the clusters. The clusters are kept.
+func (s *Server) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.calls = nil
+	s.faults = nil
+	s.serviceLogs = nil
+	for _, c := range s.clusters {
+		c.reasons = nil
+		c.policies = nil
+	}
+}
+
+// routeInspection serves the inspection API of the fake:
+//
+//	GET    /_fake/calls[?operation=...]  the recorded calls
+//	DELETE /_fake/calls                  removes the recorded calls
+//	GET    /_fake/state                  the clusters and their subresources and the service logs
+//	POST   /_fake/clusters               adds the cluster of the body, which requires an id
+//	GET    /_fake/faults                 the faults still to be applied
+//	POST   /_fake/faults                 adds the fault of the body
+//	PUT    /_fake/faults                 replaces the faults with the list of the body
+//	DELETE /_fake/faults                 removes the faults
+//	POST   /_fake/reset                  resets the fake, see Reset
+func (s *Server) routeInspection(r *mux.Router) {
+	r.HandleFunc("/calls", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.Calls(r.URL.Query()["operation"]...))
+	}).Methods(http.MethodGet)
+	r.HandleFunc("/calls", func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		s.calls = nil
+		s.mutex.Unlock()
+		writeJSON(w, http.StatusNoContent, nil)
+	}).Methods(http.MethodDelete)
+
+	r.HandleFunc("/state", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.State())
+	}).Methods(http.MethodGet)
+
+	r.HandleFunc("/clusters", func(w http.ResponseWriter, r *http.Request) {
+		var c object
+		if err := json.NewDecoder(r.Body).Decode(&c); err != nil || c == nil {
+			writeJSON(w, http.StatusBadRequest, newError(http.StatusBadRequest, "", "request body isn't a JSON object"))
+			return
+		}
+		if id, _ := c["id"].(string); id == "" {
+			writeJSON(w, http.StatusBadRequest, newError(http.StatusBadRequest, "", "cluster requires an id"))
+			return
+		}
+		writeJSON(w, http.StatusCreated, s.addCluster(c))
+	}).Methods(http.MethodPost)
+
+	r.HandleFunc("/faults", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.Faults())
+	}).Methods(http.MethodGet)
+	r.HandleFunc("/faults", func(w http.ResponseWriter, r *http.Request) {
+		var fault Fault
+		if err := json.NewDecoder(r.Body).Decode(&fault); err != nil {
+			writeJSON(w, http.StatusBadRequest, newError(http.StatusBadRequest, "", "invalid fault: %v", err))
+			return
+		}
+		if err := s.AddFault(fault); err != nil {
+			writeJSON(w, http.StatusBadRequest, newError(http.StatusBadRequest, "", "invalid fault: %v", err))
+			return
+		}
+		writeJSON(w, http.StatusCreated, fault)
+	}).Methods(http.MethodPost)
+	r.HandleFunc("/faults", func(w http.ResponseWriter, r *http.Request) {
+		var faults []Fault
+		if err := json.NewDecoder(r.Body).Decode(&faults); err != nil {
+			writeJSON(w, http.StatusBadRequest, newError(http.StatusBadRequest, "", "invalid faults: %v", err))
+			return
+		}
+		if err := s.SetFaults(faults); err != nil {
+			writeJSON(w, http.StatusBadRequest, newError(http.StatusBadRequest, "", "invalid faults: %v", err))
+			return
+		}
+		writeJSON(w, http.StatusOK, s.Faults())
+	}).Methods(http.MethodPut)
+	r.HandleFunc("/faults", func(w http.ResponseWriter, r *http.Request) {
+		s.ClearFaults()
+		writeJSON(w, http.StatusNoContent, nil)
+	}).Methods(http.MethodDelete)
+
+	r.HandleFunc("/reset", func(w http.ResponseWriter, r *http.Request) {
+		s.Reset()
+		writeJSON(w, http.StatusNoContent, nil)
+	}).Methods(http.MethodPost)
+}
+
+// unmarshalAll converts the objects to the SDK type with its unmarshal function, skipping the objects it rejects.
+func unmarshalAll[T any](objects []object, unmarshal func(source interface{}) (T, error)) []T {
+	var result []T
+	for _, o := range objects {
+		data, err := json.Marshal(o)
+		if err != nil {
+			continue
+		}
+		converted, err := unmarshal(data)
+		if err != nil {
+			continue
+		}
+		result = append(result, converted)
+	}
+	return result
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns a short description of the call, e.g. for test failures.
+func (c Call) String() string {
+	return fmt.Sprintf("%s %s %s -> %d", c.Operation, c.Method, c.Path, c.Status)
+}
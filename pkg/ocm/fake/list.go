@@ -0,0 +1,154 @@
+package fake
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Page and page size of a list request without page or size
+	defaultPage = 1
+	defaultSize = 100
+)
+
+// searchTerm matches a term of a search: a field compared to a quoted value
+var searchTerm = regexp.MustCompile(`^(\w+)\s*(=|!=)\s*'([^']*)'$`)
+
+// searchConjunction splits a search into its terms
+var searchConjunction = regexp.MustCompile(`(?i)\s+and\s+`)
+
+// list returns the page of the items matching the search of the request in the order of the request. The search
+// supports terms comparing a top-level field to a quoted value with = or !=, joined by 'and', and the order a
+// comma-separated list of top-level fields followed by asc or desc.
+func list(r *http.Request, kind string, items []object) response {
+	query := r.URL.Query()
+	page, err := intParam(query.Get("page"), defaultPage)
+	if err != nil {
+		return response{http.StatusBadRequest, newError(http.StatusBadRequest, "", "invalid page: %v", err)}
+	}
+	size, err := intParam(query.Get("size"), defaultSize)
+	if err != nil {
+		return response{http.StatusBadRequest, newError(http.StatusBadRequest, "", "invalid size: %v", err)}
+	}
+
+	matching, err := search(items, query.Get("search"))
+	if err != nil {
+		return response{http.StatusBadRequest, newError(http.StatusBadRequest, "", "%v", err)}
+	}
+	if err := order(matching, query.Get("order")); err != nil {
+		return response{http.StatusBadRequest, newError(http.StatusBadRequest, "", "%v", err)}
+	}
+
+	pageItems := []object{}
+	for i := (page - 1) * size; i < len(matching) && i < page*size; i++ {
+		pageItems = append(pageItems, clone(matching[i]))
+	}
+	return response{http.StatusOK, object{
+		"kind":  kind,
+		"page":  page,
+		"size":  len(pageItems),
+		"total": len(matching),
+		"items": pageItems,
+	}}
+}
+
+func intParam(value string, defaultValue int) (int, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if number < 1 {
+		return 0, fmt.Errorf("%d isn't positive", number)
+	}
+	return number, nil
+}
+
+func search(items []object, query string) ([]object, error) {
+	if strings.TrimSpace(query) == "" {
+		return append([]object(nil), items...), nil
+	}
+
+	type term struct {
+		field, operator, value string
+	}
+	var terms []term
+	for _, part := range searchConjunction.Split(strings.TrimSpace(query), -1) {
+		match := searchTerm.FindStringSubmatch(strings.TrimSpace(part))
+		if match == nil {
+			return nil, fmt.Errorf("search term '%s' isn't supported by the fake OCM server", part)
+		}
+		terms = append(terms, term{match[1], match[2], match[3]})
+	}
+
+	var matching []object
+	for _, item := range items {
+		matches := true
+		for _, t := range terms {
+			if (fieldValue(item, t.field) == t.value) != (t.operator == "=") {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			matching = append(matching, item)
+		}
+	}
+	return matching, nil
+}
+
+func order(items []object, query string) error {
+	if strings.TrimSpace(query) == "" {
+		return nil
+	}
+
+	type key struct {
+		field      string
+		descending bool
+	}
+	var keys []key
+	for _, part := range strings.Split(query, ",") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 || len(fields) > 2 {
+			return fmt.Errorf("order '%s' isn't supported by the fake OCM server", part)
+		}
+		k := key{field: fields[0]}
+		if len(fields) == 2 {
+			switch trimmedLower(fields[1]) {
+			case "asc":
+			case "desc":
+				k.descending = true
+			default:
+				return fmt.Errorf("order direction '%s' isn't supported by the fake OCM server", fields[1])
+			}
+		}
+		keys = append(keys, k)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		for _, k := range keys {
+			a, b := fieldValue(items[i], k.field), fieldValue(items[j], k.field)
+			if a == b {
+				continue
+			}
+			return (a < b) != k.descending
+		}
+		return false
+	})
+	return nil
+}
+
+// fieldValue returns a top-level field of the object as a string, empty when missing.
+func fieldValue(o object, field string) string {
+	value, ok := o[field]
+	if !ok || value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,565 @@
+// Package fake implements an in-memory fake of the subset of the OCM APIs the agent uses, so that the
+// agent can be run and tested without an OCM environment.
+package fake
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// OperationIDHeader is the header the OCM operation ID of a response is sent in
+	OperationIDHeader = "X-Operation-Id"
+
+	// Operations served by the fake, as recorded in the calls and matched by the faults
+	OperationGetCurrentAccount          = "GetCurrentAccount"
+	OperationListClusters               = "ListClusters"
+	OperationGetCluster                 = "GetCluster"
+	OperationListLimitedSupportReasons  = "ListLimitedSupportReasons"
+	OperationAddLimitedSupportReason    = "AddLimitedSupportReason"
+	OperationGetLimitedSupportReason    = "GetLimitedSupportReason"
+	OperationDeleteLimitedSupportReason = "DeleteLimitedSupportReason"
+	OperationListUpgradePolicies        = "ListUpgradePolicies"
+	OperationAddUpgradePolicy           = "AddUpgradePolicy"
+	OperationGetUpgradePolicy           = "GetUpgradePolicy"
+	OperationUpdateUpgradePolicy        = "UpdateUpgradePolicy"
+	OperationDeleteUpgradePolicy        = "DeleteUpgradePolicy"
+	OperationGetUpgradePolicyState      = "GetUpgradePolicyState"
+	OperationUpdateUpgradePolicyState   = "UpdateUpgradePolicyState"
+	OperationAddServiceLog              = "AddServiceLog"
+	OperationListServiceLogs            = "ListServiceLogs"
+
+	clustersPath    = "/api/clusters_mgmt/v1/clusters"
+	serviceLogsPath = "/api/service_logs/v1/cluster_logs"
+)
+
+// object is an OCM resource as sent and received in JSON
+type object map[string]interface{}
+
+// cluster holds a cluster and its subresources
+type cluster struct {
+	object   object
+	reasons  []object
+	policies []*upgradePolicy
+}
+
+// upgradePolicy holds an upgrade policy and its state
+type upgradePolicy struct {
+	object object
+	state  object
+}
+
+// Server is an in-memory fake of the OCM APIs used by the agent: the current account, the cluster search and get,
+// the limited support reasons, the upgrade policies and their state and the service logs. Every request is
+// recorded, faults can be injected and the state is inspected with the methods of the server or under /_fake.
+// Don't create instances of this type directly; use the NewServer function instead.
+type Server struct {
+	mutex       sync.Mutex
+	router      *mux.Router
+	clusters    []*cluster
+	serviceLogs []object
+	calls       []Call
+	faults      []*Fault
+}
+
+// NewServer creates a fake OCM server without clusters, to be served with net/http.
+func NewServer() *Server {
+	s := &Server{}
+	r := mux.NewRouter()
+
+	s.route(r, "/api/accounts_mgmt/v1/current_account", http.MethodGet, OperationGetCurrentAccount, s.getCurrentAccount)
+
+	s.route(r, clustersPath, http.MethodGet, OperationListClusters, s.listClusters)
+	s.route(r, clustersPath+"/{cluster_id}", http.MethodGet, OperationGetCluster, s.getCluster)
+
+	reasons := clustersPath + "/{cluster_id}/limited_support_reasons"
+	s.route(r, reasons, http.MethodGet, OperationListLimitedSupportReasons, s.listLimitedSupportReasons)
+	s.route(r, reasons, http.MethodPost, OperationAddLimitedSupportReason, s.addLimitedSupportReason)
+	s.route(r, reasons+"/{reason_id}", http.MethodGet, OperationGetLimitedSupportReason, s.getLimitedSupportReason)
+	s.route(r, reasons+"/{reason_id}", http.MethodDelete, OperationDeleteLimitedSupportReason, s.deleteLimitedSupportReason)
+
+	policies := clustersPath + "/{cluster_id}/upgrade_policies"
+	s.route(r, policies, http.MethodGet, OperationListUpgradePolicies, s.listUpgradePolicies)
+	s.route(r, policies, http.MethodPost, OperationAddUpgradePolicy, s.addUpgradePolicy)
+	s.route(r, policies+"/{policy_id}", http.MethodGet, OperationGetUpgradePolicy, s.getUpgradePolicy)
+	s.route(r, policies+"/{policy_id}", http.MethodPatch, OperationUpdateUpgradePolicy, s.updateUpgradePolicy)
+	s.route(r, policies+"/{policy_id}", http.MethodDelete, OperationDeleteUpgradePolicy, s.deleteUpgradePolicy)
+	s.route(r, policies+"/{policy_id}/state", http.MethodGet, OperationGetUpgradePolicyState, s.getUpgradePolicyState)
+	s.route(r, policies+"/{policy_id}/state", http.MethodPatch, OperationUpdateUpgradePolicyState, s.updateUpgradePolicyState)
+
+	s.route(r, serviceLogsPath, http.MethodPost, OperationAddServiceLog, s.addServiceLog)
+	s.route(r, "/api/service_logs/v1/clusters/cluster_logs", http.MethodGet, OperationListServiceLogs, s.listServiceLogs)
+
+	s.routeInspection(r.PathPrefix(InspectionPathPrefix).Subrouter())
+
+	r.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusNotFound, newError(http.StatusNotFound, "", "path '%s' isn't served by the fake OCM server", r.URL.Path))
+	})
+	r.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusMethodNotAllowed, newError(http.StatusMethodNotAllowed, "", "method %s isn't allowed for path '%s'", r.Method, r.URL.Path))
+	})
+
+	s.router = r
+	return s
+}
+
+// ServeHTTP serves the OCM APIs and the inspection API of the fake.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.router.ServeHTTP(w, r)
+}
+
+// AddCluster adds a cluster with the given internal and external IDs, replacing the cluster with the same internal ID.
+func (s *Server) AddCluster(id, externalID string) {
+	s.addCluster(object{"id": id, "external_id": externalID, "name": id})
+}
+
+// addCluster adds or replaces the cluster and returns a copy of it.
+func (s *Server) addCluster(o object) object {
+	id := fmt.Sprint(o["id"])
+	o["kind"] = "Cluster"
+	o["href"] = clustersPath + "/" + id
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, c := range s.clusters {
+		if c.object["id"] == id {
+			c.object = o
+			return clone(o)
+		}
+	}
+	s.clusters = append(s.clusters, &cluster{object: o})
+	return clone(o)
+}
+
+// response is the status and JSON body of a response, without body when nil
+type response struct {
+	status int
+	body   interface{}
+}
+
+// handler handles a request of an operation with its body already read
+type handler func(r *http.Request, body []byte) response
+
+// route serves the operation on the path and method, recording its calls and applying its faults.
+func (s *Server) route(r *mux.Router, path, method, operation string, h handler) {
+	r.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		operationID := uuid.NewString()
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, newError(http.StatusBadRequest, operationID, "can't read request body: %v", err))
+			return
+		}
+
+		fault := s.takeFault(operation)
+		var resp response
+		if fault != nil && fault.Latency.Duration > 0 {
+			select {
+			case <-time.After(fault.Latency.Duration):
+			case <-r.Context().Done():
+			}
+		}
+		if fault != nil && fault.Status != 0 {
+			resp = response{fault.Status, newError(fault.Status, operationID, "fault injected by the fake OCM server")}
+		} else {
+			resp = h(r, body)
+		}
+		if e, ok := resp.body.(ocmError); ok {
+			e["operation_id"] = operationID
+		}
+
+		s.record(Call{
+			Time:        start,
+			Operation:   operation,
+			Method:      r.Method,
+			Path:        r.URL.Path,
+			Query:       r.URL.RawQuery,
+			Body:        rawJSON(body),
+			Status:      resp.status,
+			OperationID: operationID,
+			Faulted:     fault != nil,
+			Duration:    time.Since(start),
+		})
+
+		w.Header().Set(OperationIDHeader, operationID)
+		if fault != nil && fault.isRateLimit() && fault.RetryAfter > 0 {
+			w.Header().Set("Retry-After", fmt.Sprint(fault.RetryAfter))
+		}
+		writeJSON(w, resp.status, resp.body)
+	}).Methods(method)
+}
+
+func (s *Server) getCurrentAccount(_ *http.Request, _ []byte) response {
+	return response{http.StatusOK, object{
+		"kind":     "Account",
+		"id":       "fake-ocm",
+		"href":     "/api/accounts_mgmt/v1/accounts/fake-ocm",
+		"username": "fake-ocm",
+	}}
+}
+
+func (s *Server) listClusters(r *http.Request, _ []byte) response {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var items []object
+	for _, c := range s.clusters {
+		items = append(items, c.object)
+	}
+	return list(r, "ClusterList", items)
+}
+
+func (s *Server) getCluster(r *http.Request, _ []byte) response {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	c, resp := s.cluster(r)
+	if c == nil {
+		return resp
+	}
+	return response{http.StatusOK, clone(c.object)}
+}
+
+func (s *Server) listLimitedSupportReasons(r *http.Request, _ []byte) response {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	c, resp := s.cluster(r)
+	if c == nil {
+		return resp
+	}
+	return list(r, "LimitedSupportReasonList", c.reasons)
+}
+
+func (s *Server) addLimitedSupportReason(r *http.Request, body []byte) response {
+	reason, resp := decode(body)
+	if reason == nil {
+		return resp
+	}
+	if reason["summary"] == nil && reason["template"] == nil {
+		return response{http.StatusBadRequest, newError(http.StatusBadRequest, "", "limited support reason requires a summary or a template")}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	c, resp := s.cluster(r)
+	if c == nil {
+		return resp
+	}
+	id := uuid.NewString()
+	reason["kind"] = "LimitedSupportReason"
+	reason["id"] = id
+	reason["href"] = c.object["href"].(string) + "/limited_support_reasons/" + id
+	reason["creation_timestamp"] = time.Now().UTC().Format(time.RFC3339)
+	c.reasons = append(c.reasons, reason)
+	return response{http.StatusCreated, clone(reason)}
+}
+
+func (s *Server) getLimitedSupportReason(r *http.Request, _ []byte) response {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	c, resp := s.cluster(r)
+	if c == nil {
+		return resp
+	}
+	i := find(c.reasons, mux.Vars(r)["reason_id"])
+	if i < 0 {
+		return notFound("limited support reason", mux.Vars(r)["reason_id"])
+	}
+	return response{http.StatusOK, clone(c.reasons[i])}
+}
+
+func (s *Server) deleteLimitedSupportReason(r *http.Request, _ []byte) response {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	c, resp := s.cluster(r)
+	if c == nil {
+		return resp
+	}
+	i := find(c.reasons, mux.Vars(r)["reason_id"])
+	if i < 0 {
+		return notFound("limited support reason", mux.Vars(r)["reason_id"])
+	}
+	c.reasons = append(c.reasons[:i], c.reasons[i+1:]...)
+	return response{status: http.StatusNoContent}
+}
+
+func (s *Server) listUpgradePolicies(r *http.Request, _ []byte) response {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	c, resp := s.cluster(r)
+	if c == nil {
+		return resp
+	}
+	var items []object
+	for _, p := range c.policies {
+		items = append(items, p.object)
+	}
+	return list(r, "UpgradePolicyList", items)
+}
+
+func (s *Server) addUpgradePolicy(r *http.Request, body []byte) response {
+	policy, resp := decode(body)
+	if policy == nil {
+		return resp
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	c, resp := s.cluster(r)
+	if c == nil {
+		return resp
+	}
+	id := uuid.NewString()
+	href := c.object["href"].(string) + "/upgrade_policies/" + id
+	policy["kind"] = "UpgradePolicy"
+	policy["id"] = id
+	policy["href"] = href
+	policy["cluster_id"] = c.object["id"]
+	c.policies = append(c.policies, &upgradePolicy{
+		object: policy,
+		state: object{
+			"kind":        "UpgradePolicyState",
+			"id":          id,
+			"href":        href + "/state",
+			"value":       "scheduled",
+			"description": "Upgrade scheduled.",
+		},
+	})
+	return response{http.StatusCreated, clone(policy)}
+}
+
+func (s *Server) getUpgradePolicy(r *http.Request, _ []byte) response {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	p, resp := s.upgradePolicy(r)
+	if p == nil {
+		return resp
+	}
+	return response{http.StatusOK, clone(p.object)}
+}
+
+func (s *Server) updateUpgradePolicy(r *http.Request, body []byte) response {
+	patch, resp := decode(body)
+	if patch == nil {
+		return resp
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	p, resp := s.upgradePolicy(r)
+	if p == nil {
+		return resp
+	}
+	merge(p.object, patch)
+	return response{http.StatusOK, clone(p.object)}
+}
+
+func (s *Server) deleteUpgradePolicy(r *http.Request, _ []byte) response {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	c, resp := s.cluster(r)
+	if c == nil {
+		return resp
+	}
+	for i, p := range c.policies {
+		if p.object["id"] == mux.Vars(r)["policy_id"] {
+			c.policies = append(c.policies[:i], c.policies[i+1:]...)
+			return response{status: http.StatusNoContent}
+		}
+	}
+	return notFound("upgrade policy", mux.Vars(r)["policy_id"])
+}
+
+func (s *Server) getUpgradePolicyState(r *http.Request, _ []byte) response {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	p, resp := s.upgradePolicy(r)
+	if p == nil {
+		return resp
+	}
+	return response{http.StatusOK, clone(p.state)}
+}
+
+func (s *Server) updateUpgradePolicyState(r *http.Request, body []byte) response {
+	patch, resp := decode(body)
+	if patch == nil {
+		return resp
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	p, resp := s.upgradePolicy(r)
+	if p == nil {
+		return resp
+	}
+	merge(p.state, patch)
+	return response{http.StatusOK, clone(p.state)}
+}
+
+func (s *Server) addServiceLog(_ *http.Request, body []byte) response {
+	entry, resp := decode(body)
+	if entry == nil {
+		return resp
+	}
+	if entry["cluster_uuid"] == nil && entry["cluster_id"] == nil {
+		return response{http.StatusBadRequest, newError(http.StatusBadRequest, "", "service log requires a cluster_uuid or a cluster_id")}
+	}
+	if summary, _ := entry["summary"].(string); summary == "" {
+		return response{http.StatusBadRequest, newError(http.StatusBadRequest, "", "service log requires a summary")}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	id := uuid.NewString()
+	now := time.Now().UTC().Format(time.RFC3339)
+	entry["kind"] = "ClusterLog"
+	entry["id"] = id
+	entry["href"] = serviceLogsPath + "/" + id
+	entry["created_at"] = now
+	if entry["timestamp"] == nil {
+		entry["timestamp"] = now
+	}
+	s.serviceLogs = append(s.serviceLogs, entry)
+	return response{http.StatusCreated, clone(entry)}
+}
+
+func (s *Server) listServiceLogs(r *http.Request, _ []byte) response {
+	query := r.URL.Query()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var items []object
+	for _, entry := range s.serviceLogs {
+		if uuid := query.Get("cluster_uuid"); uuid != "" && entry["cluster_uuid"] != uuid {
+			continue
+		}
+		if id := query.Get("cluster_id"); id != "" && entry["cluster_id"] != id {
+			continue
+		}
+		items = append(items, entry)
+	}
+	return list(r, "ClusterLogList", items)
+}
+
+// cluster returns the cluster of the cluster_id path variable, or the not found response.
+// It must be called with the mutex held.
+func (s *Server) cluster(r *http.Request) (*cluster, response) {
+	id := mux.Vars(r)["cluster_id"]
+	for _, c := range s.clusters {
+		if c.object["id"] == id {
+			return c, response{}
+		}
+	}
+	return nil, notFound("cluster", id)
+}
+
+// upgradePolicy returns the upgrade policy of the cluster_id and policy_id path variables, or the not found response.
+// It must be called with the mutex held.
+func (s *Server) upgradePolicy(r *http.Request) (*upgradePolicy, response) {
+	c, resp := s.cluster(r)
+	if c == nil {
+		return nil, resp
+	}
+	id := mux.Vars(r)["policy_id"]
+	for _, p := range c.policies {
+		if p.object["id"] == id {
+			return p, response{}
+		}
+	}
+	return nil, notFound("upgrade policy", id)
+}
+
+// find returns the index of the object with the ID, or -1.
+func find(objects []object, id string) int {
+	for i, o := range objects {
+		if o["id"] == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// clone returns a copy of the object, so that it can be encoded while the state is changed.
+func clone(o object) object {
+	copied := object{}
+	for key, value := range o {
+		copied[key] = value
+	}
+	return copied
+}
+
+// merge sets the fields of the patch on the object, except the read-only ones.
+func merge(o, patch object) {
+	for key, value := range patch {
+		switch key {
+		case "kind", "id", "href":
+			continue
+		}
+		o[key] = value
+	}
+}
+
+// decode reads the JSON object of a request body, or returns the bad request response.
+func decode(body []byte) (object, response) {
+	var o object
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&o); err != nil || o == nil {
+		return nil, response{http.StatusBadRequest, newError(http.StatusBadRequest, "", "request body isn't a JSON object")}
+	}
+	return o, response{}
+}
+
+// ocmError is the body of an OCM error response
+type ocmError object
+
+func newError(status int, operationID, format string, args ...interface{}) ocmError {
+	e := ocmError{
+		"kind":   "Error",
+		"id":     fmt.Sprint(status),
+		"href":   fmt.Sprintf("/api/clusters_mgmt/v1/errors/%d", status),
+		"code":   fmt.Sprintf("OCM-FAKE-%d", status),
+		"reason": fmt.Sprintf(format, args...),
+	}
+	if operationID != "" {
+		e["operation_id"] = operationID
+	}
+	return e
+}
+
+func notFound(kind, id string) response {
+	return response{http.StatusNotFound, newError(http.StatusNotFound, "", "%s '%s' not found", kind, id)}
+}
+
+// rawJSON returns the body as JSON, or as a JSON string when it isn't valid JSON.
+func rawJSON(body []byte) json.RawMessage {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	if json.Valid(body) {
+		return json.RawMessage(body)
+	}
+	quoted, _ := json.Marshal(string(body))
+	return quoted
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	if body == nil {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// trimmedLower returns the value without surrounding spaces and in lower case.
+func trimmedLower(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
@@ -0,0 +1,257 @@
+package fake
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	slv1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/ocm-agent/pkg/ocm"
+)
+
+var _ = Describe("Fake OCM server", func() {
+	const (
+		internalID = "internal-id"
+		externalID = "bd845de4-5c16-4067-a868-15b02d55ccef"
+	)
+
+	var (
+		server     *Server
+		httpServer *httptest.Server
+		connection *sdk.Connection
+		client     ocm.OCMClient
+	)
+
+	BeforeEach(func() {
+		server = NewServer()
+		server.AddCluster(internalID, externalID)
+		httpServer = httptest.NewServer(server)
+
+		var err error
+		connection, err = ocm.NewConnection().
+			RetryPolicy(ocm.RetryPolicy{Limit: 2, Interval: 10 * time.Millisecond}).
+			Build(httpServer.URL, externalID, "dG9rZW4=")
+		Expect(err).NotTo(HaveOccurred())
+		client = ocm.NewOcmClient(connection)
+	})
+
+	AfterEach(func() {
+		connection.Close()
+		httpServer.Close()
+	})
+
+	serviceLog := func(summary string) *slv1.LogEntry {
+		entry, err := slv1.NewLogEntry().ClusterUUID(externalID).Summary(summary).Severity(slv1.SeverityWarning).Build()
+		Expect(err).NotTo(HaveOccurred())
+		return entry
+	}
+
+	inspect := func(method, path string, body interface{}) *http.Response {
+		data, err := json.Marshal(body)
+		Expect(err).NotTo(HaveOccurred())
+		request, err := http.NewRequest(method, httpServer.URL+InspectionPathPrefix+path, bytes.NewReader(data))
+		Expect(err).NotTo(HaveOccurred())
+		response, err := http.DefaultClient.Do(request)
+		Expect(err).NotTo(HaveOccurred())
+		return response
+	}
+
+	Context("service logs", func() {
+		It("records the posted service logs", func() {
+			Expect(client.SendServiceLog(serviceLog("Cluster is degraded"))).To(Succeed())
+
+			serviceLogs := server.ServiceLogs()
+			Expect(serviceLogs).To(HaveLen(1))
+			Expect(serviceLogs[0].Summary()).To(Equal("Cluster is degraded"))
+			Expect(serviceLogs[0].ID()).NotTo(BeEmpty())
+
+			calls := server.Calls(OperationAddServiceLog)
+			Expect(calls).To(HaveLen(1))
+			Expect(calls[0].Status).To(Equal(http.StatusCreated))
+			Expect(calls[0].OperationID).NotTo(BeEmpty())
+		})
+
+		It("lists the service logs of a cluster page by page", func() {
+			for _, summary := range []string{"first", "second", "third"} {
+				Expect(client.SendServiceLog(serviceLog(summary))).To(Succeed())
+			}
+
+			serviceLogs, operationID, err := client.GetServiceLogs(externalID, ocm.ListOptions{Size: 2, Order: "summary desc"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(operationID).NotTo(BeEmpty())
+			Expect(serviceLogs).To(HaveLen(3))
+			Expect(serviceLogs[0].Summary()).To(Equal("third"))
+			Expect(server.Calls(OperationListServiceLogs)).To(HaveLen(2))
+
+			serviceLogs, _, err = client.GetServiceLogs(externalID, ocm.ListOptions{Search: "summary = 'second'"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(serviceLogs).To(HaveLen(1))
+		})
+
+		It("rejects a service log without summary", func() {
+			entry, err := slv1.NewLogEntry().ClusterUUID(externalID).Build()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.SendServiceLog(entry)).NotTo(Succeed())
+			Expect(server.ServiceLogs()).To(BeEmpty())
+		})
+	})
+
+	Context("limited support reasons", func() {
+		It("adds, lists and removes the reasons of a cluster by external ID", func() {
+			reason, err := cmv1.NewLimitedSupportReason().Summary("Cluster is in limited support").Details("details").Build()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.SendLimitedSupport(externalID, reason)).To(Succeed())
+
+			reasons, err := client.GetLimitedSupportReasons(externalID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reasons).To(HaveLen(1))
+			Expect(reasons[0].Summary()).To(Equal("Cluster is in limited support"))
+			Expect(server.LimitedSupportReasons(internalID)).To(HaveLen(1))
+
+			Expect(client.RemoveLimitedSupport(externalID, reasons[0].ID())).To(Succeed())
+			Expect(server.LimitedSupportReasons(internalID)).To(BeEmpty())
+			Expect(server.Calls(OperationListClusters)).To(HaveLen(3))
+		})
+
+		It("fails for an unknown cluster", func() {
+			_, err := client.GetLimitedSupportReasons("unknown")
+			Expect(err).To(MatchError(ContainSubstring("not found")))
+		})
+	})
+
+	Context("upgrade policies", func() {
+		It("creates, updates and deletes upgrade policies and their state", func() {
+			policy, err := cmv1.NewUpgradePolicy().ScheduleType(cmv1.ScheduleTypeManual).UpgradeType(cmv1.UpgradeTypeOSD).Version("4.16.1").Build()
+			Expect(err).NotTo(HaveOccurred())
+			created, _, err := client.CreateUpgradePolicy(internalID, policy)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created.ID()).NotTo(BeEmpty())
+			Expect(created.ClusterID()).To(Equal(internalID))
+
+			policies, _, err := client.GetUpgradePolicies(internalID, ocm.ListOptions{Search: "version = '4.16.1'"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policies).To(HaveLen(1))
+
+			state, _, err := client.GetUpgradePolicyState(internalID, created.ID())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Value()).To(Equal(cmv1.UpgradePolicyStateValueScheduled))
+
+			update, err := cmv1.NewUpgradePolicyState().Value(cmv1.UpgradePolicyStateValueStarted).Description("Upgrade started.").Build()
+			Expect(err).NotTo(HaveOccurred())
+			state, _, err = client.UpdateUpgradePolicyState(internalID, created.ID(), update)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Value()).To(Equal(cmv1.UpgradePolicyStateValueStarted))
+			Expect(state.Description()).To(Equal("Upgrade started."))
+
+			_, err = client.DeleteUpgradePolicy(internalID, created.ID())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(server.UpgradePolicies(internalID)).To(BeEmpty())
+
+			_, _, err = client.GetUpgradePolicy(internalID, created.ID())
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("faults", func() {
+		It("answers too many requests", func() {
+			Expect(server.AddFault(Fault{Operation: OperationAddServiceLog, Status: http.StatusTooManyRequests})).To(Succeed())
+
+			err := client.SendServiceLog(serviceLog("rate limited"))
+			var rateLimitErr *ocm.RateLimitError
+			Expect(errors.As(err, &rateLimitErr)).To(BeTrue())
+			Expect(server.ServiceLogs()).To(BeEmpty())
+			Expect(server.Calls(OperationAddServiceLog)).NotTo(BeEmpty())
+			for _, call := range server.Calls(OperationAddServiceLog) {
+				Expect(call.Faulted).To(BeTrue())
+			}
+		})
+
+		It("stops failing once applied the given number of times", func() {
+			Expect(server.AddFault(Fault{Operation: OperationGetCluster, Status: http.StatusServiceUnavailable, Times: 1})).To(Succeed())
+
+			_, _, err := client.GetCluster(internalID)
+			Expect(err).NotTo(HaveOccurred())
+
+			calls := server.Calls(OperationGetCluster)
+			Expect(calls).To(HaveLen(2))
+			Expect(calls[0].Status).To(Equal(http.StatusServiceUnavailable))
+			Expect(calls[0].Faulted).To(BeTrue())
+			Expect(calls[1].Status).To(Equal(http.StatusOK))
+			Expect(server.Faults()).To(BeEmpty())
+		})
+
+		It("delays the requests", func() {
+			Expect(server.AddFault(Fault{Latency: metav1.Duration{Duration: 100 * time.Millisecond}})).To(Succeed())
+
+			_, _, err := client.GetCluster(internalID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(server.Calls()[0].Duration).To(BeNumerically(">=", 100*time.Millisecond))
+		})
+
+		It("rejects invalid faults", func() {
+			Expect(server.AddFault(Fault{Operation: OperationGetCluster})).To(MatchError(ContainSubstring("requires a status or a latency")))
+			Expect(server.AddFault(Fault{Status: 42})).To(MatchError(ContainSubstring("invalid status")))
+			Expect(server.AddFault(Fault{Status: http.StatusBadGateway, Rate: 2})).To(HaveOccurred())
+		})
+	})
+
+	Context("inspection API", func() {
+		It("sets the faults and returns the calls and state", func() {
+			response := inspect(http.MethodPut, "/faults", []Fault{{Operation: OperationGetCluster, Status: http.StatusNotFound, Times: 1}})
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+			Expect(server.Faults()).To(HaveLen(1))
+
+			_, _, err := client.GetCluster(internalID)
+			Expect(err).To(HaveOccurred())
+			Expect(client.SendServiceLog(serviceLog("recorded"))).To(Succeed())
+
+			response = inspect(http.MethodGet, "/calls?operation="+OperationGetCluster, nil)
+			var calls []Call
+			Expect(json.NewDecoder(response.Body).Decode(&calls)).To(Succeed())
+			Expect(calls).To(HaveLen(1))
+			Expect(calls[0].Status).To(Equal(http.StatusNotFound))
+
+			response = inspect(http.MethodGet, "/state", nil)
+			var state State
+			Expect(json.NewDecoder(response.Body).Decode(&state)).To(Succeed())
+			Expect(state.Clusters).To(HaveLen(1))
+			Expect(state.ServiceLogs).To(HaveLen(1))
+			Expect(state.ServiceLogs[0]).To(HaveKeyWithValue("summary", "recorded"))
+		})
+
+		It("adds clusters and resets the fake", func() {
+			response := inspect(http.MethodPost, "/clusters", map[string]string{"id": "other-id", "external_id": "other-external-id"})
+			Expect(response.StatusCode).To(Equal(http.StatusCreated))
+			cluster, _, err := client.GetCluster("other-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cluster.ExternalID()).To(Equal("other-external-id"))
+
+			Expect(client.SendServiceLog(serviceLog("removed by the reset"))).To(Succeed())
+			response = inspect(http.MethodPost, "/reset", nil)
+			Expect(response.StatusCode).To(Equal(http.StatusNoContent))
+			Expect(server.ServiceLogs()).To(BeEmpty())
+			Expect(server.Calls()).To(BeEmpty())
+			Expect(server.State().Clusters).To(HaveLen(2))
+		})
+
+		It("rejects a cluster without ID and an invalid fault", func() {
+			Expect(inspect(http.MethodPost, "/clusters", map[string]string{"external_id": "id"}).StatusCode).To(Equal(http.StatusBadRequest))
+			Expect(inspect(http.MethodPost, "/faults", Fault{Status: 1000}).StatusCode).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	It("rejects unsupported searches", func() {
+		response, err := http.Get(httpServer.URL + clustersPath + "?search=name+like+'foo%25'")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusBadRequest))
+	})
+})
@@ -0,0 +1,254 @@
+package ocm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	slv1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/openshift/ocm-agent/pkg/metrics"
+)
+
+// callStats collects the HTTP requests sent by the SDK for a single OCMClient call.
+type callStats struct {
+	mutex       sync.Mutex
+	lastRequest *http.Request
+	retries     int
+	rateLimited int
+	status      int
+}
+
+type callStatsKey struct{}
+
+// MetricsTransportWrapper records the attempts and responses of the requests sent for the calls of an
+// instrumented client. It must be set as transport wrapper of the connection, so that it is called for
+// every attempt of the retrying SDK transport.
+func MetricsTransportWrapper(transport http.RoundTripper) http.RoundTripper {
+	return metricsRoundTripper{transport: transport}
+}
+
+type metricsRoundTripper struct {
+	transport http.RoundTripper
+}
+
+func (m metricsRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	response, err := m.transport.RoundTrip(request)
+
+	stats, ok := request.Context().Value(callStatsKey{}).(*callStats)
+	if !ok {
+		return response, err
+	}
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+	// The SDK retries by sending the same request again
+	if request == stats.lastRequest {
+		stats.retries++
+	}
+	stats.lastRequest = request
+	stats.status = 0
+	if response != nil {
+		stats.status = response.StatusCode
+		if response.StatusCode == http.StatusTooManyRequests {
+			stats.rateLimited++
+		}
+	}
+	return response, err
+}
+
+// instrumentedOCMClient wraps an OCMClient and records the duration, status class, rate limited responses and
+// retries of every call.
+type instrumentedOCMClient struct {
+	OCMClient
+	ctx         context.Context
+	environment string
+}
+
+// NewInstrumentedOcmClient returns an OCMClient recording metrics for the calls of the given client to the named OCM
+// environment, metrics.OCMEnvironmentDefault for the connection configured by the flags.
+// Responses and retries are only known if the connection of the client uses MetricsTransportWrapper.
+func NewInstrumentedOcmClient(o OCMClient, environment string) OCMClient {
+	return &instrumentedOCMClient{
+		OCMClient:   o,
+		ctx:         context.Background(),
+		environment: environment,
+	}
+}
+
+func (i *instrumentedOCMClient) withContext(ctx context.Context) OCMClient {
+	withContext := *i
+	withContext.ctx = ctx
+	withContext.OCMClient = WithContext(i.OCMClient, ctx)
+	return &withContext
+}
+
+// observe runs call with a client collecting the requests it sends and records its metrics.
+func (i *instrumentedOCMClient) observe(service, operation string, call func(o OCMClient) (string, error)) (string, error) {
+	stats := &callStats{}
+	start := time.Now()
+	operationID, err := call(WithContext(i.OCMClient, context.WithValue(i.ctx, callStatsKey{}, stats)))
+	duration := time.Since(start)
+
+	stats.mutex.Lock()
+	defer stats.mutex.Unlock()
+
+	rateLimited := stats.rateLimited
+	var rateLimitErr *RateLimitError
+	if rateLimited == 0 && errors.As(err, &rateLimitErr) {
+		rateLimited = 1
+	}
+
+	metrics.ObserveOCMRequest(i.environment, service, operation, statusClass(stats.status, err), duration)
+	if rateLimited > 0 {
+		metrics.CountOCMRateLimited(i.environment, service, operation, rateLimited)
+	}
+	if stats.retries > 0 {
+		metrics.CountOCMRetries(i.environment, service, operation, stats.retries)
+	}
+	return operationID, err
+}
+
+// statusClass returns the class of the last HTTP status received for a call, e.g. 4xx.
+// Without a response, successful calls are assumed to be 2xx.
+func statusClass(status int, err error) string {
+	switch {
+	case status > 0:
+		return fmt.Sprintf("%dxx", status/100)
+	case err == nil:
+		return "2xx"
+	default:
+		return metrics.OCMStatusClassError
+	}
+}
+
+func (i *instrumentedOCMClient) SendServiceLog(logEntry *slv1.LogEntry) error {
+	_, err := i.sendServiceLog(logEntry)
+	return err
+}
+
+func (i *instrumentedOCMClient) sendServiceLog(logEntry *slv1.LogEntry) (string, error) {
+	return i.observe(metrics.OCMServiceServiceLogs, "SendServiceLog", func(o OCMClient) (string, error) {
+		if c, ok := o.(operationIDClient); ok {
+			return c.sendServiceLog(logEntry)
+		}
+		return "", o.SendServiceLog(logEntry)
+	})
+}
+
+func (i *instrumentedOCMClient) SendLimitedSupport(clusterUUID string, lsReason *cmv1.LimitedSupportReason) error {
+	_, err := i.sendLimitedSupport(clusterUUID, lsReason)
+	return err
+}
+
+func (i *instrumentedOCMClient) sendLimitedSupport(clusterUUID string, lsReason *cmv1.LimitedSupportReason) (string, error) {
+	return i.observe(metrics.OCMServiceClustersMgmt, "SendLimitedSupport", func(o OCMClient) (string, error) {
+		if c, ok := o.(operationIDClient); ok {
+			return c.sendLimitedSupport(clusterUUID, lsReason)
+		}
+		return "", o.SendLimitedSupport(clusterUUID, lsReason)
+	})
+}
+
+func (i *instrumentedOCMClient) RemoveLimitedSupport(clusterUUID string, lsReasonID string) error {
+	_, err := i.removeLimitedSupport(clusterUUID, lsReasonID)
+	return err
+}
+
+func (i *instrumentedOCMClient) removeLimitedSupport(clusterUUID string, lsReasonID string) (string, error) {
+	return i.observe(metrics.OCMServiceClustersMgmt, "RemoveLimitedSupport", func(o OCMClient) (string, error) {
+		if c, ok := o.(operationIDClient); ok {
+			return c.removeLimitedSupport(clusterUUID, lsReasonID)
+		}
+		return "", o.RemoveLimitedSupport(clusterUUID, lsReasonID)
+	})
+}
+
+func (i *instrumentedOCMClient) GetLimitedSupportReasons(clusterUUID string) ([]*cmv1.LimitedSupportReason, error) {
+	var reasons []*cmv1.LimitedSupportReason
+	_, err := i.observe(metrics.OCMServiceClustersMgmt, "GetLimitedSupportReasons", func(o OCMClient) (_ string, err error) {
+		reasons, err = o.GetLimitedSupportReasons(clusterUUID)
+		return "", err
+	})
+	return reasons, err
+}
+
+func (i *instrumentedOCMClient) GetCluster(clusterID string) (*cmv1.Cluster, string, error) {
+	var cluster *cmv1.Cluster
+	operationID, err := i.observe(metrics.OCMServiceClustersMgmt, "GetCluster", func(o OCMClient) (operationID string, err error) {
+		cluster, operationID, err = o.GetCluster(clusterID)
+		return operationID, err
+	})
+	return cluster, operationID, err
+}
+
+func (i *instrumentedOCMClient) GetUpgradePolicyState(clusterID string, upgradePolicyID string) (*cmv1.UpgradePolicyState, string, error) {
+	var policyState *cmv1.UpgradePolicyState
+	operationID, err := i.observe(metrics.OCMServiceClustersMgmt, "GetUpgradePolicyState", func(o OCMClient) (operationID string, err error) {
+		policyState, operationID, err = o.GetUpgradePolicyState(clusterID, upgradePolicyID)
+		return operationID, err
+	})
+	return policyState, operationID, err
+}
+
+func (i *instrumentedOCMClient) GetUpgradePolicy(clusterID string, upgradePolicyID string) (*cmv1.UpgradePolicy, string, error) {
+	var policy *cmv1.UpgradePolicy
+	operationID, err := i.observe(metrics.OCMServiceClustersMgmt, "GetUpgradePolicy", func(o OCMClient) (operationID string, err error) {
+		policy, operationID, err = o.GetUpgradePolicy(clusterID, upgradePolicyID)
+		return operationID, err
+	})
+	return policy, operationID, err
+}
+
+func (i *instrumentedOCMClient) GetUpgradePolicies(clusterID string, opts ListOptions) ([]*cmv1.UpgradePolicy, string, error) {
+	var policies []*cmv1.UpgradePolicy
+	operationID, err := i.observe(metrics.OCMServiceClustersMgmt, "GetUpgradePolicies", func(o OCMClient) (operationID string, err error) {
+		policies, operationID, err = o.GetUpgradePolicies(clusterID, opts)
+		return operationID, err
+	})
+	return policies, operationID, err
+}
+
+func (i *instrumentedOCMClient) GetServiceLogs(clusterUUID string, opts ListOptions) ([]*slv1.LogEntry, string, error) {
+	var logEntries []*slv1.LogEntry
+	operationID, err := i.observe(metrics.OCMServiceServiceLogs, "GetServiceLogs", func(o OCMClient) (operationID string, err error) {
+		logEntries, operationID, err = o.GetServiceLogs(clusterUUID, opts)
+		return operationID, err
+	})
+	return logEntries, operationID, err
+}
+
+func (i *instrumentedOCMClient) UpdateUpgradePolicyState(clusterID string, upgradePolicyID string, policyState *cmv1.UpgradePolicyState) (*cmv1.UpgradePolicyState, string, error) {
+	var updatedState *cmv1.UpgradePolicyState
+	operationID, err := i.observe(metrics.OCMServiceClustersMgmt, "UpdateUpgradePolicyState", func(o OCMClient) (operationID string, err error) {
+		updatedState, operationID, err = o.UpdateUpgradePolicyState(clusterID, upgradePolicyID, policyState)
+		return operationID, err
+	})
+	return updatedState, operationID, err
+}
+
+func (i *instrumentedOCMClient) CreateUpgradePolicy(clusterID string, policy *cmv1.UpgradePolicy) (*cmv1.UpgradePolicy, string, error) {
+	var createdPolicy *cmv1.UpgradePolicy
+	operationID, err := i.observe(metrics.OCMServiceClustersMgmt, "CreateUpgradePolicy", func(o OCMClient) (operationID string, err error) {
+		createdPolicy, operationID, err = o.CreateUpgradePolicy(clusterID, policy)
+		return operationID, err
+	})
+	return createdPolicy, operationID, err
+}
+
+func (i *instrumentedOCMClient) UpdateUpgradePolicy(clusterID string, upgradePolicyID string, policy *cmv1.UpgradePolicy) (*cmv1.UpgradePolicy, string, error) {
+	var updatedPolicy *cmv1.UpgradePolicy
+	operationID, err := i.observe(metrics.OCMServiceClustersMgmt, "UpdateUpgradePolicy", func(o OCMClient) (operationID string, err error) {
+		updatedPolicy, operationID, err = o.UpdateUpgradePolicy(clusterID, upgradePolicyID, policy)
+		return operationID, err
+	})
+	return updatedPolicy, operationID, err
+}
+
+func (i *instrumentedOCMClient) DeleteUpgradePolicy(clusterID string, upgradePolicyID string) (string, error) {
+	return i.observe(metrics.OCMServiceClustersMgmt, "DeleteUpgradePolicy", func(o OCMClient) (string, error) {
+		return o.DeleteUpgradePolicy(clusterID, upgradePolicyID)
+	})
+}
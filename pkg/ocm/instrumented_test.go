@@ -0,0 +1,113 @@
+package ocm
+
+import (
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/ghttp"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	. "github.com/openshift-online/ocm-sdk-go/testing"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/openshift/ocm-agent/pkg/metrics"
+)
+
+// metricValue returns the value of a counter or gauge, or the sample count of a histogram, with the given labels
+func metricValue(name string, labels prometheus.Labels) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	Expect(err).ToNot(HaveOccurred())
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if !hasLabels(metric, labels) {
+				continue
+			}
+			if metric.GetHistogram() != nil {
+				return float64(metric.GetHistogram().GetSampleCount())
+			}
+			if metric.GetGauge() != nil {
+				return metric.GetGauge().GetValue()
+			}
+			return metric.GetCounter().GetValue()
+		}
+	}
+	return 0
+}
+
+func hasLabels(metric *dto.Metric, labels prometheus.Labels) bool {
+	matched := 0
+	for _, pair := range metric.GetLabel() {
+		if value, ok := labels[pair.GetName()]; ok && value == pair.GetValue() {
+			matched++
+		}
+	}
+	return matched == len(labels)
+}
+
+var _ = Describe("Instrumented OCM client", func() {
+	var (
+		mockServer *Server
+		ocmClient  OCMClient
+		clusterID  = "abcd1234efgh5678ijkl9123mnopqrst"
+		getCluster = prometheus.Labels{"ocm_environment": metrics.OCMEnvironmentDefault, "ocm_service": metrics.OCMServiceClustersMgmt, "operation": "GetCluster"}
+	)
+
+	durationLabels := func(statusClass string) prometheus.Labels {
+		return prometheus.Labels{"ocm_environment": metrics.OCMEnvironmentDefault, "ocm_service": metrics.OCMServiceClustersMgmt, "operation": "GetCluster", "status_class": statusClass}
+	}
+
+	BeforeEach(func() {
+		mockServer = NewServer()
+		connection, err := sdk.NewConnectionBuilder().
+			URL(mockServer.URL()).
+			Tokens(MakeTokenString("Bearer", 15*time.Minute)).
+			RetryInterval(time.Millisecond).
+			TransportWrapper(MetricsTransportWrapper).
+			Build()
+		Expect(err).NotTo(HaveOccurred())
+		ocmClient = NewInstrumentedOcmClient(NewOcmClient(connection), metrics.OCMEnvironmentDefault)
+	})
+
+	AfterEach(func() {
+		mockServer.Close()
+	})
+
+	It("records the duration, rate limited responses and retries of a call", func() {
+		successes := metricValue("ocm_agent_ocm_request_duration_seconds", durationLabels("2xx"))
+		rateLimited := metricValue("ocm_agent_ocm_rate_limited_total", getCluster)
+		retries := metricValue("ocm_agent_ocm_request_retries_total", getCluster)
+
+		mockServer.AppendHandlers(
+			RespondWith(http.StatusTooManyRequests, `{}`),
+			RespondWith(http.StatusOK, `{"kind":"Cluster","id":"`+clusterID+`"}`, http.Header{"Content-Type": []string{"application/json"}}),
+		)
+		cluster, _, err := ocmClient.GetCluster(clusterID)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cluster.ID()).To(Equal(clusterID))
+
+		Expect(metricValue("ocm_agent_ocm_request_duration_seconds", durationLabels("2xx"))).To(Equal(successes + 1))
+		Expect(metricValue("ocm_agent_ocm_rate_limited_total", getCluster)).To(Equal(rateLimited + 1))
+		Expect(metricValue("ocm_agent_ocm_request_retries_total", getCluster)).To(Equal(retries + 1))
+	})
+
+	It("records the status class of failed calls", func() {
+		failures := metricValue("ocm_agent_ocm_request_duration_seconds", durationLabels("4xx"))
+
+		mockServer.AppendHandlers(RespondWith(http.StatusNotFound, `{"kind":"Error"}`, http.Header{"Content-Type": []string{"application/json"}}))
+		_, _, err := ocmClient.GetCluster(clusterID)
+		Expect(err).To(HaveOccurred())
+
+		Expect(metricValue("ocm_agent_ocm_request_duration_seconds", durationLabels("4xx"))).To(Equal(failures + 1))
+	})
+
+	It("classifies calls without response", func() {
+		Expect(statusClass(0, nil)).To(Equal("2xx"))
+		Expect(statusClass(503, nil)).To(Equal("5xx"))
+		Expect(statusClass(0, &RateLimitError{Err: http.ErrHandlerTimeout})).To(Equal(metrics.OCMStatusClassError))
+	})
+})
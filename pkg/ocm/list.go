@@ -0,0 +1,74 @@
+package ocm
+
+import (
+	"github.com/openshift/ocm-agent/pkg/consts"
+)
+
+const (
+	// Query parameters supported by the OCM list endpoints
+	ListParamPage   = "page"
+	ListParamSize   = "size"
+	ListParamSearch = "search"
+	ListParamOrder  = "order"
+)
+
+// ListOptions holds the query parameters of a paginated OCM list request.
+// A zero Page requests every page, a zero Size requests the maximum page size.
+type ListOptions struct {
+	Page   int
+	Size   int
+	Search string
+	Order  string
+}
+
+// unknownTotal is returned by the fetch function of listPages when OCM doesn't report the total of a list.
+const unknownTotal = -1
+
+// listTotal converts the total reported by a list response into the total expected by listPages.
+func listTotal(total int, ok bool) int {
+	if !ok {
+		return unknownTotal
+	}
+	return total
+}
+
+// listPages sends the list request built by fetch for the requested pages and concatenates their items.
+// If opts.Page is set only that page is fetched, otherwise pages are fetched from the first one until the total
+// number of items reported by OCM is reached or a page comes back empty. OCM may return fewer items than the
+// requested size on any page, so a short page only ends the list when OCM doesn't report a total.
+// The operation ID of the last request is returned.
+func listPages[T any](opts ListOptions, fetch func(page, size int) (items []T, total int, operationID string, err error)) ([]T, string, error) {
+	size := opts.Size
+	if size <= 0 || size > consts.OCMListRequestMaxPerPage {
+		size = consts.OCMListRequestMaxPerPage
+	}
+
+	if opts.Page > 0 {
+		items, _, operationID, err := fetch(opts.Page, size)
+		return items, operationID, err
+	}
+
+	var items []T
+	var operationID string
+	for page := consts.OCMListRequestStartPage; ; page++ {
+		pageItems, total, pageOperationID, err := fetch(page, size)
+		operationID = pageOperationID
+		if err != nil {
+			return nil, operationID, err
+		}
+
+		items = append(items, pageItems...)
+		if len(pageItems) == 0 {
+			break
+		}
+		if total == unknownTotal {
+			if len(pageItems) < size {
+				break
+			}
+		} else if len(items) >= total {
+			break
+		}
+	}
+
+	return items, operationID, nil
+}
@@ -0,0 +1,160 @@
+package ocm
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+
+	"github.com/openshift/ocm-agent/pkg/metrics"
+)
+
+const (
+	// Time between the checks of a healthy OCM connection
+	DefaultConnectionCheckInterval = 5 * time.Minute
+	// Time before checking a failing OCM connection again, doubled on every consecutive failure
+	DefaultConnectionRetryInterval = 30 * time.Second
+	// How long before it expires the access token is checked again, so that it is refreshed in time
+	tokenExpiryMargin = time.Minute
+)
+
+// ConnectionMonitor periodically checks that an OCM connection can obtain an access token and reach OCM,
+// and records the token expiry and refreshes, the failures and the reachability of OCM as metrics.
+type ConnectionMonitor struct {
+	connection    func() *sdk.Connection
+	pullSecret    bool
+	environment   string
+	interval      time.Duration
+	retryInterval time.Duration
+	now           func() time.Time
+
+	failures    int
+	accessToken string
+	expiry      time.Time
+}
+
+// NewConnectionMonitor returns a monitor for the current connection returned by connection, which changes when
+// its credentials are reloaded. With pullSecret, the connection authenticates with the pull secret of the cluster
+// and ocm_agent_pull_secret_invalid is set when OCM rejects it.
+func NewConnectionMonitor(connection func() *sdk.Connection, pullSecret bool) *ConnectionMonitor {
+	return &ConnectionMonitor{
+		connection:    connection,
+		pullSecret:    pullSecret,
+		interval:      DefaultConnectionCheckInterval,
+		retryInterval: DefaultConnectionRetryInterval,
+		now:           time.Now,
+	}
+}
+
+// WithEnvironment sets the name of the OCM environment the metrics of the connection are labeled with.
+func (m *ConnectionMonitor) WithEnvironment(environment string) *ConnectionMonitor {
+	m.environment = environment
+	return m
+}
+
+// Run checks the connection until the context is done.
+func (m *ConnectionMonitor) Run(ctx context.Context) {
+	for {
+		timer := time.NewTimer(m.Check(ctx))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// Check checks the connection once and returns how long to wait before the next check.
+func (m *ConnectionMonitor) Check(ctx context.Context) time.Duration {
+	logger := packageLogger.FromContext(ctx)
+	logger.Debug("OCM connection check starting")
+
+	if err := m.checkToken(ctx); err != nil {
+		logger.WithError(err).Warn("OCM connection check failure: can't obtain an access token")
+		metrics.CountOCMTokenRefreshFailure(m.environment)
+		return m.failed()
+	}
+
+	response, err := m.connection().AccountsMgmt().V1().CurrentAccount().Get().SendContext(ctx)
+	if response == nil {
+		logger.WithError(err).Warn("OCM connection check failure: OCM is unreachable")
+		metrics.SetOCMReachable(m.environment, false)
+		return m.failed()
+	}
+	metrics.SetOCMReachable(m.environment, response.Status() < http.StatusInternalServerError)
+
+	switch {
+	case response.Status() == http.StatusUnauthorized:
+		logger.Warn("OCM connection check failure: OCM rejected the credentials")
+		if m.pullSecret {
+			metrics.SetPullSecretInvalidMetricFailure()
+		}
+		return m.failed()
+	case response.Status() >= http.StatusInternalServerError:
+		logger.WithField("Status", response.Status()).Warn("OCM connection check failure: OCM is unavailable")
+		return m.failed()
+	}
+	if m.pullSecret {
+		metrics.SetPullSecretInvalidMetricSuccess()
+	}
+	logger.Debug("OCM connection check success")
+	m.failures = 0
+
+	// Check again before the token expires, so that a failing refresh is noticed before calls fail
+	delay := m.interval
+	if !m.expiry.IsZero() {
+		if untilRefresh := m.expiry.Sub(m.now()) - tokenExpiryMargin; untilRefresh < delay {
+			delay = max(untilRefresh, m.retryInterval)
+		}
+	}
+	return delay
+}
+
+// checkToken obtains the access token of the connection, which is refreshed by the SDK when it is about to expire.
+func (m *ConnectionMonitor) checkToken(ctx context.Context) error {
+	accessToken, _, err := m.connection().TokensContext(ctx)
+	if err != nil {
+		return err
+	}
+	if accessToken != m.accessToken {
+		m.accessToken = accessToken
+		m.expiry = tokenExpiry(accessToken)
+		metrics.SetOCMTokenRefreshed(m.environment, m.now(), m.expiry)
+	}
+	return nil
+}
+
+// failed returns how long to wait after a failed check, backing off exponentially up to the check interval.
+func (m *ConnectionMonitor) failed() time.Duration {
+	m.failures++
+	delay := m.retryInterval
+	for i := 1; i < m.failures && delay < m.interval; i++ {
+		delay *= 2
+	}
+	return min(delay, m.interval)
+}
+
+// tokenExpiry returns the expiry of a JWT access token, or zero if the token is not a JWT or doesn't expire.
+// The token is not verified, as it is only used to report its expiry.
+func tokenExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		Exp float64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(claims.Exp), 0)
+}
@@ -0,0 +1,138 @@
+package ocm
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/ghttp"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	. "github.com/openshift-online/ocm-sdk-go/testing"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/openshift/ocm-agent/pkg/metrics"
+)
+
+var _ = Describe("OCM connection monitor", func() {
+	const currentAccountPath = "/api/accounts_mgmt/v1/current_account"
+
+	var (
+		mockServer *Server
+		now        time.Time
+		noLabels   = prometheus.Labels{}
+		defaultEnv = prometheus.Labels{"ocm_environment": metrics.OCMEnvironmentDefault}
+	)
+
+	BeforeEach(func() {
+		mockServer = NewServer()
+		now = time.Now()
+	})
+
+	AfterEach(func() {
+		mockServer.Close()
+	})
+
+	newMonitor := func(connection *sdk.Connection, pullSecret bool) *ConnectionMonitor {
+		monitor := NewConnectionMonitor(func() *sdk.Connection { return connection }, pullSecret)
+		monitor.now = func() time.Time { return now }
+		return monitor
+	}
+
+	Context("with a pull secret", func() {
+		var monitor *ConnectionMonitor
+
+		BeforeEach(func() {
+			connection, err := NewConnection().Build(mockServer.URL(), "bd845de4-5c16-4067-a868-15b02d55ccef", "cHVsbC1zZWNyZXQ=")
+			Expect(err).NotTo(HaveOccurred())
+			monitor = newMonitor(connection, true)
+		})
+
+		It("reports a reachable OCM and a valid pull secret", func() {
+			mockServer.AppendHandlers(CombineHandlers(
+				VerifyRequest(http.MethodGet, currentAccountPath),
+				RespondWithJSON(http.StatusOK, `{"kind": "Account"}`),
+			))
+
+			Expect(monitor.Check(context.Background())).To(Equal(DefaultConnectionCheckInterval))
+			Expect(metricValue("ocm_agent_ocm_reachable", defaultEnv)).To(Equal(float64(1)))
+			Expect(metricValue("ocm_agent_pull_secret_invalid", noLabels)).To(Equal(float64(0)))
+		})
+
+		It("reports an invalid pull secret and backs off", func() {
+			mockServer.AppendHandlers(
+				RespondWithJSON(http.StatusUnauthorized, `{"kind": "Error"}`),
+				RespondWithJSON(http.StatusUnauthorized, `{"kind": "Error"}`),
+			)
+
+			Expect(monitor.Check(context.Background())).To(Equal(DefaultConnectionRetryInterval))
+			Expect(monitor.Check(context.Background())).To(Equal(2 * DefaultConnectionRetryInterval))
+			Expect(metricValue("ocm_agent_pull_secret_invalid", noLabels)).To(Equal(float64(1)))
+			Expect(metricValue("ocm_agent_ocm_reachable", defaultEnv)).To(Equal(float64(1)))
+		})
+
+		It("reports an unreachable OCM", func() {
+			mockServer.Close()
+
+			Expect(monitor.Check(context.Background())).To(Equal(DefaultConnectionRetryInterval))
+			Expect(metricValue("ocm_agent_ocm_reachable", defaultEnv)).To(Equal(float64(0)))
+		})
+	})
+
+	Context("with client credentials", func() {
+		var monitor *ConnectionMonitor
+
+		BeforeEach(func() {
+			connection, err := sdk.NewConnectionBuilder().
+				URL(mockServer.URL()).
+				TokenURL(mockServer.URL()+"/token").
+				Client("client-id", "client-secret").
+				RetryLimit(0).
+				Build()
+			Expect(err).NotTo(HaveOccurred())
+			monitor = newMonitor(connection, false)
+		})
+
+		It("reports the expiry of the access token and checks again before it expires", func() {
+			accessToken := MakeTokenString("Bearer", 3*time.Minute)
+			mockServer.AppendHandlers(
+				CombineHandlers(VerifyRequest(http.MethodPost, "/token"), RespondWithAccessToken(accessToken)),
+				RespondWithJSON(http.StatusOK, `{"kind": "Account"}`),
+			)
+
+			delay := monitor.Check(context.Background())
+			expiry := tokenExpiry(accessToken)
+			Expect(expiry).To(BeTemporally("~", time.Now().Add(3*time.Minute), 5*time.Second))
+			Expect(delay).To(Equal(expiry.Sub(now) - tokenExpiryMargin))
+			Expect(metricValue("ocm_agent_ocm_token_expiry_timestamp_seconds", defaultEnv)).To(Equal(float64(expiry.Unix())))
+			Expect(metricValue("ocm_agent_ocm_token_last_refresh_timestamp_seconds", defaultEnv)).To(Equal(float64(now.Unix())))
+		})
+
+		It("counts the failures to obtain an access token", func() {
+			failures := metricValue("ocm_agent_ocm_token_refresh_failures_total", defaultEnv)
+			mockServer.AppendHandlers(RespondWithTokenError("invalid_client", "Invalid client credentials"))
+
+			Expect(monitor.Check(context.Background())).To(Equal(DefaultConnectionRetryInterval))
+			Expect(metricValue("ocm_agent_ocm_token_refresh_failures_total", defaultEnv)).To(Equal(failures + 1))
+		})
+	})
+
+	It("labels the metrics with the OCM environment", func() {
+		connection, err := NewConnection().Build(mockServer.URL(), "bd845de4-5c16-4067-a868-15b02d55ccef", "cHVsbC1zZWNyZXQ=")
+		Expect(err).NotTo(HaveOccurred())
+		monitor := newMonitor(connection, false).WithEnvironment("integration")
+		mockServer.Close()
+
+		Expect(monitor.Check(context.Background())).To(Equal(DefaultConnectionRetryInterval))
+		Expect(metricValue("ocm_agent_ocm_reachable", prometheus.Labels{"ocm_environment": "integration"})).To(Equal(float64(0)))
+	})
+
+	It("doesn't back off beyond the check interval", func() {
+		monitor := newMonitor(nil, false)
+		for range 10 {
+			monitor.failed()
+		}
+		Expect(monitor.failed()).To(Equal(DefaultConnectionCheckInterval))
+	})
+})
@@ -1,17 +1,20 @@
 package ocm
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
 
 	sdk "github.com/openshift-online/ocm-sdk-go"
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	ocmerrors "github.com/openshift-online/ocm-sdk-go/errors"
 	slv1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
 	"github.com/openshift/ocm-agent-operator/api/v1alpha1"
 	"github.com/openshift/ocm-agent/pkg/consts"
+	"github.com/openshift/ocm-agent/pkg/logging"
 	"github.com/prometheus/alertmanager/template"
-	log "github.com/sirupsen/logrus"
 )
 
 const (
@@ -33,6 +36,33 @@ func (e *RateLimitError) Unwrap() error {
 	return e.Err
 }
 
+// unexpectedStatusError indicates that OCM answered with a status the SDK doesn't report as an error, e.g. an error
+// status without body.
+type unexpectedStatusError struct {
+	status int
+}
+
+func (e *unexpectedStatusError) Error() string {
+	return fmt.Sprintf("unexpected status: %d", e.status)
+}
+
+// errorStatus returns the HTTP status OCM answered a failed call with, or 0 when OCM couldn't be reached.
+func errorStatus(err error) int {
+	var sdkErr *ocmerrors.Error
+	if errors.As(err, &sdkErr) {
+		return sdkErr.Status()
+	}
+	var statusErr *unexpectedStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.status
+	}
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		return http.StatusTooManyRequests
+	}
+	return 0
+}
+
 type ServiceLogBuilder struct {
 	wrappedBuilder *slv1.LogEntryBuilder
 	summary        string
@@ -60,6 +90,9 @@ func NewServiceLogBuilder(summary, firingDesc, resolveDesc, clusterUUID string,
 
 var (
 	slVarRefRe = regexp.MustCompile(`\${[^{}]*}`)
+
+	// packageLogger logs at the level of the ocm package, which can be changed at runtime
+	packageLogger = logging.ForPackage(logging.PackageOCM)
 )
 
 // Replace place holders in the given string with the alert labels and annotations
@@ -135,33 +168,62 @@ type OCMClient interface {
 	GetCluster(clusterID string) (*cmv1.Cluster, string, error)
 	GetUpgradePolicyState(clusterID string, upgradePolicyID string) (*cmv1.UpgradePolicyState, string, error)
 	GetUpgradePolicy(clusterID string, upgradePolicyID string) (*cmv1.UpgradePolicy, string, error)
-	GetUpgradePolicies(clusterID string) ([]*cmv1.UpgradePolicy, string, error)
+	GetUpgradePolicies(clusterID string, opts ListOptions) ([]*cmv1.UpgradePolicy, string, error)
+	GetServiceLogs(clusterUUID string, opts ListOptions) ([]*slv1.LogEntry, string, error)
 	UpdateUpgradePolicyState(clusterID string, upgradePolicyID string, policyState *cmv1.UpgradePolicyState) (*cmv1.UpgradePolicyState, string, error)
+	CreateUpgradePolicy(clusterID string, policy *cmv1.UpgradePolicy) (*cmv1.UpgradePolicy, string, error)
+	UpdateUpgradePolicy(clusterID string, upgradePolicyID string, policy *cmv1.UpgradePolicy) (*cmv1.UpgradePolicy, string, error)
+	DeleteUpgradePolicy(clusterID string, upgradePolicyID string) (string, error)
 }
 
 type ocmClientImpl struct {
 	ocmConnection *sdk.Connection
+	ctx           context.Context
+}
+
+// contextClient is implemented by the OCM clients, and the decorators wrapping them, which log or trace
+// their calls with the request-scoped logger and span of a context.
+type contextClient interface {
+	withContext(ctx context.Context) OCMClient
+}
+
+// WithContext returns a client making its calls for the request ctx belongs to, logging them with the
+// request-scoped logger of ctx and tracing them as children of its span.
+// Clients which don't support contexts are returned as is.
+func WithContext(o OCMClient, ctx context.Context) OCMClient {
+	c, ok := o.(contextClient)
+	if !ok {
+		return o
+	}
+	return c.withContext(ctx)
 }
 
 //go:generate mockgen -destination=mocks/ocm.go -package=mocks github.com/openshift/ocm-agent/pkg/ocm OCMClient
 func NewOcmClient(ocmConnection *sdk.Connection) OCMClient {
 	return &ocmClientImpl{
 		ocmConnection: ocmConnection,
+		ctx:           context.Background(),
 	}
 }
 
+func (o *ocmClientImpl) withContext(ctx context.Context) OCMClient {
+	withContext := *o
+	withContext.ctx = ctx
+	return &withContext
+}
+
 // https://pkg.go.dev/github.com/openshift-online/ocm-sdk-go@v0.1.382/clustersmgmt/v1#Cluster
 func (o *ocmClientImpl) GetCluster(clusterID string) (*cmv1.Cluster, string, error) {
-	log.Debugf("Sending get cluster object request to OCM API: %s", clusterID)
+	packageLogger.FromContext(o.ctx).Debugf("Sending get cluster object request to OCM API: %s", clusterID)
 	request := o.ocmConnection.ClustersMgmt().V1().Clusters().Cluster(clusterID)
-	resp, err := request.Get().Send()
+	resp, err := request.Get().SendContext(o.ctx)
 	if err != nil {
 		return nil, resp.Header().Get(OcmOperationIdHeader), err
 	}
 
 	if resp.Status() < 200 || resp.Status() >= 300 {
 		// Extract error details from the resp and return an appropriate error.
-		return nil, resp.Header().Get(OcmOperationIdHeader), fmt.Errorf("unexpected status: %d", resp.Status())
+		return nil, resp.Header().Get(OcmOperationIdHeader), &unexpectedStatusError{status: resp.Status()}
 	}
 
 	return resp.Body(), resp.Header().Get(OcmOperationIdHeader), nil
@@ -170,16 +232,16 @@ func (o *ocmClientImpl) GetCluster(clusterID string) (*cmv1.Cluster, string, err
 // GetUpgradePolicy gets a single upgrade policy from a cluster.
 // Proxies to https://api.openshift.com/#/default/get_api_clusters_mgmt_v1_clusters__cluster_id__upgrade_policies__upgrade_policy_id_
 func (o *ocmClientImpl) GetUpgradePolicy(clusterID string, upgradePolicyID string) (*cmv1.UpgradePolicy, string, error) {
-	log.Debugf("Sending get upgrade policy request to OCM API: %s %s", clusterID, upgradePolicyID)
+	packageLogger.FromContext(o.ctx).Debugf("Sending get upgrade policy request to OCM API: %s %s", clusterID, upgradePolicyID)
 	request := o.ocmConnection.ClustersMgmt().V1().Clusters().Cluster(clusterID).UpgradePolicies().UpgradePolicy(upgradePolicyID)
-	resp, err := request.Get().Send()
+	resp, err := request.Get().SendContext(o.ctx)
 	if err != nil {
 		return nil, resp.Header().Get(OcmOperationIdHeader), err
 	}
 
 	if resp.Status() < 200 || resp.Status() >= 300 {
 		// Extract error details from the resp and return an appropriate error.
-		return nil, resp.Header().Get(OcmOperationIdHeader), fmt.Errorf("unexpected status: %d", resp.Status())
+		return nil, resp.Header().Get(OcmOperationIdHeader), &unexpectedStatusError{status: resp.Status()}
 	}
 	return resp.Body(), resp.Header().Get(OcmOperationIdHeader), nil
 }
@@ -187,90 +249,143 @@ func (o *ocmClientImpl) GetUpgradePolicy(clusterID string, upgradePolicyID strin
 // GetUpgradePolicy gets a single upgrade policy's state from a cluster.
 // Proxies to https://api.openshift.com#/default/get_api_clusters_mgmt_v1_clusters__cluster_id__upgrade_policies__upgrade_policy_id__state
 func (o *ocmClientImpl) GetUpgradePolicyState(clusterID string, upgradePolicyID string) (*cmv1.UpgradePolicyState, string, error) {
-	log.Debugf("Sending get upgrade policy state request to OCM API: %s", clusterID)
+	packageLogger.FromContext(o.ctx).Debugf("Sending get upgrade policy state request to OCM API: %s", clusterID)
 	request := o.ocmConnection.ClustersMgmt().V1().Clusters().Cluster(clusterID).UpgradePolicies().UpgradePolicy(upgradePolicyID).State()
-	resp, err := request.Get().Send()
+	resp, err := request.Get().SendContext(o.ctx)
 	if err != nil {
 		return nil, resp.Header().Get(OcmOperationIdHeader), err
 	}
 
 	if resp.Status() < 200 || resp.Status() >= 300 {
 		// Extract error details from the resp and return an appropriate error.
-		return nil, resp.Header().Get(OcmOperationIdHeader), fmt.Errorf("unexpected status: %d", resp.Status())
+		return nil, resp.Header().Get(OcmOperationIdHeader), &unexpectedStatusError{status: resp.Status()}
 	}
 	return resp.Body(), resp.Header().Get(OcmOperationIdHeader), nil
 }
 
-// GetUpgradePolicies gets all of the upgrade policies belonging to a cluster from OCM.
-// It does not paginate, and sends the whole list as a single list.
+// GetUpgradePolicies gets the upgrade policies belonging to a cluster from OCM.
+// All pages are fetched and returned as a single list unless a page is given in the list options.
 // Proxies to https://api.openshift.com/#/default/get_api_clusters_mgmt_v1_clusters__cluster_id__upgrade_policies
-func (o *ocmClientImpl) GetUpgradePolicies(clusterID string) ([]*cmv1.UpgradePolicy, string, error) {
-	var upgradePolicies []*cmv1.UpgradePolicy
-	var operationIdHeader string
-
-	log.Debugf("Sending get all upgrade polices request to OCM API: %s", clusterID)
+func (o *ocmClientImpl) GetUpgradePolicies(clusterID string, opts ListOptions) ([]*cmv1.UpgradePolicy, string, error) {
+	packageLogger.FromContext(o.ctx).Debugf("Sending get all upgrade polices request to OCM API: %s", clusterID)
 	collection := o.ocmConnection.ClustersMgmt().V1().Clusters().Cluster(clusterID).UpgradePolicies()
-	page := consts.OCMListRequestStartPage
-	size := consts.OCMListRequestMaxPerPage
 
-	for {
-		resp, err := collection.List().Send()
+	return listPages(opts, func(page, size int) ([]*cmv1.UpgradePolicy, int, string, error) {
+		request := collection.List().Page(page).Size(size)
+		if opts.Search != "" {
+			request = request.Parameter(ListParamSearch, opts.Search)
+		}
+		if opts.Order != "" {
+			request = request.Parameter(ListParamOrder, opts.Order)
+		}
 
+		resp, err := request.SendContext(o.ctx)
 		if err != nil {
-			return nil, resp.Header().Get(OcmOperationIdHeader), err
+			return nil, 0, resp.Header().Get(OcmOperationIdHeader), err
 		}
 
 		if resp.Status() < 200 || resp.Status() >= 300 {
 			// Extract error details from the resp and return an appropriate error.
-			return nil, resp.Header().Get(OcmOperationIdHeader), fmt.Errorf("unexpected status: %d", resp.Status())
+			return nil, 0, resp.Header().Get(OcmOperationIdHeader), &unexpectedStatusError{status: resp.Status()}
 		}
 
-		upgradePolicies = append(upgradePolicies, resp.Items().Slice()...)
-		operationIdHeader = resp.Header().Get(OcmOperationIdHeader)
-		if resp.Size() < size {
-			break
-		}
-		page++
-	}
-
-	return upgradePolicies, operationIdHeader, nil
+		return resp.Items().Slice(), listTotal(resp.GetTotal()), resp.Header().Get(OcmOperationIdHeader), nil
+	})
 }
 
 // UpdateUpgradePolicyState updates a single upgrade policy's state for a given cluster.
 // Proxies to https://api.openshift.com/#/default/patch_api_clusters_mgmt_v1_clusters__cluster_id__upgrade_policies__upgrade_policy_id__state
 func (o *ocmClientImpl) UpdateUpgradePolicyState(clusterID string, upgradePolicyID string, policyState *cmv1.UpgradePolicyState) (*cmv1.UpgradePolicyState, string, error) {
-	log.Debugf("Sending update upgrade policy state request to OCM API: %s %s", clusterID, upgradePolicyID)
+	packageLogger.FromContext(o.ctx).Debugf("Sending update upgrade policy state request to OCM API: %s %s", clusterID, upgradePolicyID)
 	request := o.ocmConnection.ClustersMgmt().V1().Clusters().Cluster(clusterID).UpgradePolicies().UpgradePolicy(upgradePolicyID).State().Update().Body(policyState)
-	resp, err := request.Send()
+	resp, err := request.SendContext(o.ctx)
+	if err != nil {
+		return nil, resp.Header().Get(OcmOperationIdHeader), err
+	}
+	return resp.Body(), resp.Header().Get(OcmOperationIdHeader), nil
+}
+
+// CreateUpgradePolicy schedules a new upgrade policy for a given cluster.
+// Proxies to https://api.openshift.com/#/default/post_api_clusters_mgmt_v1_clusters__cluster_id__upgrade_policies
+func (o *ocmClientImpl) CreateUpgradePolicy(clusterID string, policy *cmv1.UpgradePolicy) (*cmv1.UpgradePolicy, string, error) {
+	packageLogger.FromContext(o.ctx).Debugf("Sending create upgrade policy request to OCM API: %s", clusterID)
+	request := o.ocmConnection.ClustersMgmt().V1().Clusters().Cluster(clusterID).UpgradePolicies().Add().Body(policy)
+	resp, err := request.SendContext(o.ctx)
 	if err != nil {
 		return nil, resp.Header().Get(OcmOperationIdHeader), err
 	}
+
+	if resp.Status() < 200 || resp.Status() >= 300 {
+		// Extract error details from the resp and return an appropriate error.
+		return nil, resp.Header().Get(OcmOperationIdHeader), &unexpectedStatusError{status: resp.Status()}
+	}
 	return resp.Body(), resp.Header().Get(OcmOperationIdHeader), nil
 }
 
+// UpdateUpgradePolicy updates a single upgrade policy for a given cluster.
+// Proxies to https://api.openshift.com/#/default/patch_api_clusters_mgmt_v1_clusters__cluster_id__upgrade_policies__upgrade_policy_id_
+func (o *ocmClientImpl) UpdateUpgradePolicy(clusterID string, upgradePolicyID string, policy *cmv1.UpgradePolicy) (*cmv1.UpgradePolicy, string, error) {
+	packageLogger.FromContext(o.ctx).Debugf("Sending update upgrade policy request to OCM API: %s %s", clusterID, upgradePolicyID)
+	request := o.ocmConnection.ClustersMgmt().V1().Clusters().Cluster(clusterID).UpgradePolicies().UpgradePolicy(upgradePolicyID).Update().Body(policy)
+	resp, err := request.SendContext(o.ctx)
+	if err != nil {
+		return nil, resp.Header().Get(OcmOperationIdHeader), err
+	}
+
+	if resp.Status() < 200 || resp.Status() >= 300 {
+		// Extract error details from the resp and return an appropriate error.
+		return nil, resp.Header().Get(OcmOperationIdHeader), &unexpectedStatusError{status: resp.Status()}
+	}
+	return resp.Body(), resp.Header().Get(OcmOperationIdHeader), nil
+}
+
+// DeleteUpgradePolicy cancels a single upgrade policy for a given cluster.
+// Proxies to https://api.openshift.com/#/default/delete_api_clusters_mgmt_v1_clusters__cluster_id__upgrade_policies__upgrade_policy_id_
+func (o *ocmClientImpl) DeleteUpgradePolicy(clusterID string, upgradePolicyID string) (string, error) {
+	packageLogger.FromContext(o.ctx).Debugf("Sending delete upgrade policy request to OCM API: %s %s", clusterID, upgradePolicyID)
+	request := o.ocmConnection.ClustersMgmt().V1().Clusters().Cluster(clusterID).UpgradePolicies().UpgradePolicy(upgradePolicyID).Delete()
+	resp, err := request.SendContext(o.ctx)
+	if err != nil {
+		return resp.Header().Get(OcmOperationIdHeader), err
+	}
+
+	if resp.Status() < 200 || resp.Status() >= 300 {
+		// Extract error details from the resp and return an appropriate error.
+		return resp.Header().Get(OcmOperationIdHeader), &unexpectedStatusError{status: resp.Status()}
+	}
+	return resp.Header().Get(OcmOperationIdHeader), nil
+}
+
 func (o *ocmClientImpl) SendServiceLog(logEntry *slv1.LogEntry) error {
+	_, err := o.sendServiceLog(logEntry)
+	return err
+}
+
+// sendServiceLog posts the service log and returns the OCM operation ID of the request.
+func (o *ocmClientImpl) sendServiceLog(logEntry *slv1.LogEntry) (string, error) {
+	packageLogger.FromContext(o.ctx).Debugf("Sending post service log request to OCM API: %s", logEntry.ClusterUUID())
 	// Use the OCM SDK to construct the request for posting a service log for a specific cluster.
 	request := o.ocmConnection.ServiceLogs().V1().ClusterLogs().Add().Body(logEntry)
 
 	// Send the request to the OCM API.
-	response, err := request.Send()
+	response, err := request.SendContext(o.ctx)
 	if err != nil {
 		if response != nil && response.Status() == http.StatusTooManyRequests {
-			return &RateLimitError{Err: fmt.Errorf("can't post service log: rate limited (HTTP 429): %w", err)}
+			return response.Header().Get(OcmOperationIdHeader), &RateLimitError{Err: fmt.Errorf("can't post service log: rate limited (HTTP 429): %w", err)}
 		}
-		return fmt.Errorf("can't post service log: %v", err)
+		return response.Header().Get(OcmOperationIdHeader), fmt.Errorf("can't post service log: %v", err)
 	}
 
 	// Check the response status code.
 	if response.Status() == http.StatusTooManyRequests {
-		return &RateLimitError{Err: fmt.Errorf("can't post service log: rate limited (HTTP 429)")}
+		return response.Header().Get(OcmOperationIdHeader), &RateLimitError{Err: fmt.Errorf("can't post service log: rate limited (HTTP 429)")}
 	}
 	if response.Status() != http.StatusCreated {
 		// Extract error details from the response and return an appropriate error.
-		return fmt.Errorf("unexpected status: %d", response.Status())
+		return response.Header().Get(OcmOperationIdHeader), &unexpectedStatusError{status: response.Status()}
 	}
 
-	return nil
+	return response.Header().Get(OcmOperationIdHeader), nil
 }
 
 func BuildAndSendServiceLog(slBuilder *ServiceLogBuilder, firing bool, alert *template.Alert, ocmClient OCMClient) error {
@@ -282,62 +397,112 @@ func BuildAndSendServiceLog(slBuilder *ServiceLogBuilder, firing bool, alert *te
 }
 
 func (o *ocmClientImpl) SendLimitedSupport(clusterUUID string, lsReason *cmv1.LimitedSupportReason) error {
-	internalID, err := GetInternalIDByExternalID(clusterUUID, o.ocmConnection)
+	_, err := o.sendLimitedSupport(clusterUUID, lsReason)
+	return err
+}
+
+// sendLimitedSupport adds the limited support reason and returns the OCM operation ID of the request.
+func (o *ocmClientImpl) sendLimitedSupport(clusterUUID string, lsReason *cmv1.LimitedSupportReason) (string, error) {
+	packageLogger.FromContext(o.ctx).Debugf("Sending post limited support reason request to OCM API: %s", clusterUUID)
+	internalID, err := getInternalIDByExternalID(o.ctx, clusterUUID, o.ocmConnection)
 	if err != nil {
-		return fmt.Errorf("can't get internal id: %w", err)
+		return "", fmt.Errorf("can't get internal id: %w", err)
 	}
 
-	response, err := o.ocmConnection.ClustersMgmt().V1().Clusters().Cluster(internalID).LimitedSupportReasons().Add().Body(lsReason).Send()
+	response, err := o.ocmConnection.ClustersMgmt().V1().Clusters().Cluster(internalID).LimitedSupportReasons().Add().Body(lsReason).SendContext(o.ctx)
 	if err != nil {
-		return fmt.Errorf("can't post limited support: %w", err)
+		return response.Header().Get(OcmOperationIdHeader), fmt.Errorf("can't post limited support: %w", err)
 	}
 
 	// Check the response status code
 	if response.Status() < 200 || response.Status() >= 300 {
 		// Extract error details from the response and return an appropriate error.
-		return fmt.Errorf("unexpected status: %d", response.Status())
+		return response.Header().Get(OcmOperationIdHeader), &unexpectedStatusError{status: response.Status()}
 	}
 
-	return nil
+	return response.Header().Get(OcmOperationIdHeader), nil
 }
 
 func (o *ocmClientImpl) RemoveLimitedSupport(clusterUUID string, lsReasonID string) error {
-	internalID, err := GetInternalIDByExternalID(clusterUUID, o.ocmConnection)
+	_, err := o.removeLimitedSupport(clusterUUID, lsReasonID)
+	return err
+}
+
+// removeLimitedSupport deletes the limited support reason and returns the OCM operation ID of the request.
+func (o *ocmClientImpl) removeLimitedSupport(clusterUUID string, lsReasonID string) (string, error) {
+	packageLogger.FromContext(o.ctx).Debugf("Sending delete limited support reason request to OCM API: %s %s", clusterUUID, lsReasonID)
+	internalID, err := getInternalIDByExternalID(o.ctx, clusterUUID, o.ocmConnection)
 	if err != nil {
-		return fmt.Errorf("can't get internal id: %w", err)
+		return "", fmt.Errorf("can't get internal id: %w", err)
 	}
 
-	response, err := o.ocmConnection.ClustersMgmt().V1().Clusters().Cluster(internalID).LimitedSupportReasons().LimitedSupportReason(lsReasonID).Delete().Send()
+	response, err := o.ocmConnection.ClustersMgmt().V1().Clusters().Cluster(internalID).LimitedSupportReasons().LimitedSupportReason(lsReasonID).Delete().SendContext(o.ctx)
 	if err != nil {
-		return fmt.Errorf("can't delete limited support reason %s from cluster %s: %w", lsReasonID, clusterUUID, err)
+		return response.Header().Get(OcmOperationIdHeader), fmt.Errorf("can't delete limited support reason %s from cluster %s: %w", lsReasonID, clusterUUID, err)
 	}
 
 	// Check the response status code
 	if response.Status() < 200 || response.Status() >= 300 {
 		// Extract error details from the response and return an appropriate error.
-		return fmt.Errorf("unexpected status: %d", response.Status())
+		return response.Header().Get(OcmOperationIdHeader), &unexpectedStatusError{status: response.Status()}
 	}
 
-	return nil
+	return response.Header().Get(OcmOperationIdHeader), nil
 }
 
 func (o *ocmClientImpl) GetLimitedSupportReasons(clusterUUID string) ([]*cmv1.LimitedSupportReason, error) {
-
-	internalID, err := GetInternalIDByExternalID(clusterUUID, o.ocmConnection)
+	packageLogger.FromContext(o.ctx).Debugf("Sending get limited support reasons request to OCM API: %s", clusterUUID)
+	internalID, err := getInternalIDByExternalID(o.ctx, clusterUUID, o.ocmConnection)
 	if err != nil {
 		return nil, fmt.Errorf("can't get internal id: %w", err)
 	}
 
-	response, err := o.ocmConnection.ClustersMgmt().V1().Clusters().Cluster(internalID).LimitedSupportReasons().List().Send()
-	if err != nil {
-		return nil, fmt.Errorf("can't get limited support reasons: %w", err)
-	}
+	collection := o.ocmConnection.ClustersMgmt().V1().Clusters().Cluster(internalID).LimitedSupportReasons()
 
-	// Check the response status code
-	if response.Status() < 200 || response.Status() >= 300 {
-		// Extract error details from the response and return an appropriate error.
-		return nil, fmt.Errorf("unexpected status: %d", response.Status())
-	}
+	reasons, _, err := listPages(ListOptions{}, func(page, size int) ([]*cmv1.LimitedSupportReason, int, string, error) {
+		response, err := collection.List().Page(page).Size(size).SendContext(o.ctx)
+		if err != nil {
+			return nil, 0, response.Header().Get(OcmOperationIdHeader), fmt.Errorf("can't get limited support reasons: %w", err)
+		}
+
+		// Check the response status code
+		if response.Status() < 200 || response.Status() >= 300 {
+			// Extract error details from the response and return an appropriate error.
+			return nil, 0, response.Header().Get(OcmOperationIdHeader), &unexpectedStatusError{status: response.Status()}
+		}
+
+		return response.Items().Slice(), listTotal(response.GetTotal()), response.Header().Get(OcmOperationIdHeader), nil
+	})
+
+	return reasons, err
+}
+
+// GetServiceLogs gets the service logs of a cluster from OCM.
+// All pages are fetched and returned as a single list unless a page is given in the list options.
+// Proxies to https://api.openshift.com/#/default/get_api_service_logs_v1_clusters_cluster_logs
+func (o *ocmClientImpl) GetServiceLogs(clusterUUID string, opts ListOptions) ([]*slv1.LogEntry, string, error) {
+	packageLogger.FromContext(o.ctx).Debugf("Sending get service logs request to OCM API: %s", clusterUUID)
+	collection := o.ocmConnection.ServiceLogs().V1().Clusters().ClusterLogs()
+
+	return listPages(opts, func(page, size int) ([]*slv1.LogEntry, int, string, error) {
+		request := collection.List().ClusterUUID(clusterUUID).Page(page).Size(size)
+		if opts.Search != "" {
+			request = request.Search(opts.Search)
+		}
+		if opts.Order != "" {
+			request = request.Order(opts.Order)
+		}
+
+		resp, err := request.SendContext(o.ctx)
+		if err != nil {
+			return nil, 0, resp.Header().Get(OcmOperationIdHeader), fmt.Errorf("can't get service logs: %w", err)
+		}
+
+		if resp.Status() < 200 || resp.Status() >= 300 {
+			// Extract error details from the resp and return an appropriate error.
+			return nil, 0, resp.Header().Get(OcmOperationIdHeader), &unexpectedStatusError{status: resp.Status()}
+		}
 
-	return response.Items().Slice(), nil
+		return resp.Items().Slice(), listTotal(resp.GetTotal()), resp.Header().Get(OcmOperationIdHeader), nil
+	})
 }
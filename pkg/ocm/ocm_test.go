@@ -241,11 +241,86 @@ var _ = Describe("OCM client Handler", func() {
 					http.Header{"Content-Type": []string{"application/json"}},
 				),
 			))
-			upgradePolicyArray, _, err := ocmClient.GetUpgradePolicies(clusterID)
+			upgradePolicyArray, _, err := ocmClient.GetUpgradePolicies(clusterID, ListOptions{})
 			Expect(upgradePolicyArray).ShouldNot(BeNil())
 			Expect(err).ShouldNot(HaveOccurred())
 			Expect(len(upgradePolicyArray)).To(Equal(2))
 		})
+		It("should fetch every page of upgrade policies", func() {
+			firstPage := `{"page":1,"size":1,"total":2,"items": [` + upgradePolicy1 + `]}`
+			secondPage := `{"page":2,"size":1,"total":2,"items": [` + upgradePolicy2 + `]}`
+			mockServer.SetHandler(0, CombineHandlers(
+				VerifyRequest("GET", fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s/upgrade_policies", clusterID), "page=1&size=1"),
+				RespondWith(http.StatusOK, firstPage, http.Header{"Content-Type": []string{"application/json"}}),
+			))
+			mockServer.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest("GET", fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s/upgrade_policies", clusterID), "page=2&size=1"),
+					RespondWith(http.StatusOK, secondPage, http.Header{"Content-Type": []string{"application/json"}}),
+				),
+			)
+			upgradePolicies, _, err := ocmClient.GetUpgradePolicies(clusterID, ListOptions{Size: 1})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(len(upgradePolicies)).To(Equal(2))
+			Expect(mockServer.ReceivedRequests()).To(HaveLen(2))
+		})
+		It("should fetch every page of upgrade policies when ocm caps the page size below the requested one", func() {
+			firstPage := `{"page":1,"size":1,"total":2,"items": [` + upgradePolicy1 + `]}`
+			secondPage := `{"page":2,"size":1,"total":2,"items": [` + upgradePolicy2 + `]}`
+			mockServer.SetHandler(0, CombineHandlers(
+				VerifyRequest("GET", fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s/upgrade_policies", clusterID), "page=1&size=2"),
+				RespondWith(http.StatusOK, firstPage, http.Header{"Content-Type": []string{"application/json"}}),
+			))
+			mockServer.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest("GET", fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s/upgrade_policies", clusterID), "page=2&size=2"),
+					RespondWith(http.StatusOK, secondPage, http.Header{"Content-Type": []string{"application/json"}}),
+				),
+			)
+			upgradePolicies, _, err := ocmClient.GetUpgradePolicies(clusterID, ListOptions{Size: 2})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(len(upgradePolicies)).To(Equal(2))
+			Expect(upgradePolicies[1].ID()).To(Equal(upgradePolicyID + "-222"))
+			Expect(mockServer.ReceivedRequests()).To(HaveLen(2))
+		})
+		It("should stop fetching the pages of upgrade policies on an empty page", func() {
+			firstPage := `{"page":1,"size":1,"total":3,"items": [` + upgradePolicy1 + `]}`
+			emptyPage := `{"page":2,"size":0,"total":3,"items": []}`
+			mockServer.SetHandler(0, CombineHandlers(
+				VerifyRequest("GET", fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s/upgrade_policies", clusterID), "page=1&size=2"),
+				RespondWith(http.StatusOK, firstPage, http.Header{"Content-Type": []string{"application/json"}}),
+			))
+			mockServer.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest("GET", fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s/upgrade_policies", clusterID), "page=2&size=2"),
+					RespondWith(http.StatusOK, emptyPage, http.Header{"Content-Type": []string{"application/json"}}),
+				),
+			)
+			upgradePolicies, _, err := ocmClient.GetUpgradePolicies(clusterID, ListOptions{Size: 2})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(len(upgradePolicies)).To(Equal(1))
+			Expect(mockServer.ReceivedRequests()).To(HaveLen(2))
+		})
+		It("should pass the list options through when fetching a single page of upgrade policies", func() {
+			mockServer.SetHandler(0, CombineHandlers(
+				VerifyRequest("GET", fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s/upgrade_policies", clusterID), "order=next_run+desc&page=2&search=schedule_type+%3D+%27manual%27&size=2"),
+				RespondWith(http.StatusOK, upgradePoliciesList, http.Header{"Content-Type": []string{"application/json"}}),
+			))
+			upgradePolicies, _, err := ocmClient.GetUpgradePolicies(clusterID, ListOptions{Page: 2, Size: 2, Search: "schedule_type = 'manual'", Order: "next_run desc"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(len(upgradePolicies)).To(Equal(2))
+			Expect(mockServer.ReceivedRequests()).To(HaveLen(1))
+		})
+		It("should pass the list options through when fetching service logs", func() {
+			mockServer.SetHandler(0, CombineHandlers(
+				VerifyRequest("GET", "/api/service_logs/v1/clusters/cluster_logs", "cluster_uuid="+clusterUUID+"&order=timestamp+desc&page=1&search=severity+%3D+%27Info%27&size=5"),
+				RespondWith(http.StatusOK, `{"kind":"ClusterLogList","page":1,"size":1,"total":1,"items":[{"kind":"ClusterLog","id":"log-id","summary":"test"}]}`, http.Header{"Content-Type": []string{"application/json"}}),
+			))
+			serviceLogs, _, err := ocmClient.GetServiceLogs(clusterUUID, ListOptions{Page: 1, Size: 5, Search: "severity = 'Info'", Order: "timestamp desc"})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(len(serviceLogs)).To(Equal(1))
+			Expect(serviceLogs[0].ID()).To(Equal("log-id"))
+		})
 		It("should return an error fetching upgrade policies when not found", func() {
 			mockServer.SetHandler(0, CombineHandlers(
 				VerifyRequest("GET", fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s/upgrade_policies", clusterID)),
@@ -255,7 +330,7 @@ var _ = Describe("OCM client Handler", func() {
 					http.Header{"Content-Type": []string{"application/json"}},
 				),
 			))
-			upgradePolicyState, _, err := ocmClient.GetUpgradePolicies(clusterID)
+			upgradePolicyState, _, err := ocmClient.GetUpgradePolicies(clusterID, ListOptions{})
 			Expect(upgradePolicyState).Should(BeNil())
 			Expect(err).Should(HaveOccurred())
 		})
@@ -286,6 +361,54 @@ var _ = Describe("OCM client Handler", func() {
 			Expect(upgradePolicyState).Should(BeNil())
 			Expect(err).Should(HaveOccurred())
 		})
+		It("should not return an error when creating an upgrade policy", func() {
+			mockServer.SetHandler(0, CombineHandlers(
+				VerifyRequest("POST", fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s/upgrade_policies", clusterID)),
+				RespondWith(
+					http.StatusCreated,
+					fmt.Sprintf(`{"kind": "UpgradePolicy", "id": "%s", "schedule_type": "manual", "version": "4.14.5"}`, upgradePolicyID),
+					http.Header{"Content-Type": []string{"application/json"}},
+				),
+			))
+			policy, _ := cmv1.NewUpgradePolicy().ScheduleType(cmv1.ScheduleTypeManual).Version("4.14.5").Build()
+			createdPolicy, _, err := ocmClient.CreateUpgradePolicy(clusterID, policy)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(createdPolicy.ID()).To(Equal(upgradePolicyID))
+		})
+		It("should not return an error when updating an upgrade policy", func() {
+			mockServer.SetHandler(0, CombineHandlers(
+				VerifyRequest("PATCH", fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s/upgrade_policies/%s", clusterID, upgradePolicyID)),
+				RespondWith(
+					http.StatusOK,
+					fmt.Sprintf(`{"kind": "UpgradePolicy", "id": "%s", "schedule": "0 2 * * 1"}`, upgradePolicyID),
+					http.Header{"Content-Type": []string{"application/json"}},
+				),
+			))
+			policy, _ := cmv1.NewUpgradePolicy().Schedule("0 2 * * 1").Build()
+			updatedPolicy, _, err := ocmClient.UpdateUpgradePolicy(clusterID, upgradePolicyID, policy)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(updatedPolicy.Schedule()).To(Equal("0 2 * * 1"))
+		})
+		It("should not return an error when deleting an upgrade policy", func() {
+			mockServer.SetHandler(0, CombineHandlers(
+				VerifyRequest("DELETE", fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s/upgrade_policies/%s", clusterID, upgradePolicyID)),
+				RespondWith(http.StatusNoContent, nil),
+			))
+			_, err := ocmClient.DeleteUpgradePolicy(clusterID, upgradePolicyID)
+			Expect(err).ShouldNot(HaveOccurred())
+		})
+		It("should return an error when deleting an upgrade policy that doesn't exist", func() {
+			mockServer.SetHandler(0, CombineHandlers(
+				VerifyRequest("DELETE", fmt.Sprintf("/api/clusters_mgmt/v1/clusters/%s/upgrade_policies/%s", clusterID, upgradePolicyID)),
+				RespondWith(
+					http.StatusNotFound,
+					`{"kind": "Error", "reason": "Not found"}`,
+					http.Header{"Content-Type": []string{"application/json"}},
+				),
+			))
+			_, err := ocmClient.DeleteUpgradePolicy(clusterID, upgradePolicyID)
+			Expect(err).Should(HaveOccurred())
+		})
 	})
 
 	Context("Posting a service log", func() {
@@ -629,7 +752,7 @@ var _ = Describe("OCM client Handler", func() {
 					RespondWith(http.StatusInternalServerError, `{"kind": "Error", "reason": "Internal server error"}`, http.Header{"Content-Type": []string{"application/json"}}),
 				),
 			)
-			upgradePolicies, _, err := ocmClient.GetUpgradePolicies(clusterID)
+			upgradePolicies, _, err := ocmClient.GetUpgradePolicies(clusterID, ListOptions{})
 			Expect(err).Should(HaveOccurred())
 			Expect(upgradePolicies).Should(BeNil())
 			Expect(err.Error()).Should(ContainSubstring("500"))
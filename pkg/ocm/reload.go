@@ -0,0 +1,239 @@
+package ocm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	slv1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+
+	"github.com/openshift/ocm-agent/pkg/metrics"
+)
+
+const (
+	// Time between the checks of the credential files for changes
+	DefaultCredentialsPollInterval = 30 * time.Second
+	// Time a replaced connection is kept open for the calls still using it, including their retries
+	DefaultConnectionCloseDelay = 2 * time.Minute
+)
+
+// ConnectionReloader holds the connection to OCM and replaces it with a new one when its credentials change,
+// e.g. when the pull secret or the OCM service account is rotated.
+type ConnectionReloader struct {
+	build       func() (*sdk.Connection, error)
+	credentials func() ([]byte, error)
+	interval    time.Duration
+	closeDelay  time.Duration
+	environment string
+
+	connection atomic.Pointer[sdk.Connection]
+	mutex      sync.Mutex
+	checksum   string
+}
+
+// NewConnectionReloader builds the initial connection with build, which reads the credentials from files.
+// Without files, the credentials never change.
+func NewConnectionReloader(build func() (*sdk.Connection, error), files ...string) (*ConnectionReloader, error) {
+	if len(files) == 0 {
+		return NewCredentialsConnectionReloader(build, nil)
+	}
+	return NewCredentialsConnectionReloader(build, func() ([]byte, error) {
+		var contents []byte
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("can't read the OCM credentials from '%s': %w", file, err)
+			}
+			contents = append(append(contents, data...), 0)
+		}
+		return contents, nil
+	})
+}
+
+// NewCredentialsConnectionReloader builds the initial connection with build, which is called again once the
+// credentials returned by credentials change. With nil credentials, the connection is never reloaded.
+func NewCredentialsConnectionReloader(build func() (*sdk.Connection, error), credentials func() ([]byte, error)) (*ConnectionReloader, error) {
+	r := &ConnectionReloader{
+		build:       build,
+		credentials: credentials,
+		interval:    DefaultCredentialsPollInterval,
+		closeDelay:  DefaultConnectionCloseDelay,
+	}
+	checksum, err := r.credentialsChecksum()
+	if err != nil {
+		return nil, err
+	}
+	connection, err := build()
+	if err != nil {
+		return nil, err
+	}
+	r.checksum = checksum
+	r.connection.Store(connection)
+	return r, nil
+}
+
+// WithEnvironment sets the name of the OCM environment the reloads of the connection are counted for.
+func (r *ConnectionReloader) WithEnvironment(environment string) *ConnectionReloader {
+	r.environment = environment
+	return r
+}
+
+// Connection returns the current connection to OCM.
+func (r *ConnectionReloader) Connection() *sdk.Connection {
+	return r.connection.Load()
+}
+
+// Watch checks the credentials for changes until the context is done.
+// It returns right away when the credentials never change.
+func (r *ConnectionReloader) Watch(ctx context.Context) {
+	if r.credentials == nil {
+		return
+	}
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.Reload(); err != nil {
+				packageLogger.WithError(err).WithField("Environment", r.environment).Error("Can't reload the OCM connection with the changed credentials")
+			}
+		}
+	}
+}
+
+// Reload replaces the connection if the credentials changed since it was built, and returns whether it did.
+// On failure, the current connection is kept and the reload is attempted again on the next call.
+func (r *ConnectionReloader) Reload() (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	checksum, err := r.credentialsChecksum()
+	if err != nil {
+		metrics.CountOCMCredentialsReload(r.environment, false)
+		return false, err
+	}
+	if checksum == r.checksum {
+		return false, nil
+	}
+	connection, err := r.build()
+	if err != nil {
+		metrics.CountOCMCredentialsReload(r.environment, false)
+		return false, err
+	}
+	r.checksum = checksum
+
+	// Calls which already got the previous connection complete with it before it is closed
+	previous := r.connection.Swap(connection)
+	time.AfterFunc(r.closeDelay, func() {
+		if err := previous.Close(); err != nil {
+			packageLogger.WithError(err).Warn("Can't close the replaced OCM connection")
+		}
+	})
+	metrics.CountOCMCredentialsReload(r.environment, true)
+	packageLogger.WithField("Environment", r.environment).Info("OCM connection reloaded with the changed credentials")
+	return true, nil
+}
+
+func (r *ConnectionReloader) credentialsChecksum() (string, error) {
+	if r.credentials == nil {
+		return "", nil
+	}
+	credentials, err := r.credentials()
+	if err != nil {
+		return "", err
+	}
+	checksum := sha256.Sum256(credentials)
+	return hex.EncodeToString(checksum[:]), nil
+}
+
+// reloadingOCMClient makes every call with the current connection of a ConnectionReloader.
+type reloadingOCMClient struct {
+	reloader *ConnectionReloader
+}
+
+// NewReloadingOcmClient returns an OCMClient using the current connection of the reloader for each call.
+// A client bound to a context with WithContext keeps the connection which was current at that time.
+func NewReloadingOcmClient(reloader *ConnectionReloader) OCMClient {
+	return &reloadingOCMClient{reloader: reloader}
+}
+
+func (r *reloadingOCMClient) current() OCMClient {
+	return NewOcmClient(r.reloader.Connection())
+}
+
+func (r *reloadingOCMClient) withContext(ctx context.Context) OCMClient {
+	return WithContext(r.current(), ctx)
+}
+
+func (r *reloadingOCMClient) SendServiceLog(logEntry *slv1.LogEntry) error {
+	return r.current().SendServiceLog(logEntry)
+}
+
+func (r *reloadingOCMClient) sendServiceLog(logEntry *slv1.LogEntry) (string, error) {
+	return r.current().(operationIDClient).sendServiceLog(logEntry)
+}
+
+func (r *reloadingOCMClient) SendLimitedSupport(clusterUUID string, lsReason *cmv1.LimitedSupportReason) error {
+	return r.current().SendLimitedSupport(clusterUUID, lsReason)
+}
+
+func (r *reloadingOCMClient) sendLimitedSupport(clusterUUID string, lsReason *cmv1.LimitedSupportReason) (string, error) {
+	return r.current().(operationIDClient).sendLimitedSupport(clusterUUID, lsReason)
+}
+
+func (r *reloadingOCMClient) RemoveLimitedSupport(clusterUUID string, lsReasonID string) error {
+	return r.current().RemoveLimitedSupport(clusterUUID, lsReasonID)
+}
+
+func (r *reloadingOCMClient) removeLimitedSupport(clusterUUID string, lsReasonID string) (string, error) {
+	return r.current().(operationIDClient).removeLimitedSupport(clusterUUID, lsReasonID)
+}
+
+func (r *reloadingOCMClient) GetLimitedSupportReasons(clusterUUID string) ([]*cmv1.LimitedSupportReason, error) {
+	return r.current().GetLimitedSupportReasons(clusterUUID)
+}
+
+func (r *reloadingOCMClient) GetCluster(clusterID string) (*cmv1.Cluster, string, error) {
+	return r.current().GetCluster(clusterID)
+}
+
+func (r *reloadingOCMClient) GetUpgradePolicyState(clusterID string, upgradePolicyID string) (*cmv1.UpgradePolicyState, string, error) {
+	return r.current().GetUpgradePolicyState(clusterID, upgradePolicyID)
+}
+
+func (r *reloadingOCMClient) GetUpgradePolicy(clusterID string, upgradePolicyID string) (*cmv1.UpgradePolicy, string, error) {
+	return r.current().GetUpgradePolicy(clusterID, upgradePolicyID)
+}
+
+func (r *reloadingOCMClient) GetUpgradePolicies(clusterID string, opts ListOptions) ([]*cmv1.UpgradePolicy, string, error) {
+	return r.current().GetUpgradePolicies(clusterID, opts)
+}
+
+func (r *reloadingOCMClient) GetServiceLogs(clusterUUID string, opts ListOptions) ([]*slv1.LogEntry, string, error) {
+	return r.current().GetServiceLogs(clusterUUID, opts)
+}
+
+func (r *reloadingOCMClient) UpdateUpgradePolicyState(clusterID string, upgradePolicyID string, policyState *cmv1.UpgradePolicyState) (*cmv1.UpgradePolicyState, string, error) {
+	return r.current().UpdateUpgradePolicyState(clusterID, upgradePolicyID, policyState)
+}
+
+func (r *reloadingOCMClient) CreateUpgradePolicy(clusterID string, policy *cmv1.UpgradePolicy) (*cmv1.UpgradePolicy, string, error) {
+	return r.current().CreateUpgradePolicy(clusterID, policy)
+}
+
+func (r *reloadingOCMClient) UpdateUpgradePolicy(clusterID string, upgradePolicyID string, policy *cmv1.UpgradePolicy) (*cmv1.UpgradePolicy, string, error) {
+	return r.current().UpdateUpgradePolicy(clusterID, upgradePolicyID, policy)
+}
+
+func (r *reloadingOCMClient) DeleteUpgradePolicy(clusterID string, upgradePolicyID string) (string, error) {
+	return r.current().DeleteUpgradePolicy(clusterID, upgradePolicyID)
+}
@@ -0,0 +1,105 @@
+package ocm
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/ghttp"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	. "github.com/openshift-online/ocm-sdk-go/testing"
+)
+
+var _ = Describe("OCM connection reloader", func() {
+	const (
+		clusterID       = "bd845de4-5c16-4067-a868-15b02d55ccef"
+		firstToken      = "Zmlyc3QtdG9rZW4="
+		rotatedToken    = "cm90YXRlZC10b2tlbg=="
+		clusterResponse = `{"kind": "Cluster", "id": "internal-id"}`
+	)
+
+	var (
+		mockServer *Server
+		tokenFile  string
+		buildErr   error
+		reloader   *ConnectionReloader
+	)
+
+	build := func() (*sdk.Connection, error) {
+		if buildErr != nil {
+			return nil, buildErr
+		}
+		token, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return nil, err
+		}
+		return NewConnection().Build(mockServer.URL(), clusterID, string(token))
+	}
+
+	BeforeEach(func() {
+		mockServer = NewServer()
+		tokenFile = filepath.Join(GinkgoT().TempDir(), "token")
+		Expect(os.WriteFile(tokenFile, []byte(firstToken), 0600)).To(Succeed())
+		buildErr = nil
+
+		var err error
+		reloader, err = NewConnectionReloader(build, tokenFile)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		mockServer.Close()
+	})
+
+	It("keeps the connection while the credentials don't change", func() {
+		connection := reloader.Connection()
+
+		Expect(reloader.Reload()).To(BeFalse())
+		Expect(reloader.Connection()).To(BeIdenticalTo(connection))
+	})
+
+	It("makes the calls with the new credentials once they changed", func() {
+		mockServer.AppendHandlers(
+			CombineHandlers(
+				VerifyHeaderKV("Authorization", "AccessToken "+clusterID+":"+firstToken),
+				RespondWithJSON(http.StatusOK, clusterResponse),
+			),
+			CombineHandlers(
+				VerifyHeaderKV("Authorization", "AccessToken "+clusterID+":"+rotatedToken),
+				RespondWithJSON(http.StatusOK, clusterResponse),
+			),
+		)
+		client := NewReloadingOcmClient(reloader)
+		_, _, err := client.GetCluster("internal-id")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(os.WriteFile(tokenFile, []byte(rotatedToken), 0600)).To(Succeed())
+		Expect(reloader.Reload()).To(BeTrue())
+
+		_, _, err = client.GetCluster("internal-id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mockServer.ReceivedRequests()).To(HaveLen(2))
+	})
+
+	It("keeps the current connection when the new one can't be built", func() {
+		connection := reloader.Connection()
+		Expect(os.WriteFile(tokenFile, []byte(rotatedToken), 0600)).To(Succeed())
+		buildErr = errors.New("invalid credentials")
+
+		_, err := reloader.Reload()
+		Expect(err).To(HaveOccurred())
+		Expect(reloader.Connection()).To(BeIdenticalTo(connection))
+
+		// The reload is attempted again once the connection can be built
+		buildErr = nil
+		Expect(reloader.Reload()).To(BeTrue())
+	})
+
+	It("fails when a credential file can't be read", func() {
+		_, err := NewConnectionReloader(build, filepath.Join(GinkgoT().TempDir(), "missing"))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,186 @@
+package ocm
+
+import (
+	"context"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	slv1 "github.com/openshift-online/ocm-sdk-go/servicelogs/v1"
+	"github.com/openshift/ocm-agent/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// Attributes of the spans of OCM calls
+	TraceAttributeOperation   = "ocm.operation"
+	TraceAttributeClusterID   = "ocm.cluster_id"
+	TraceAttributeOperationID = "ocm.operation_id"
+)
+
+// tracingOCMClient wraps an OCMClient and creates a client span for every call.
+type tracingOCMClient struct {
+	OCMClient
+	ctx context.Context
+}
+
+// NewTracingOcmClient returns an OCMClient tracing the calls of the given client.
+// The spans are children of the span in the context set with WithContext, if any.
+func NewTracingOcmClient(o OCMClient) OCMClient {
+	return &tracingOCMClient{
+		OCMClient: o,
+		ctx:       context.Background(),
+	}
+}
+
+func (t *tracingOCMClient) withContext(ctx context.Context) OCMClient {
+	withContext := *t
+	withContext.ctx = ctx
+	withContext.OCMClient = WithContext(t.OCMClient, ctx)
+	return &withContext
+}
+
+// trace runs call within a span for operation and records the OCM operation ID and error of the call.
+// call is given the wrapped client bound to the context of the span, so that its own spans are children of it.
+func (t *tracingOCMClient) trace(operation, clusterID string, call func(o OCMClient) (string, error)) (string, error) {
+	ctx, span := tracing.StartSpan(t.ctx, "OCM "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String(TraceAttributeOperation, operation),
+			attribute.String(TraceAttributeClusterID, clusterID),
+		))
+	operationID, err := call(WithContext(t.OCMClient, ctx))
+	if operationID != "" {
+		span.SetAttributes(attribute.String(TraceAttributeOperationID, operationID))
+	}
+	tracing.EndSpan(span, err)
+	return operationID, err
+}
+
+func (t *tracingOCMClient) SendServiceLog(logEntry *slv1.LogEntry) error {
+	_, err := t.sendServiceLog(logEntry)
+	return err
+}
+
+func (t *tracingOCMClient) sendServiceLog(logEntry *slv1.LogEntry) (string, error) {
+	return t.trace("SendServiceLog", logEntry.ClusterUUID(), func(o OCMClient) (string, error) {
+		if c, ok := o.(operationIDClient); ok {
+			return c.sendServiceLog(logEntry)
+		}
+		return "", o.SendServiceLog(logEntry)
+	})
+}
+
+func (t *tracingOCMClient) SendLimitedSupport(clusterUUID string, lsReason *cmv1.LimitedSupportReason) error {
+	_, err := t.sendLimitedSupport(clusterUUID, lsReason)
+	return err
+}
+
+func (t *tracingOCMClient) sendLimitedSupport(clusterUUID string, lsReason *cmv1.LimitedSupportReason) (string, error) {
+	return t.trace("SendLimitedSupport", clusterUUID, func(o OCMClient) (string, error) {
+		if c, ok := o.(operationIDClient); ok {
+			return c.sendLimitedSupport(clusterUUID, lsReason)
+		}
+		return "", o.SendLimitedSupport(clusterUUID, lsReason)
+	})
+}
+
+func (t *tracingOCMClient) RemoveLimitedSupport(clusterUUID string, lsReasonID string) error {
+	_, err := t.removeLimitedSupport(clusterUUID, lsReasonID)
+	return err
+}
+
+func (t *tracingOCMClient) removeLimitedSupport(clusterUUID string, lsReasonID string) (string, error) {
+	return t.trace("RemoveLimitedSupport", clusterUUID, func(o OCMClient) (string, error) {
+		if c, ok := o.(operationIDClient); ok {
+			return c.removeLimitedSupport(clusterUUID, lsReasonID)
+		}
+		return "", o.RemoveLimitedSupport(clusterUUID, lsReasonID)
+	})
+}
+
+func (t *tracingOCMClient) GetLimitedSupportReasons(clusterUUID string) ([]*cmv1.LimitedSupportReason, error) {
+	var reasons []*cmv1.LimitedSupportReason
+	_, err := t.trace("GetLimitedSupportReasons", clusterUUID, func(o OCMClient) (_ string, err error) {
+		reasons, err = o.GetLimitedSupportReasons(clusterUUID)
+		return "", err
+	})
+	return reasons, err
+}
+
+func (t *tracingOCMClient) GetCluster(clusterID string) (*cmv1.Cluster, string, error) {
+	var cluster *cmv1.Cluster
+	operationID, err := t.trace("GetCluster", clusterID, func(o OCMClient) (operationID string, err error) {
+		cluster, operationID, err = o.GetCluster(clusterID)
+		return operationID, err
+	})
+	return cluster, operationID, err
+}
+
+func (t *tracingOCMClient) GetUpgradePolicyState(clusterID string, upgradePolicyID string) (*cmv1.UpgradePolicyState, string, error) {
+	var policyState *cmv1.UpgradePolicyState
+	operationID, err := t.trace("GetUpgradePolicyState", clusterID, func(o OCMClient) (operationID string, err error) {
+		policyState, operationID, err = o.GetUpgradePolicyState(clusterID, upgradePolicyID)
+		return operationID, err
+	})
+	return policyState, operationID, err
+}
+
+func (t *tracingOCMClient) GetUpgradePolicy(clusterID string, upgradePolicyID string) (*cmv1.UpgradePolicy, string, error) {
+	var policy *cmv1.UpgradePolicy
+	operationID, err := t.trace("GetUpgradePolicy", clusterID, func(o OCMClient) (operationID string, err error) {
+		policy, operationID, err = o.GetUpgradePolicy(clusterID, upgradePolicyID)
+		return operationID, err
+	})
+	return policy, operationID, err
+}
+
+func (t *tracingOCMClient) GetUpgradePolicies(clusterID string, opts ListOptions) ([]*cmv1.UpgradePolicy, string, error) {
+	var policies []*cmv1.UpgradePolicy
+	operationID, err := t.trace("GetUpgradePolicies", clusterID, func(o OCMClient) (operationID string, err error) {
+		policies, operationID, err = o.GetUpgradePolicies(clusterID, opts)
+		return operationID, err
+	})
+	return policies, operationID, err
+}
+
+func (t *tracingOCMClient) GetServiceLogs(clusterUUID string, opts ListOptions) ([]*slv1.LogEntry, string, error) {
+	var logEntries []*slv1.LogEntry
+	operationID, err := t.trace("GetServiceLogs", clusterUUID, func(o OCMClient) (operationID string, err error) {
+		logEntries, operationID, err = o.GetServiceLogs(clusterUUID, opts)
+		return operationID, err
+	})
+	return logEntries, operationID, err
+}
+
+func (t *tracingOCMClient) UpdateUpgradePolicyState(clusterID string, upgradePolicyID string, policyState *cmv1.UpgradePolicyState) (*cmv1.UpgradePolicyState, string, error) {
+	var updatedState *cmv1.UpgradePolicyState
+	operationID, err := t.trace("UpdateUpgradePolicyState", clusterID, func(o OCMClient) (operationID string, err error) {
+		updatedState, operationID, err = o.UpdateUpgradePolicyState(clusterID, upgradePolicyID, policyState)
+		return operationID, err
+	})
+	return updatedState, operationID, err
+}
+
+func (t *tracingOCMClient) CreateUpgradePolicy(clusterID string, policy *cmv1.UpgradePolicy) (*cmv1.UpgradePolicy, string, error) {
+	var createdPolicy *cmv1.UpgradePolicy
+	operationID, err := t.trace("CreateUpgradePolicy", clusterID, func(o OCMClient) (operationID string, err error) {
+		createdPolicy, operationID, err = o.CreateUpgradePolicy(clusterID, policy)
+		return operationID, err
+	})
+	return createdPolicy, operationID, err
+}
+
+func (t *tracingOCMClient) UpdateUpgradePolicy(clusterID string, upgradePolicyID string, policy *cmv1.UpgradePolicy) (*cmv1.UpgradePolicy, string, error) {
+	var updatedPolicy *cmv1.UpgradePolicy
+	operationID, err := t.trace("UpdateUpgradePolicy", clusterID, func(o OCMClient) (operationID string, err error) {
+		updatedPolicy, operationID, err = o.UpdateUpgradePolicy(clusterID, upgradePolicyID, policy)
+		return operationID, err
+	})
+	return updatedPolicy, operationID, err
+}
+
+func (t *tracingOCMClient) DeleteUpgradePolicy(clusterID string, upgradePolicyID string) (string, error) {
+	return t.trace("DeleteUpgradePolicy", clusterID, func(o OCMClient) (string, error) {
+		return o.DeleteUpgradePolicy(clusterID, upgradePolicyID)
+	})
+}
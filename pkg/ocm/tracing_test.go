@@ -0,0 +1,87 @@
+package ocm
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ = Describe("Tracing OCM client", func() {
+	var (
+		recorder    *tracetest.SpanRecorder
+		policyState *cmv1.UpgradePolicyState
+	)
+
+	BeforeEach(func() {
+		recorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+		policyState, _ = cmv1.NewUpgradePolicyState().Value(cmv1.UpgradePolicyStateValueCancelled).Build()
+	})
+
+	It("creates a client span with the OCM operation ID as a child of the trace context", func() {
+		ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+		client := WithContext(NewTracingOcmClient(&fakeWriteOCMClient{}), ctx)
+
+		_, _, err := client.UpdateUpgradePolicyState("cluster-id", "policy-id", policyState)
+		Expect(err).ToNot(HaveOccurred())
+		parent.End()
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(2))
+		span := spans[0]
+		Expect(span.Name()).To(Equal("OCM UpdateUpgradePolicyState"))
+		Expect(span.SpanKind()).To(Equal(trace.SpanKindClient))
+		Expect(span.Parent().SpanID()).To(Equal(parent.SpanContext().SpanID()))
+		Expect(span.Attributes()).To(ContainElements(
+			attribute.String(TraceAttributeClusterID, "cluster-id"),
+			attribute.String(TraceAttributeOperationID, "op-update"),
+		))
+	})
+
+	It("makes the calls of the wrapped client with the context of its span", func() {
+		ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+		client := WithContext(NewTracingOcmClient(NewTracingOcmClient(&fakeWriteOCMClient{})), ctx)
+
+		_, _, err := client.UpdateUpgradePolicyState("cluster-id", "policy-id", policyState)
+		Expect(err).ToNot(HaveOccurred())
+		parent.End()
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(3))
+		inner, outer := spans[0], spans[1]
+		Expect(outer.Parent().SpanID()).To(Equal(parent.SpanContext().SpanID()))
+		Expect(inner.Parent().SpanID()).To(Equal(outer.SpanContext().SpanID()))
+	})
+
+	It("marks the span of failed calls as failed", func() {
+		client := NewTracingOcmClient(&fakeWriteOCMClient{err: errors.New("unexpected status: 500")})
+
+		_, _, err := client.UpdateUpgradePolicyState("cluster-id", "policy-id", policyState)
+		Expect(err).To(MatchError("unexpected status: 500"))
+
+		Expect(recorder.Ended()).To(HaveLen(1))
+		Expect(recorder.Ended()[0].Status().Code).To(Equal(codes.Error))
+		Expect(recorder.Ended()[0].Attributes()).To(ContainElement(attribute.String(TraceAttributeOperationID, "op-failed")))
+	})
+
+	It("sets the audit context of the audited client it wraps", func() {
+		sink := &recordingAuditSink{}
+		client := WithAuditContext(NewTracingOcmClient(NewAuditingOcmClient(&fakeWriteOCMClient{}, sink)), AuditContext{Caller: "someone"})
+
+		_, _, err := client.UpdateUpgradePolicyState("cluster-id", "policy-id", policyState)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(sink.records).To(HaveLen(1))
+		Expect(sink.records[0].Caller).To(Equal("someone"))
+		Expect(recorder.Ended()).To(HaveLen(1))
+	})
+})
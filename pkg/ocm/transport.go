@@ -0,0 +1,85 @@
+package ocm
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// TransportOptions configure the egress proxy the requests to OCM are sent through and the CAs trusted in
+// addition to the system ones, e.g. those of a TLS-intercepting proxy.
+type TransportOptions struct {
+	// ProxyURL is the proxy of the HTTP and HTTPS requests, the HTTPS_PROXY and HTTP_PROXY environment variables
+	// are used when empty
+	ProxyURL string
+	// NoProxy is the comma-separated list of hosts, domains and CIDRs which are reached without the proxy, the
+	// NO_PROXY environment variable is used when empty
+	NoProxy string
+	// CABundleFile is the PEM file of the additional trusted CAs
+	CABundleFile string
+}
+
+// Validate checks that the proxy URL can be used.
+func (o TransportOptions) Validate() error {
+	if o.ProxyURL == "" {
+		return nil
+	}
+	proxyURL, err := url.Parse(o.ProxyURL)
+	if err != nil {
+		return fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("invalid proxy URL '%s': the scheme must be http, https or socks5", proxyURL.Redacted())
+	}
+	if proxyURL.Host == "" {
+		return fmt.Errorf("invalid proxy URL '%s': missing host", proxyURL.Redacted())
+	}
+	return nil
+}
+
+// RedactedProxyURL returns the proxy URL with its password redacted, for logging.
+func (o TransportOptions) RedactedProxyURL() string {
+	proxyURL, err := url.Parse(o.ProxyURL)
+	if err != nil {
+		return ""
+	}
+	return proxyURL.Redacted()
+}
+
+// Proxy returns the proxy function of the transports, which takes the environment into account for the
+// options which are not set.
+func (o TransportOptions) Proxy() func(*http.Request) (*url.URL, error) {
+	config := httpproxy.FromEnvironment()
+	if o.ProxyURL != "" {
+		config.HTTPProxy = o.ProxyURL
+		config.HTTPSProxy = o.ProxyURL
+	}
+	if o.NoProxy != "" {
+		config.NoProxy = o.NoProxy
+	}
+	proxy := config.ProxyFunc()
+	return func(request *http.Request) (*url.URL, error) {
+		return proxy(request.URL)
+	}
+}
+
+// Apply configures the SDK connection builder with the options. It must be called after the other transport
+// wrappers are set, so that the proxy is set on the transport of the SDK rather than on a wrapper.
+func (o TransportOptions) Apply(builder *sdk.ConnectionBuilder) *sdk.ConnectionBuilder {
+	if o.CABundleFile != "" {
+		// The SDK trusts the CAs of the file in addition to the system ones
+		builder.TrustedCAFile(o.CABundleFile)
+	}
+	proxy := o.Proxy()
+	return builder.TransportWrapper(func(transport http.RoundTripper) http.RoundTripper {
+		if httpTransport, ok := transport.(*http.Transport); ok {
+			httpTransport.Proxy = proxy
+		}
+		return transport
+	})
+}
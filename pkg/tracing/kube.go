@@ -0,0 +1,69 @@
+package tracing
+
+import (
+	"context"
+	"reflect"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// kubeClient wraps a client.Client and traces its reads and status updates.
+type kubeClient struct {
+	client.Client
+}
+
+// NewKubeClient returns a client.Client creating a span for every Get, List and Status().Update call of c.
+func NewKubeClient(c client.Client) client.Client {
+	return &kubeClient{Client: c}
+}
+
+// kind returns the type name of obj, such as ManagedNotification
+func kind(obj interface{}) string {
+	t := reflect.TypeOf(obj)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+func startKubeSpan(ctx context.Context, verb string, obj interface{}, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return StartSpan(ctx, "kube "+verb+" "+kind(obj),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(append(attrs, attribute.String("k8s.verb", verb), attribute.String("k8s.kind", kind(obj)))...))
+}
+
+func (c *kubeClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	ctx, span := startKubeSpan(ctx, "get", obj,
+		attribute.String("k8s.namespace.name", key.Namespace),
+		attribute.String("k8s.object.name", key.Name))
+	err := c.Client.Get(ctx, key, obj, opts...)
+	EndSpan(span, err)
+	return err
+}
+
+func (c *kubeClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	ctx, span := startKubeSpan(ctx, "list", list)
+	err := c.Client.List(ctx, list, opts...)
+	EndSpan(span, err)
+	return err
+}
+
+func (c *kubeClient) Status() client.SubResourceWriter {
+	return &kubeStatusWriter{SubResourceWriter: c.Client.Status()}
+}
+
+// kubeStatusWriter traces the status updates of a client.SubResourceWriter
+type kubeStatusWriter struct {
+	client.SubResourceWriter
+}
+
+func (w *kubeStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	ctx, span := startKubeSpan(ctx, "update status", obj,
+		attribute.String("k8s.namespace.name", obj.GetNamespace()),
+		attribute.String("k8s.object.name", obj.GetName()))
+	err := w.SubResourceWriter.Update(ctx, obj, opts...)
+	EndSpan(span, err)
+	return err
+}
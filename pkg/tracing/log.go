@@ -0,0 +1,38 @@
+package tracing
+
+import (
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// Fields added to the log entries made within a span
+	LogFieldTraceID = "trace_id"
+	LogFieldSpanID  = "span_id"
+)
+
+// LogHook adds the IDs of the span in the context of log entries to their fields,
+// for entries created with logrus.WithContext.
+type LogHook struct{}
+
+// NewLogHook returns a LogHook.
+func NewLogHook() *LogHook {
+	return &LogHook{}
+}
+
+func (h *LogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *LogHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	spanContext := trace.SpanContextFromContext(entry.Context)
+	if !spanContext.IsValid() {
+		return nil
+	}
+	entry.Data[LogFieldTraceID] = spanContext.TraceID().String()
+	entry.Data[LogFieldSpanID] = spanContext.SpanID().String()
+	return nil
+}
@@ -0,0 +1,126 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// TracerName is the name of the tracer creating the spans of the agent
+	TracerName = "github.com/openshift/ocm-agent"
+	// ServiceName is the name of the service the spans are reported for
+	ServiceName = "ocm-agent"
+
+	// Exporters the spans can be sent with
+	ExporterNone     = "none"
+	ExporterOTLPGRPC = "otlp-grpc"
+	ExporterOTLPHTTP = "otlp-http"
+	ExporterStdout   = "stdout"
+)
+
+// Options configure the export of the spans.
+type Options struct {
+	// Exporter is one of ExporterNone, ExporterOTLPGRPC, ExporterOTLPHTTP or ExporterStdout
+	Exporter string
+	// Endpoint is the host:port of the OTLP collector, the OTEL_EXPORTER_OTLP_* environment variables are used if empty
+	Endpoint string
+	// Insecure disables TLS towards the OTLP collector
+	Insecure bool
+	// SampleRatio is the ratio of the traces started by the agent which are sampled
+	SampleRatio float64
+}
+
+// NewTracerProvider creates the tracer provider exporting spans as configured by opts and registers it,
+// along with the W3C trace context propagator, as the global one.
+// Nil is returned without error when tracing is disabled.
+func NewTracerProvider(ctx context.Context, opts Options) (*sdktrace.TracerProvider, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch opts.Exporter {
+	case "", ExporterNone:
+		return nil, nil
+	case ExporterOTLPGRPC:
+		var grpcOpts []otlptracegrpc.Option
+		if opts.Endpoint != "" {
+			grpcOpts = append(grpcOpts, otlptracegrpc.WithEndpoint(opts.Endpoint))
+		}
+		if opts.Insecure {
+			grpcOpts = append(grpcOpts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, grpcOpts...)
+	case ExporterOTLPHTTP:
+		var httpOpts []otlptracehttp.Option
+		if opts.Endpoint != "" {
+			httpOpts = append(httpOpts, otlptracehttp.WithEndpoint(opts.Endpoint))
+		}
+		if opts.Insecure {
+			httpOpts = append(httpOpts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, httpOpts...)
+	case ExporterStdout:
+		// stdout is used by the audit log, write the spans to stderr along with the logs
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stderr))
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q, must be one of %s, %s, %s or %s",
+			opts.Exporter, ExporterNone, ExporterOTLPGRPC, ExporterOTLPHTTP, ExporterStdout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to create %s tracing exporter: %w", opts.Exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attribute.String("service.name", ServiceName)))
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return tp, nil
+}
+
+// StartSpan starts a span of the agent as a child of the span in ctx, if any.
+func StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(TracerName).Start(ctx, name, opts...)
+}
+
+// EndSpan ends span, marking it as failed when err isn't nil.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Retry runs fn with the retry function, such as retry.RetryOnConflict, within a span named name
+// recording the number of attempts it took. fn is given the context of the span to make its calls with.
+func Retry(ctx context.Context, name string, retry func(fn func() error) error, fn func(ctx context.Context) error) error {
+	ctx, span := StartSpan(ctx, name)
+	attempts := 0
+	err := retry(func() error {
+		attempts++
+		return fn(ctx)
+	})
+	span.SetAttributes(attribute.Int("retry.attempts", attempts))
+	EndSpan(span, err)
+	return err
+}
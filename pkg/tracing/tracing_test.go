@@ -0,0 +1,179 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clientmocks "github.com/openshift/ocm-agent/pkg/util/test/generated/mocks/client"
+)
+
+var _ = Describe("Tracing", func() {
+	var (
+		recorder *tracetest.SpanRecorder
+	)
+
+	BeforeEach(func() {
+		recorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	})
+
+	Context("NewTracerProvider", func() {
+		It("doesn't create a provider when tracing is disabled", func() {
+			tp, err := NewTracerProvider(context.Background(), Options{Exporter: ExporterNone})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tp).To(BeNil())
+		})
+
+		It("fails on unknown exporters", func() {
+			_, err := NewTracerProvider(context.Background(), Options{Exporter: "zipkin"})
+			Expect(err).To(MatchError(ContainSubstring(`unknown tracing exporter "zipkin"`)))
+		})
+	})
+
+	Context("NewHandler", func() {
+		It("runs the handler within a server span continuing the propagated trace", func() {
+			handler := NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, span := StartSpan(r.Context(), "child")
+				span.End()
+				w.WriteHeader(http.StatusInternalServerError)
+			}), "/alertmanager-receiver")
+
+			req := httptest.NewRequest(http.MethodPost, "/alertmanager-receiver", nil)
+			req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := recorder.Ended()
+			Expect(spans).To(HaveLen(2))
+			child, server := spans[0], spans[1]
+			Expect(server.Name()).To(Equal("POST /alertmanager-receiver"))
+			Expect(server.SpanContext().TraceID().String()).To(Equal("4bf92f3577b34da6a3ce929d0e0e4736"))
+			Expect(server.Parent().SpanID().String()).To(Equal("00f067aa0ba902b7"))
+			Expect(server.Attributes()).To(ContainElement(attribute.Int("http.response.status_code", http.StatusInternalServerError)))
+			Expect(server.Status().Code).To(Equal(codes.Error))
+			Expect(child.Parent().SpanID()).To(Equal(server.SpanContext().SpanID()))
+		})
+	})
+
+	Context("LogHook", func() {
+		It("adds the trace and span IDs to entries logged with a span context", func() {
+			var out bytes.Buffer
+			logger := logrus.New()
+			logger.SetOutput(&out)
+			logger.SetFormatter(&logrus.JSONFormatter{})
+			logger.AddHook(NewLogHook())
+
+			ctx, span := StartSpan(context.Background(), "test")
+			defer span.End()
+			logger.WithContext(ctx).Info("within span")
+			Expect(out.String()).To(ContainSubstring(`"trace_id":"` + span.SpanContext().TraceID().String() + `"`))
+			Expect(out.String()).To(ContainSubstring(`"span_id":"` + span.SpanContext().SpanID().String() + `"`))
+
+			out.Reset()
+			logger.Info("without span")
+			Expect(out.String()).ToNot(ContainSubstring("trace_id"))
+		})
+	})
+
+	Context("Retry", func() {
+		It("records the number of attempts", func() {
+			attempts := 0
+			err := Retry(context.Background(), "retry", func(fn func() error) error {
+				for i := 0; i < 3; i++ {
+					if err := fn(); err == nil {
+						return nil
+					}
+				}
+				return errors.New("exhausted")
+			}, func(ctx context.Context) error {
+				attempts++
+				if attempts < 2 {
+					return errors.New("conflict")
+				}
+				return nil
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(recorder.Ended()).To(HaveLen(1))
+			Expect(recorder.Ended()[0].Attributes()).To(ContainElement(attribute.Int("retry.attempts", 2)))
+		})
+
+	})
+
+	Context("NewKubeClient", func() {
+		var (
+			mockCtrl         *gomock.Controller
+			mockClient       *clientmocks.MockClient
+			mockStatusWriter *clientmocks.MockStatusWriter
+		)
+
+		BeforeEach(func() {
+			mockCtrl = gomock.NewController(GinkgoT())
+			mockClient = clientmocks.NewMockClient(mockCtrl)
+			mockStatusWriter = clientmocks.NewMockStatusWriter(mockCtrl)
+		})
+
+		It("creates a span for reads and status updates", func() {
+			configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "name"}}
+			gomock.InOrder(
+				mockClient.EXPECT().Get(gomock.Any(), client.ObjectKeyFromObject(configMap), configMap).Return(errors.New("not found")),
+				mockClient.EXPECT().Status().Return(mockStatusWriter),
+				mockStatusWriter.EXPECT().Update(gomock.Any(), configMap).Return(nil),
+			)
+
+			kubeClient := NewKubeClient(mockClient)
+			Expect(kubeClient.Get(context.Background(), client.ObjectKeyFromObject(configMap), configMap)).ToNot(Succeed())
+			Expect(kubeClient.Status().Update(context.Background(), configMap)).To(Succeed())
+
+			spans := recorder.Ended()
+			Expect(spans).To(HaveLen(2))
+			Expect(spans[0].Name()).To(Equal("kube get ConfigMap"))
+			Expect(spans[0].Status().Code).To(Equal(codes.Error))
+			Expect(spans[1].Name()).To(Equal("kube update status ConfigMap"))
+			Expect(spans[1].Attributes()).To(ContainElement(attribute.String("k8s.object.name", "name")))
+		})
+
+		It("creates the spans of the calls made by a retry as children of its span", func() {
+			configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "name"}}
+			gomock.InOrder(
+				mockClient.EXPECT().Get(gomock.Any(), client.ObjectKeyFromObject(configMap), configMap).Return(nil),
+				mockClient.EXPECT().Status().Return(mockStatusWriter),
+				mockStatusWriter.EXPECT().Update(gomock.Any(), configMap).Return(nil),
+			)
+
+			kubeClient := NewKubeClient(mockClient)
+			err := Retry(context.Background(), "retry", func(fn func() error) error {
+				return fn()
+			}, func(ctx context.Context) error {
+				if err := kubeClient.Get(ctx, client.ObjectKeyFromObject(configMap), configMap); err != nil {
+					return err
+				}
+				return kubeClient.Status().Update(ctx, configMap)
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			spans := recorder.Ended()
+			Expect(spans).To(HaveLen(3))
+			Expect(spans[2].Name()).To(Equal("retry"))
+			Expect(spans[0].Parent().SpanID()).To(Equal(spans[2].SpanContext().SpanID()))
+			Expect(spans[1].Parent().SpanID()).To(Equal(spans[2].SpanContext().SpanID()))
+		})
+	})
+})
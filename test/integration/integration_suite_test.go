@@ -0,0 +1,166 @@
+//go:build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	oav1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	"github.com/openshift/ocm-agent/pkg/config"
+	"github.com/openshift/ocm-agent/pkg/handlers"
+	"github.com/openshift/ocm-agent/pkg/ocm"
+	"github.com/openshift/ocm-agent/pkg/ocm/fake"
+)
+
+const (
+	// crdsEnv is the environment variable holding the directory of the ocm-agent-operator CRDs
+	crdsEnv = "OCM_AGENT_OPERATOR_CRDS"
+	// clusterID is the external ID of the cluster the classic receiver sends its service logs for
+	clusterID = "bd845de4-5c16-4067-a868-15b02d55ccef"
+	// namespace is the namespace the notification custom resources are created in
+	namespace = handlers.OCMAgentNamespaceName
+)
+
+var (
+	testEnv     *envtest.Environment
+	k8sClient   client.WithWatch
+	ocmServer   *fake.Server
+	ocmListener *httptest.Server
+	connection  *sdk.Connection
+	ocmClient   ocm.OCMClient
+)
+
+func TestIntegration(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Webhook Receivers Integration Suite")
+}
+
+var _ = BeforeSuite(func() {
+	crds := os.Getenv(crdsEnv)
+	Expect(crds).NotTo(BeEmpty(), "%s must be set to the directory of the ocm-agent-operator CRDs", crdsEnv)
+
+	testEnv = &envtest.Environment{
+		CRDDirectoryPaths:     []string{crds},
+		ErrorIfCRDPathMissing: true,
+	}
+	cfg, err := testEnv.Start()
+	Expect(err).NotTo(HaveOccurred())
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(oav1alpha1.AddToScheme(scheme))
+	k8sClient, err = client.NewWithWatch(cfg, client.Options{Scheme: scheme})
+	Expect(err).NotTo(HaveOccurred())
+	Expect(k8sClient.Create(context.Background(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})).To(Succeed())
+
+	ocmServer = fake.NewServer()
+	ocmListener = httptest.NewServer(ocmServer)
+	connection, err = ocm.NewConnection().
+		RetryPolicy(ocm.RetryPolicy{Limit: 2, Interval: 10 * time.Millisecond}).
+		Build(ocmListener.URL, clusterID, "dG9rZW4=")
+	Expect(err).NotTo(HaveOccurred())
+	ocmClient = ocm.NewOcmClient(connection)
+
+	viper.Set(config.ExternalClusterID, clusterID)
+})
+
+var _ = AfterSuite(func() {
+	if connection != nil {
+		connection.Close()
+	}
+	if ocmListener != nil {
+		ocmListener.Close()
+	}
+	if testEnv != nil {
+		Expect(testEnv.Stop()).To(Succeed())
+	}
+})
+
+var _ = BeforeEach(func() {
+	ocmServer.Reset()
+})
+
+// nameCounter makes the names of the specs unique, as the custom resources are kept between specs and the
+// rate limit backoffs of the fleet receiver between deliveries.
+var nameCounter atomic.Int32
+
+func uniqueName(prefix string) string {
+	return fmt.Sprintf("%s-%d", prefix, nameCounter.Add(1))
+}
+
+// alertPayload renders the Alertmanager webhook payload of a test/ template with the given values.
+func alertPayload(template string, values map[string]string) []byte {
+	data, err := os.ReadFile(filepath.Join("..", template))
+	Expect(err).NotTo(HaveOccurred())
+
+	defaults := map[string]string{
+		"SEND_MANAGED_NOTIFICATION_BOOL": "true",
+		"START_DATE":                     time.Now().UTC().Format("2006-01-02"),
+		"END_DATE":                       time.Now().UTC().Add(24 * time.Hour).Format("2006-01-02"),
+	}
+	return []byte(os.Expand(string(data), func(key string) string {
+		if value, ok := values[key]; ok {
+			return value
+		}
+		return defaults[key]
+	}))
+}
+
+// deliver posts the payload to the receiver as Alertmanager does and returns the response status.
+func deliver(receiver *httptest.Server, payload []byte) int {
+	response, err := http.Post(receiver.URL, "application/json", bytes.NewReader(payload))
+	Expect(err).NotTo(HaveOccurred())
+	defer response.Body.Close()
+	return response.StatusCode
+}
+
+// conflictingClient returns a client making the next status update of a custom resource conflict: the resource is
+// updated by another writer just before, so that the update is rejected by the API server for its stale
+// resourceVersion. The returned counter holds the number of conflicts the receiver ran into.
+func conflictingClient() (client.Client, *atomic.Int32) {
+	var injected atomic.Bool
+	conflicts := &atomic.Int32{}
+	return interceptor.NewClient(k8sClient, interceptor.Funcs{
+		SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+			if injected.CompareAndSwap(false, true) {
+				current := obj.DeepCopyObject().(client.Object)
+				Expect(c.Get(ctx, client.ObjectKeyFromObject(obj), current)).To(Succeed())
+				annotations := current.GetAnnotations()
+				if annotations == nil {
+					annotations = map[string]string{}
+				}
+				annotations["integration-test/conflict"] = time.Now().String()
+				current.SetAnnotations(annotations)
+				Expect(c.Update(ctx, current)).To(Succeed())
+			}
+			err := c.SubResource(subResourceName).Update(ctx, obj, opts...)
+			if apierrors.IsConflict(err) {
+				conflicts.Add(1)
+			}
+			return err
+		},
+	}), conflicts
+}
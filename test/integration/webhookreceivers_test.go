@@ -0,0 +1,342 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	oav1alpha1 "github.com/openshift/ocm-agent-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/ocm-agent/pkg/handlers"
+	"github.com/openshift/ocm-agent/pkg/ocm/fake"
+)
+
+const (
+	// concurrentDeliveries is the number of deliveries racing on the same custom resource. The receivers retry their
+	// status updates 5 times, and each round of conflicting updates lets at least one of them through.
+	concurrentDeliveries = 4
+
+	firing   = "firing"
+	resolved = "resolved"
+)
+
+var _ = Describe("Classic webhook receiver", func() {
+	var (
+		ctx      context.Context
+		receiver *httptest.Server
+	)
+
+	serve := func(c client.Client) {
+		receiver = httptest.NewServer(handlers.NewWebhookReceiverHandler(c, ocmClient, nil).WithNamespaces([]string{namespace}))
+		DeferCleanup(receiver.Close)
+	}
+
+	createManagedNotification := func(notificationNames ...string) *oav1alpha1.ManagedNotification {
+		managedNotification := &oav1alpha1.ManagedNotification{
+			ObjectMeta: metav1.ObjectMeta{Name: uniqueName("notifications"), Namespace: namespace},
+		}
+		for _, name := range notificationNames {
+			managedNotification.Spec.Notifications = append(managedNotification.Spec.Notifications, oav1alpha1.Notification{
+				Name:         name,
+				Summary:      "Summary of " + name,
+				ActiveDesc:   "The alert of " + name + " is firing.",
+				ResolvedDesc: "The alert of " + name + " resolved.",
+				Severity:     "Info",
+				ResendWait:   24,
+			})
+		}
+		Expect(k8sClient.Create(ctx, managedNotification)).To(Succeed())
+		return managedNotification
+	}
+
+	alert := func(status, notificationName string) []byte {
+		return alertPayload("template-alert.json", map[string]string{
+			"ALERT_STATUS":                  status,
+			"ALERT_NAME":                    "Alert" + notificationName,
+			"MANAGED_NOTIFICATION_TEMPLATE": notificationName,
+		})
+	}
+
+	notificationRecord := func(managedNotification *oav1alpha1.ManagedNotification, notificationName string) *oav1alpha1.NotificationRecord {
+		latest := &oav1alpha1.ManagedNotification{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(managedNotification), latest)).To(Succeed())
+		record, err := latest.Status.GetNotificationRecord(notificationName)
+		Expect(err).NotTo(HaveOccurred())
+		return record
+	}
+
+	conditionStatus := func(record *oav1alpha1.NotificationRecord, conditionType oav1alpha1.NotificationConditionType) corev1.ConditionStatus {
+		condition := record.Conditions.GetCondition(conditionType)
+		if condition == nil {
+			return ""
+		}
+		return condition.Status
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("sends a service log for a firing alert and records it in the status", func() {
+		serve(k8sClient)
+		notificationName := uniqueName("Firing")
+		managedNotification := createManagedNotification(notificationName)
+
+		Expect(deliver(receiver, alert(firing, notificationName))).To(Equal(http.StatusOK))
+
+		serviceLogs := ocmServer.ServiceLogs()
+		Expect(serviceLogs).To(HaveLen(1))
+		Expect(serviceLogs[0].ClusterUUID()).To(Equal(clusterID))
+		Expect(serviceLogs[0].Summary()).To(ContainSubstring("Summary of " + notificationName))
+		Expect(serviceLogs[0].Description()).To(Equal("The alert of " + notificationName + " is firing."))
+
+		record := notificationRecord(managedNotification, notificationName)
+		Expect(conditionStatus(record, oav1alpha1.ConditionAlertFiring)).To(Equal(corev1.ConditionTrue))
+		Expect(conditionStatus(record, oav1alpha1.ConditionServiceLogSent)).To(Equal(corev1.ConditionTrue))
+		Expect(record.ServiceLogSentCount).To(BeEquivalentTo(1))
+	})
+
+	It("doesn't resend within the resend window and sends the resolved service log", func() {
+		serve(k8sClient)
+		notificationName := uniqueName("Resolving")
+		managedNotification := createManagedNotification(notificationName)
+
+		Expect(deliver(receiver, alert(firing, notificationName))).To(Equal(http.StatusOK))
+		Expect(deliver(receiver, alert(firing, notificationName))).To(Equal(http.StatusOK))
+		Expect(ocmServer.ServiceLogs()).To(HaveLen(1))
+
+		Expect(deliver(receiver, alert(resolved, notificationName))).To(Equal(http.StatusOK))
+		serviceLogs := ocmServer.ServiceLogs()
+		Expect(serviceLogs).To(HaveLen(2))
+		Expect(serviceLogs[1].Description()).To(Equal("The alert of " + notificationName + " resolved."))
+
+		record := notificationRecord(managedNotification, notificationName)
+		Expect(conditionStatus(record, oav1alpha1.ConditionAlertFiring)).To(Equal(corev1.ConditionFalse))
+		Expect(conditionStatus(record, oav1alpha1.ConditionAlertResolved)).To(Equal(corev1.ConditionTrue))
+		Expect(record.ServiceLogSentCount).To(BeEquivalentTo(2))
+	})
+
+	It("keeps the records of the notifications of a ManagedNotification delivered concurrently", func() {
+		serve(k8sClient)
+		var notificationNames []string
+		for i := 0; i < concurrentDeliveries; i++ {
+			notificationNames = append(notificationNames, uniqueName("Concurrent"))
+		}
+		managedNotification := createManagedNotification(notificationNames...)
+
+		var wg sync.WaitGroup
+		for _, name := range notificationNames {
+			wg.Add(1)
+			go func(name string) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				Expect(deliver(receiver, alert(firing, name))).To(Equal(http.StatusOK))
+			}(name)
+		}
+		wg.Wait()
+
+		Expect(ocmServer.ServiceLogs()).To(HaveLen(concurrentDeliveries))
+		for _, name := range notificationNames {
+			record := notificationRecord(managedNotification, name)
+			Expect(conditionStatus(record, oav1alpha1.ConditionAlertFiring)).To(Equal(corev1.ConditionTrue), name)
+			Expect(conditionStatus(record, oav1alpha1.ConditionServiceLogSent)).To(Equal(corev1.ConditionTrue), name)
+		}
+	})
+
+	It("retries the status update rejected for a stale resourceVersion", func() {
+		c, conflicts := conflictingClient()
+		serve(c)
+		notificationName := uniqueName("Conflicting")
+		managedNotification := createManagedNotification(notificationName)
+
+		Expect(deliver(receiver, alert(firing, notificationName))).To(Equal(http.StatusOK))
+
+		Expect(conflicts.Load()).To(BeEquivalentTo(1))
+		Expect(ocmServer.ServiceLogs()).To(HaveLen(1))
+		record := notificationRecord(managedNotification, notificationName)
+		Expect(conditionStatus(record, oav1alpha1.ConditionAlertFiring)).To(Equal(corev1.ConditionTrue))
+		Expect(conditionStatus(record, oav1alpha1.ConditionServiceLogSent)).To(Equal(corev1.ConditionTrue))
+	})
+
+	It("records the service log rejected by OCM as not sent", func() {
+		serve(k8sClient)
+		notificationName := uniqueName("Rejected")
+		managedNotification := createManagedNotification(notificationName)
+		Expect(ocmServer.AddFault(fake.Fault{Operation: fake.OperationAddServiceLog, Status: http.StatusInternalServerError})).To(Succeed())
+
+		Expect(deliver(receiver, alert(firing, notificationName))).To(Equal(http.StatusOK))
+
+		Expect(ocmServer.ServiceLogs()).To(BeEmpty())
+		Expect(ocmServer.Calls(fake.OperationAddServiceLog)).NotTo(BeEmpty())
+		record := notificationRecord(managedNotification, notificationName)
+		Expect(conditionStatus(record, oav1alpha1.ConditionAlertFiring)).To(Equal(corev1.ConditionTrue))
+		Expect(conditionStatus(record, oav1alpha1.ConditionServiceLogSent)).To(Equal(corev1.ConditionFalse))
+	})
+})
+
+var _ = Describe("Fleet webhook receiver", func() {
+	var (
+		ctx      context.Context
+		receiver *httptest.Server
+	)
+
+	serve := func(c client.Client) {
+		receiver = httptest.NewServer(handlers.NewWebhookRHOBSReceiverHandler(c, ocmClient, nil).WithNamespaces([]string{namespace}))
+		DeferCleanup(receiver.Close)
+	}
+
+	createManagedFleetNotification := func(limitedSupport bool) *oav1alpha1.ManagedFleetNotification {
+		name := uniqueName("fleet-notification")
+		managedFleetNotification := &oav1alpha1.ManagedFleetNotification{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: oav1alpha1.ManagedFleetNotificationSpec{
+				FleetNotification: oav1alpha1.FleetNotification{
+					Name:                name,
+					Summary:             "Summary of " + name,
+					NotificationMessage: "The alert of " + name + " is firing.",
+					Severity:            "Info",
+					ResendWait:          24,
+					LimitedSupport:      limitedSupport,
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, managedFleetNotification)).To(Succeed())
+		return managedFleetNotification
+	}
+
+	alert := func(status string, managedFleetNotification *oav1alpha1.ManagedFleetNotification, managementClusterID, hostedClusterID string) []byte {
+		return alertPayload("template-alert-fleet-notification.json", map[string]string{
+			"ALERT_STATUS":                  status,
+			"ALERT_NAME":                    "Alert" + managedFleetNotification.Name,
+			"MANAGED_NOTIFICATION_TEMPLATE": managedFleetNotification.Name,
+			"MANAGEMENT_CLUSTER_ID":         managementClusterID,
+			"HC_ID":                         hostedClusterID,
+		})
+	}
+
+	notificationRecordItem := func(managedFleetNotification *oav1alpha1.ManagedFleetNotification, managementClusterID, hostedClusterID string) *oav1alpha1.NotificationRecordItem {
+		record := &oav1alpha1.ManagedFleetNotificationRecord{}
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: managementClusterID}, record)).To(Succeed())
+		Expect(record.Status.ManagementCluster).To(Equal(managementClusterID))
+		item, err := record.GetNotificationRecordItem(managementClusterID, managedFleetNotification.Name, hostedClusterID)
+		Expect(err).NotTo(HaveOccurred())
+		return item
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	It("sends a service log to the hosted cluster and creates the ManagedFleetNotificationRecord", func() {
+		serve(k8sClient)
+		managedFleetNotification := createManagedFleetNotification(false)
+		managementClusterID, hostedClusterID := uniqueName("mc"), uniqueName("hc")
+
+		Expect(deliver(receiver, alert(firing, managedFleetNotification, managementClusterID, hostedClusterID))).To(Equal(http.StatusOK))
+		Expect(deliver(receiver, alert(firing, managedFleetNotification, managementClusterID, hostedClusterID))).To(Equal(http.StatusOK))
+
+		serviceLogs := ocmServer.ServiceLogs()
+		Expect(serviceLogs).To(HaveLen(1))
+		Expect(serviceLogs[0].ClusterUUID()).To(Equal(hostedClusterID))
+		Expect(serviceLogs[0].Summary()).To(ContainSubstring("Summary of " + managedFleetNotification.Name))
+
+		item := notificationRecordItem(managedFleetNotification, managementClusterID, hostedClusterID)
+		Expect(item.FiringNotificationSentCount).To(Equal(1))
+		Expect(item.LastTransitionTime).NotTo(BeNil())
+	})
+
+	It("places the hosted cluster in limited support until the alert resolves", func() {
+		serve(k8sClient)
+		managedFleetNotification := createManagedFleetNotification(true)
+		managementClusterID, hostedClusterID, internalID := uniqueName("mc"), uniqueName("hc"), uniqueName("internal")
+		ocmServer.AddCluster(internalID, hostedClusterID)
+
+		Expect(deliver(receiver, alert(firing, managedFleetNotification, managementClusterID, hostedClusterID))).To(Equal(http.StatusOK))
+		reasons := ocmServer.LimitedSupportReasons(internalID)
+		Expect(reasons).To(HaveLen(1))
+		Expect(reasons[0].Details()).To(Equal(managedFleetNotification.Spec.FleetNotification.NotificationMessage))
+
+		Expect(deliver(receiver, alert(firing, managedFleetNotification, managementClusterID, hostedClusterID))).To(Equal(http.StatusOK))
+		Expect(ocmServer.Calls(fake.OperationAddLimitedSupportReason)).To(HaveLen(1))
+
+		Expect(deliver(receiver, alert(resolved, managedFleetNotification, managementClusterID, hostedClusterID))).To(Equal(http.StatusOK))
+		Expect(ocmServer.LimitedSupportReasons(internalID)).To(BeEmpty())
+		Expect(ocmServer.Calls(fake.OperationDeleteLimitedSupportReason)).To(HaveLen(1))
+
+		item := notificationRecordItem(managedFleetNotification, managementClusterID, hostedClusterID)
+		Expect(item.FiringNotificationSentCount).To(Equal(1))
+		Expect(item.ResolvedNotificationSentCount).To(Equal(1))
+	})
+
+	It("keeps the records of the hosted clusters of a management cluster delivered concurrently", func() {
+		serve(k8sClient)
+		managedFleetNotification := createManagedFleetNotification(false)
+		managementClusterID := uniqueName("mc")
+		var hostedClusterIDs []string
+		for i := 0; i < concurrentDeliveries; i++ {
+			hostedClusterIDs = append(hostedClusterIDs, uniqueName("hc"))
+		}
+
+		// The deliveries race on the creation of the ManagedFleetNotificationRecord, then on its status updates
+		var wg sync.WaitGroup
+		for _, hostedClusterID := range hostedClusterIDs {
+			wg.Add(1)
+			go func(hostedClusterID string) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				Expect(deliver(receiver, alert(firing, managedFleetNotification, managementClusterID, hostedClusterID))).To(Equal(http.StatusOK))
+			}(hostedClusterID)
+		}
+		wg.Wait()
+
+		var clusterUUIDs []string
+		for _, serviceLog := range ocmServer.ServiceLogs() {
+			clusterUUIDs = append(clusterUUIDs, serviceLog.ClusterUUID())
+		}
+		Expect(clusterUUIDs).To(ConsistOf(hostedClusterIDs))
+		for _, hostedClusterID := range hostedClusterIDs {
+			Expect(notificationRecordItem(managedFleetNotification, managementClusterID, hostedClusterID).FiringNotificationSentCount).To(Equal(1), hostedClusterID)
+		}
+	})
+
+	It("retries the status update rejected for a stale resourceVersion", func() {
+		c, conflicts := conflictingClient()
+		serve(c)
+		managedFleetNotification := createManagedFleetNotification(false)
+		managementClusterID, hostedClusterID := uniqueName("mc"), uniqueName("hc")
+
+		Expect(deliver(receiver, alert(firing, managedFleetNotification, managementClusterID, hostedClusterID))).To(Equal(http.StatusOK))
+
+		Expect(conflicts.Load()).To(BeEquivalentTo(1))
+		Expect(ocmServer.ServiceLogs()).To(HaveLen(1))
+		Expect(notificationRecordItem(managedFleetNotification, managementClusterID, hostedClusterID).FiringNotificationSentCount).To(Equal(1))
+	})
+
+	It("restores the record and backs off when OCM rate limits the service log", func() {
+		serve(k8sClient)
+		managedFleetNotification := createManagedFleetNotification(false)
+		managementClusterID, hostedClusterID := uniqueName("mc"), uniqueName("hc")
+		Expect(ocmServer.AddFault(fake.Fault{Operation: fake.OperationAddServiceLog, Status: http.StatusTooManyRequests})).To(Succeed())
+
+		Expect(deliver(receiver, alert(firing, managedFleetNotification, managementClusterID, hostedClusterID))).To(Equal(http.StatusOK))
+		Expect(ocmServer.ServiceLogs()).To(BeEmpty())
+		item := notificationRecordItem(managedFleetNotification, managementClusterID, hostedClusterID)
+		Expect(item.FiringNotificationSentCount).To(BeZero())
+		Expect(item.LastTransitionTime).To(BeNil())
+
+		// The alert fires again while the receiver backs off
+		ocmServer.ClearFaults()
+		calls := len(ocmServer.Calls(fake.OperationAddServiceLog))
+		Expect(deliver(receiver, alert(firing, managedFleetNotification, managementClusterID, hostedClusterID))).To(Equal(http.StatusOK))
+		Expect(ocmServer.Calls(fake.OperationAddServiceLog)).To(HaveLen(calls))
+		Expect(ocmServer.ServiceLogs()).To(BeEmpty())
+	})
+})